@@ -0,0 +1,295 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PreviewAllocation works out which pending requests to approve so that, per resource, the approved
+// set doesn't overlap and scores as high as possible under the objective. Nothing is changed: the
+// plan is applied with CommitAllocation.
+func (s *BookingService) PreviewAllocation(req *AllocationRequest) (*AllocationPlan, error) {
+	if (req.ResourceID == nil) == (req.ResourceTypeID == nil) {
+		return nil, fmt.Errorf("%w: specify exactly one of resource_id or resource_type_id", utils.ErrInvalidInput)
+	}
+	if !req.To.After(req.From) {
+		return nil, fmt.Errorf("%w: 'to' must be after 'from'", utils.ErrInvalidInput)
+	}
+	if req.Objective == "" {
+		req.Objective = MaximizeHours
+	}
+
+	pending, err := s.BookingRepo.GetPendingForAllocation(req.ResourceID, req.ResourceTypeID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &AllocationPlan{
+		Objective: req.Objective,
+		From:      req.From,
+		To:        req.To,
+		Approve:   []BookingSummary{},
+		Reject:    []BookingSummary{},
+		Skipped:   []SkippedAllocation{},
+	}
+	skip := func(b *Booking, reason string) {
+		plan.Skipped = append(plan.Skipped, SkippedAllocation{BookingID: b.ID, Reason: reason})
+	}
+
+	// Requests only compete with others for the same resource
+	var order []int
+	byResource := make(map[int][]Booking)
+	for _, b := range pending {
+		if _, seen := byResource[b.ResourceID]; !seen {
+			order = append(order, b.ResourceID)
+		}
+		byResource[b.ResourceID] = append(byResource[b.ResourceID], b)
+	}
+
+	var groups []allocationGroup
+	for _, resourceID := range order {
+		group := byResource[resourceID]
+		g := allocationGroup{res: &group[0].Resource}
+		for i := range group {
+			b := &group[i]
+			if poolSize(g.res) > 1 {
+				skip(b, "pooled resources are not auto-allocated")
+				continue
+			}
+			if b.BundleID != nil {
+				skip(b, fmt.Sprintf("part of bundle %d, approve or reject the bundle instead", *b.BundleID))
+				continue
+			}
+			busy, err := s.BookingRepo.HasApprovedOverlapExcluding(b.ResourceID, b.StartTime, b.EndTime, b.Units(), b.ID)
+			if err != nil {
+				return nil, err
+			}
+			if busy {
+				// Approving it would fail anyway
+				g.rejected = append(g.rejected, *b)
+				continue
+			}
+			g.candidates = append(g.candidates, *b)
+		}
+		groups = append(groups, g)
+	}
+
+	overQuota, err := s.scheduleWithinQuota(groups, req.Objective)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range groups {
+		candidates := withoutOverQuota(g.candidates, overQuota)
+		chosen := scheduleRequests(candidates, g.res.Buffers().Turnover(), req.Objective)
+		picked := make(map[int]bool, len(chosen))
+		for _, b := range chosen {
+			picked[b.ID] = true
+			plan.ApprovedHours += b.EndTime.Sub(b.StartTime).Hours()
+			plan.Score += allocationWeight(&b, req.Objective)
+		}
+		rejected := g.rejected
+		for _, b := range candidates {
+			if !picked[b.ID] {
+				rejected = append(rejected, b)
+			}
+		}
+		for i := range g.candidates {
+			if reason, over := overQuota[g.candidates[i].ID]; over {
+				skip(&g.candidates[i], reason)
+			}
+		}
+		plan.Approve = append(plan.Approve, s.mapToSummary(chosen)...)
+		plan.Reject = append(plan.Reject, s.mapToSummary(rejected)...)
+	}
+	return plan, nil
+}
+
+// allocationGroup holds one resource's requests in an allocation: the candidates compete for it,
+// the rejected ones already lost to an approved booking.
+type allocationGroup struct {
+	res        *resource.Resource
+	candidates []Booking
+	rejected   []Booking
+}
+
+// scheduleWithinQuota finds the requests that can't be approved without taking their owner over a
+// quota, counting everything the plan approves for the same user. An over-quota request is left
+// pending and the resources are scheduled again without it, until the plan fits. The result maps
+// the requests to leave out to the reason.
+func (s *BookingService) scheduleWithinQuota(groups []allocationGroup, objective AllocationObjective) (map[int]string, error) {
+	overQuota := make(map[int]string)
+	for {
+		// The requests the plan decides on: their stored usage is replaced by what the plan approves
+		var decided []int
+		for _, g := range groups {
+			for _, b := range withoutOverQuota(g.candidates, overQuota) {
+				decided = append(decided, b.ID)
+			}
+			for _, b := range g.rejected {
+				decided = append(decided, b.ID)
+			}
+		}
+
+		dropped, err := s.dropFirstOverQuota(groups, overQuota, decided, objective)
+		if err != nil {
+			return nil, err
+		}
+		if !dropped {
+			return overQuota, nil
+		}
+	}
+}
+
+// dropFirstOverQuota schedules every resource and adds the first chosen request that exceeds a
+// quota to overQuota. It reports whether one did.
+func (s *BookingService) dropFirstOverQuota(groups []allocationGroup, overQuota map[int]string, decided []int, objective AllocationObjective) (bool, error) {
+	planned := make(map[string][]quota.Planned)
+	for _, g := range groups {
+		chosen := scheduleRequests(withoutOverQuota(g.candidates, overQuota), g.res.Buffers().Turnover(), objective)
+		for _, b := range chosen {
+			reason, err := quotaProblem(s.checkPlannedQuota(b.UserID, g.res.TypeID, b.StartTime, b.EndTime, planned[b.UserID], decided))
+			if err != nil {
+				return false, err
+			}
+			if reason != "" {
+				overQuota[b.ID] = reason
+				return true, nil
+			}
+			planned[b.UserID] = append(planned[b.UserID], quota.Planned{ResourceTypeID: g.res.TypeID, StartTime: b.StartTime, EndTime: b.EndTime})
+		}
+	}
+	return false, nil
+}
+
+func withoutOverQuota(bookings []Booking, overQuota map[int]string) []Booking {
+	var kept []Booking
+	for _, b := range bookings {
+		if _, over := overQuota[b.ID]; !over {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// CommitAllocation applies a previewed plan in one transaction and notifies the owners. It fails
+// without changing anything if any of the requests has been decided since the preview.
+func (s *BookingService) CommitAllocation(req *AllocationCommit, approverID string) (*AllocationResult, error) {
+	if len(req.ApproveIDs) == 0 && len(req.RejectIDs) == 0 {
+		return nil, fmt.Errorf("%w: the plan is empty", utils.ErrInvalidInput)
+	}
+	seen := make(map[int]bool)
+	for _, id := range append(append([]int{}, req.ApproveIDs...), req.RejectIDs...) {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: booking %d appears more than once in the plan", utils.ErrInvalidInput, id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.checkAllocationQuota(req); err != nil {
+		return nil, err
+	}
+
+	approved, rejected, err := s.BookingRepo.CommitAllocation(req.ApproveIDs, req.RejectIDs, approverID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range approved {
+		s.afterTransition(&approved[i], StatusPending)
+	}
+	s.notifyConflictRejections(rejected)
+
+	return &AllocationResult{
+		Approved: s.mapToSummary(approved),
+		Rejected: s.mapToSummary(rejected),
+	}, nil
+}
+
+// checkAllocationQuota re-checks the plan's approvals against the owners' quotas, as the preview
+// did: the requests in the plan are counted only if approved. Quotas or bookings may have changed
+// since the preview.
+func (s *BookingService) checkAllocationQuota(req *AllocationCommit) error {
+	if s.Quotas == nil || len(req.ApproveIDs) == 0 {
+		return nil
+	}
+	approve := make([]Booking, 0, len(req.ApproveIDs))
+	for _, id := range req.ApproveIDs {
+		b, err := s.BookingRepo.GetBookingByID(id)
+		if err != nil {
+			return err
+		}
+		approve = append(approve, *b)
+	}
+	sort.SliceStable(approve, func(i, j int) bool { return approve[i].StartTime.Before(approve[j].StartTime) })
+
+	decided := append(append([]int{}, req.ApproveIDs...), req.RejectIDs...)
+	planned := make(map[string][]quota.Planned)
+	for _, b := range approve {
+		if err := s.checkPlannedQuota(b.UserID, b.Resource.TypeID, b.StartTime, b.EndTime, planned[b.UserID], decided); err != nil {
+			return fmt.Errorf("booking %d: %w", b.ID, err)
+		}
+		planned[b.UserID] = append(planned[b.UserID], quota.Planned{ResourceTypeID: b.Resource.TypeID, StartTime: b.StartTime, EndTime: b.EndTime})
+	}
+	return nil
+}
+
+func allocationWeight(b *Booking, objective AllocationObjective) float64 {
+	if objective == MaximizePriority {
+		return float64(b.Priority + 1)
+	}
+	return b.EndTime.Sub(b.StartTime).Hours()
+}
+
+// scheduleRequests solves weighted interval scheduling over one resource's requests: it returns the
+// non-overlapping subset with the highest total weight. Two requests fit together when the turnover
+// buffer fits between them.
+func scheduleRequests(requests []Booking, turnover time.Duration, objective AllocationObjective) []Booking {
+	sorted := append([]Booking(nil), requests...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].EndTime.Equal(sorted[j].EndTime) {
+			return sorted[i].EndTime.Before(sorted[j].EndTime)
+		}
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+
+	n := len(sorted)
+	// prev[j] is how many of the first j requests are free by the time request j starts
+	prev := make([]int, n)
+	for j := range sorted {
+		prev[j] = sort.Search(j, func(i int) bool {
+			return sorted[i].EndTime.Add(turnover).After(sorted[j].StartTime)
+		})
+	}
+
+	// best[j] is the highest weight reachable with the first j requests
+	best := make([]float64, n+1)
+	take := make([]bool, n+1)
+	for j := 1; j <= n; j++ {
+		with := allocationWeight(&sorted[j-1], objective) + best[prev[j-1]]
+		if with >= best[j-1] {
+			best[j], take[j] = with, true
+		} else {
+			best[j] = best[j-1]
+		}
+	}
+
+	var chosen []Booking
+	for j := n; j > 0; {
+		if take[j] {
+			chosen = append(chosen, sorted[j-1])
+			j = prev[j-1]
+		} else {
+			j--
+		}
+	}
+	// Back in start order
+	for i, k := 0, len(chosen)-1; i < k; i, k = i+1, k-1 {
+		chosen[i], chosen[k] = chosen[k], chosen[i]
+	}
+	return chosen
+}
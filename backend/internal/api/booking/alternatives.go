@@ -0,0 +1,130 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/resource"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// How many other resources a slot conflict suggests
+const maxAlternatives = 3
+
+// findAlternativeResources lists other active resources of the requested one's type that can be
+// booked for exactly [start, end) and seat the booker with their attendees: closest properties first,
+// then closest location. Like the suggested slots it is best effort, so lookup errors just mean fewer
+// suggestions.
+func (s *BookingService) findAlternativeResources(requested *resource.Resource, quantity, attendees int, start, end time.Time, role string) []AlternativeResource {
+	alternatives := []AlternativeResource{}
+	candidates, err := s.BookingRepo.GetActiveResourcesByType(requested.TypeID)
+	if err != nil {
+		return alternatives
+	}
+
+	now := time.Now()
+	var ranked []AlternativeResource
+	for i := range candidates {
+		c := &candidates[i]
+		if c.ID == requested.ID || poolSize(c) < quantity || validateHeadcount(c, attendees) != nil {
+			continue
+		}
+		// Its own schedule, slot rules and booking window must allow the same window: it mustn't be
+		// closed for the weekend or a holiday where it is, or outside its location's opening hours
+		schedule, err := s.scheduleFor(c, start, end)
+		if err != nil || validateWindowRules(start, end, schedule) != nil {
+			continue
+		}
+		if validateWindowShape(start, end, c.SlotRules(), schedule.Zone) != nil || c.BookingWindowFor(role).Check(start, now) != nil {
+			continue
+		}
+		ranked = append(ranked, AlternativeResource{
+			SuggestedSlot: SuggestedSlot{ResourceID: c.ID, StartTime: start, EndTime: end},
+			Name:          c.Name,
+			Location:      c.Location,
+			PropertyMatch: propertyMatch(requested.Properties, c.Properties),
+			LocationMatch: locationMatch(requested, c),
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].PropertyMatch != ranked[j].PropertyMatch {
+			return ranked[i].PropertyMatch > ranked[j].PropertyMatch
+		}
+		return ranked[i].LocationMatch > ranked[j].LocationMatch
+	})
+
+	// Only the best ranked need their availability checked
+	for _, alt := range ranked {
+		busy, err := s.BookingRepo.HasApprovedOverlap(alt.ResourceID, start, end, quantity)
+		if err != nil || busy {
+			continue
+		}
+		alternatives = append(alternatives, alt)
+		if len(alternatives) == maxAlternatives {
+			break
+		}
+	}
+	return alternatives
+}
+
+// propertyMatch scores how well have matches the properties in want: each property counts fully when
+// equal, numbers count by how close they are (a 10 seat room is a 0.8 match for 8 seats).
+func propertyMatch(want, have map[string]interface{}) float64 {
+	if len(want) == 0 {
+		return 1
+	}
+	var total float64
+	for key, wv := range want {
+		hv, ok := have[key]
+		if !ok {
+			continue
+		}
+		wn, wIsNum := wv.(float64)
+		hn, hIsNum := hv.(float64)
+		switch {
+		case wIsNum && hIsNum && wn != hn:
+			total += math.Min(math.Abs(wn), math.Abs(hn)) / math.Max(math.Abs(wn), math.Abs(hn))
+		case reflect.DeepEqual(wv, hv):
+			total++
+		}
+	}
+	return roundScore(total / float64(len(want)))
+}
+
+// locationMatch scores how close two locations are by the ancestors they share in the location
+// tree: the length of their common path prefix over the longer path. Rooms on the same floor come
+// first, then the same building, then the same campus, so "HQ / Building A / Floor 2" is closer to
+// "HQ / Building A / Floor 3" than to "HQ / Building B / Floor 2". Free-text locations split on
+// commas the same way.
+func locationMatch(a, b *resource.Resource) float64 {
+	if a.LocationID != nil && b.LocationID != nil && *a.LocationID == *b.LocationID {
+		return 1
+	}
+	pathA, pathB := locationPath(a.Location), locationPath(b.Location)
+	if len(pathA) == 0 || len(pathB) == 0 {
+		return 0
+	}
+	shared := 0
+	for shared < len(pathA) && shared < len(pathB) && pathA[shared] == pathB[shared] {
+		shared++
+	}
+	return roundScore(float64(shared) / math.Max(float64(len(pathA)), float64(len(pathB))))
+}
+
+// locationPath splits a location into its names from the outermost down.
+func locationPath(location string) []string {
+	var path []string
+	for _, name := range strings.FieldsFunc(strings.ToLower(location), func(r rune) bool {
+		return r == '/' || r == ','
+	}) {
+		if name = strings.TrimSpace(name); name != "" {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,135 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"strings"
+)
+
+// resolveAttendees turns the attendees of a request into records: internal users are looked up for
+// their name and email, guests need both. The booker and repeated people are dropped.
+func (s *BookingService) resolveAttendees(bookerID string, inputs []AttendeeInput) ([]BookingAttendee, error) {
+	var attendees []BookingAttendee
+	var userIDs []string
+	seenUsers := map[string]bool{bookerID: true}
+	seenEmails := make(map[string]bool)
+	for _, in := range inputs {
+		switch {
+		case in.UserID != "":
+			if !seenUsers[in.UserID] {
+				seenUsers[in.UserID] = true
+				userIDs = append(userIDs, in.UserID)
+			}
+		case in.Name != "" && in.Email != "":
+			if !seenEmails[in.Email] {
+				seenEmails[in.Email] = true
+				attendees = append(attendees, BookingAttendee{Name: in.Name, Email: in.Email})
+			}
+		default:
+			return nil, fmt.Errorf("%w: an attendee needs a user_id, or a name and an email", utils.ErrInvalidInput)
+		}
+	}
+	if len(userIDs) == 0 {
+		return attendees, nil
+	}
+
+	users, err := s.BookingRepo.GetUsersByUUIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != len(userIDs) {
+		found := make(map[string]bool, len(users))
+		for _, u := range users {
+			found[u.UUID] = true
+		}
+		var missing []string
+		for _, id := range userIDs {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		return nil, fmt.Errorf("%w: unknown attendee(s) %s", utils.ErrInvalidInput, strings.Join(missing, ", "))
+	}
+	internal := make([]BookingAttendee, 0, len(users))
+	for _, u := range users {
+		uuid := u.UUID
+		// A guest who turns out to be a user is only counted once
+		if seenEmails[strings.ToLower(u.Email)] {
+			attendees = dropGuest(attendees, strings.ToLower(u.Email))
+		}
+		internal = append(internal, BookingAttendee{UserID: &uuid, Name: u.Name, Email: u.Email})
+	}
+	return append(internal, attendees...), nil
+}
+
+func dropGuest(attendees []BookingAttendee, email string) []BookingAttendee {
+	kept := attendees[:0]
+	for _, a := range attendees {
+		if a.UserID != nil || a.Email != email {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// validateHeadcount checks that the booker and attendees fit in the resource, when its type declares
+// a capacity.
+func validateHeadcount(res *resource.Resource, attendees int) error {
+	capacity, ok := res.Capacity()
+	if !ok {
+		return nil
+	}
+	if headcount := attendees + 1; headcount > capacity {
+		return fmt.Errorf("%w: %d people (the booker and %d attendee(s)) exceed the capacity of %d of this resource",
+			utils.ErrInvalidInput, headcount, attendees, capacity)
+	}
+	return nil
+}
+
+// copyAttendees gives each occurrence of a series its own attendee records.
+func copyAttendees(attendees []BookingAttendee) []BookingAttendee {
+	if len(attendees) == 0 {
+		return nil
+	}
+	copied := make([]BookingAttendee, len(attendees))
+	copy(copied, attendees)
+	return copied
+}
+
+// attendeeEmails returns the addresses of everyone attending the bookings, once each.
+func attendeeEmails(bookings ...Booking) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, b := range bookings {
+		for _, a := range b.Attendees {
+			if a.Email != "" && !seen[a.Email] {
+				seen[a.Email] = true
+				emails = append(emails, a.Email)
+			}
+		}
+	}
+	return emails
+}
+
+// notifyAttendees emails the attendees of a booking (its owner gets their own email) what happened to it.
+func (s *BookingService) notifyAttendees(b *Booking, subject, headline string) {
+	emails := attendeeEmails(*b)
+	if len(emails) == 0 {
+		return
+	}
+	body := fmt.Sprintf("%s\n\nBooking ID: %d\nResource: %s\nBooked by: %s\nStart Time: %s\nEnd Time: %s\nPurpose: %s",
+		headline, b.ID, b.Resource.Name, b.User.Name, s.localTime(&b.Resource, b.StartTime), s.localTime(&b.Resource, b.EndTime), b.Purpose)
+	for _, email := range emails {
+		utils.SendEmail(body, email, subject)
+	}
+}
+
+// GetAttendingBookings lists the bookings the user attends (not the ones they booked).
+func (s *BookingService) GetAttendingBookings(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]BookingSummary, int64, error) {
+	bookings, total, err := s.BookingRepo.GetBookingsByAttendee(userID, filters, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.mapToSummary(bookings), total, nil
+}
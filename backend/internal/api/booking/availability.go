@@ -0,0 +1,228 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// How much of the calendar one availability request can cover
+const maxAvailabilityDays = 31
+
+// ResourceLister lists resources by the same filters as GET /resources.
+type ResourceLister interface {
+	GetAllResources(typeID *int, locationID *int, location string, props resource.PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]resource.ResourceSummary, int64, error)
+}
+
+// GetResourceAvailability returns the free/busy timeline of one resource.
+func (s *BookingService) GetResourceAvailability(resourceID int, q *AvailabilityQuery) (*ResourceAvailability, error) {
+	if err := validateAvailabilityQuery(q); err != nil {
+		return nil, err
+	}
+	res, err := s.BookingRepo.GetResourceByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := s.availability([]resource.Resource{*res}, q)
+	if err != nil {
+		return nil, err
+	}
+	return &grid[0], nil
+}
+
+// GetAvailabilityGrid returns the free/busy timeline of every resource (a page of them) matching the
+// filter, for a calendar view.
+func (s *BookingService) GetAvailabilityGrid(filter *resource.ResourceFilter, q *AvailabilityQuery, pagination utils.PaginationQuery) ([]ResourceAvailability, int64, error) {
+	if err := validateAvailabilityQuery(q); err != nil {
+		return nil, 0, err
+	}
+	if s.Resources == nil {
+		return nil, 0, fmt.Errorf("%w: resource listing is not configured", utils.ErrInternal)
+	}
+	summaries, total, err := s.Resources.GetAllResources(filter.TypeID, filter.LocationID, filter.Location, filter.Props, filter.StartTime, filter.EndTime, filter.Role, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(summaries) == 0 {
+		return []ResourceAvailability{}, total, nil
+	}
+
+	ids := make([]int, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.ID
+	}
+	resources, err := s.BookingRepo.GetResourcesByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Keep the listing's order
+	position := make(map[int]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	sort.SliceStable(resources, func(i, j int) bool { return position[resources[i].ID] < position[resources[j].ID] })
+
+	grid, err := s.availability(resources, q)
+	return grid, total, err
+}
+
+func validateAvailabilityQuery(q *AvailabilityQuery) error {
+	if !q.To.After(q.From) {
+		return fmt.Errorf("%w: 'to' must be after 'from'", utils.ErrInvalidInput)
+	}
+	if q.To.Sub(q.From) > maxAvailabilityDays*24*time.Hour {
+		return fmt.Errorf("%w: availability can cover at most %d days", utils.ErrInvalidInput, maxAvailabilityDays)
+	}
+	return nil
+}
+
+// availability builds the timelines of the resources from one query over their bookings.
+func (s *BookingService) availability(resources []resource.Resource, q *AvailabilityQuery) ([]ResourceAvailability, error) {
+	statuses := OccupyingStatuses
+	if q.IncludePending {
+		statuses = append([]BookingStatus{StatusPending}, OccupyingStatuses...)
+	}
+	// Bookings just outside the range still block it with their turnover
+	var ids []int
+	var widest time.Duration
+	for i := range resources {
+		ids = append(ids, resources[i].ID)
+		if t := resources[i].Buffers().Turnover(); t > widest {
+			widest = t
+		}
+	}
+	bookings, err := s.BookingRepo.GetBookingsInRange(ids, statuses, q.From.Add(-widest), q.To.Add(widest))
+	if err != nil {
+		return nil, err
+	}
+	byResource := make(map[int][]Booking)
+	for _, b := range bookings {
+		byResource[b.ResourceID] = append(byResource[b.ResourceID], b)
+	}
+
+	now := time.Now()
+	grid := make([]ResourceAvailability, 0, len(resources))
+	for i := range resources {
+		res := &resources[i]
+		schedule, err := s.scheduleFor(res, q.From, q.To)
+		if err != nil {
+			return nil, err
+		}
+		av := ResourceAvailability{
+			ResourceID:   res.ID,
+			ResourceName: res.Name,
+			Units:        poolSize(res),
+			SlotMinutes:  int(res.SlotRules().Granularity.Minutes()),
+			Busy:         []BusyInterval{},
+			Free:         freeIntervals(res, byResource[res.ID], schedule, q.From, q.To, now),
+		}
+		for _, b := range byResource[res.ID] {
+			if b.StartTime.Before(q.To) && b.EndTime.After(q.From) {
+				av.Busy = append(av.Busy, BusyInterval{
+					TimeInterval: TimeInterval{Start: b.StartTime, End: b.EndTime},
+					Status:       b.Status,
+					Quantity:     b.Units(),
+				})
+			}
+		}
+		grid = append(grid, av)
+	}
+	return grid, nil
+}
+
+// freeIntervals lists the stretches of [from, to) in which a booking of the resource could be made:
+// not in the past, within the opening hours of its location on days that aren't holidays, and clear of the
+// blocked periods of the bookings. The ends are cut to slot boundaries and anything shorter than the
+// shortest allowed booking is dropped.
+func freeIntervals(res *resource.Resource, bookings []Booking, schedule utils.Schedule, from, to, now time.Time) []TimeInterval {
+	rules := res.SlotRules()
+	shortest := rules.Granularity
+	if rules.MinDuration > shortest {
+		shortest = rules.MinDuration
+	}
+	if from.Before(now) {
+		from = now
+	}
+	blocked := blockedIntervals(res, bookings)
+
+	free := []TimeInterval{}
+	// One step per local day, running a day past to so its working hours are reached too
+	for day := schedule.Local(from); day.Before(to.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		open, close, ok := schedule.WorkingHoursOn(day)
+		if !ok {
+			continue
+		}
+		// Slots are counted from midnight in the working hours' time zone
+		loc := open.Location()
+		if open.Before(from) {
+			open = from.In(loc)
+		}
+		if close.After(to) {
+			close = to.In(loc)
+		}
+		if !open.Before(close) {
+			continue
+		}
+		// Walk the day's working hours, skipping over blocked periods
+		cursor := open
+		for _, b := range blocked {
+			if !b.End.After(cursor) || !b.Start.Before(close) {
+				continue
+			}
+			free = appendFree(free, cursor, b.Start.In(loc), rules.Granularity, shortest)
+			cursor = b.End.In(loc)
+		}
+		free = appendFree(free, cursor, close, rules.Granularity, shortest)
+	}
+	return free
+}
+
+// blockedIntervals returns, in order, the periods in which a new booking can't take place on the
+// resource: where, counting each booking's turnover on both sides, no unit is left.
+func blockedIntervals(res *resource.Resource, bookings []Booking) []TimeInterval {
+	turnover := res.Buffers().Turnover()
+	type change struct {
+		at    time.Time
+		units int
+	}
+	var changes []change
+	for _, b := range bookings {
+		changes = append(changes,
+			change{b.StartTime.Add(-turnover), b.Units()},
+			change{b.EndTime.Add(turnover), -b.Units()})
+	}
+	// Units given back at an instant are free for a booking starting then
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].at.Equal(changes[j].at) {
+			return changes[i].at.Before(changes[j].at)
+		}
+		return changes[i].units < changes[j].units
+	})
+
+	capacity := poolSize(res)
+	var blocked []TimeInterval
+	inUse := 0
+	for _, ch := range changes {
+		wasFull := inUse >= capacity
+		inUse += ch.units
+		switch {
+		case !wasFull && inUse >= capacity:
+			blocked = append(blocked, TimeInterval{Start: ch.at})
+		case wasFull && inUse < capacity:
+			blocked[len(blocked)-1].End = ch.at
+		}
+	}
+	return blocked
+}
+
+// appendFree adds [start, end) cut to slot boundaries, if a booking still fits in it.
+func appendFree(free []TimeInterval, start, end time.Time, granularity, shortest time.Duration) []TimeInterval {
+	start = alignToSlot(start, granularity)
+	end = alignDownToSlot(end, granularity)
+	if end.Sub(start) < shortest {
+		return free
+	}
+	return append(free, TimeInterval{Start: start, End: end})
+}
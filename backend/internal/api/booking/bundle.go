@@ -0,0 +1,301 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CreateBundle books several resources for the same window. Either every member booking is
+// created or none is.
+func (s *BookingService) CreateBundle(req *BundleCreate, userID string) (*BundleSummary, error) {
+	resourceIDs := uniqueInts(req.ResourceIDs)
+	if len(resourceIDs) < 2 {
+		return nil, fmt.Errorf("%w: a bundle needs at least two different resources", utils.ErrInvalidInput)
+	}
+	if req.StartTime.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: start time must be in the future", utils.ErrInvalidInput)
+	}
+	quantities := make(map[int]int, len(resourceIDs))
+	for _, id := range resourceIDs {
+		quantities[id] = 1
+	}
+	for id, q := range req.Quantities {
+		if _, ok := quantities[id]; !ok {
+			return nil, fmt.Errorf("%w: quantity given for resource %d, which is not in the bundle", utils.ErrInvalidInput, id)
+		}
+		if q > 1 {
+			quantities[id] = q
+		}
+	}
+
+	// The bundle only confirms instantly if none of its members needs an admin
+	path := PathInstant
+	resources := make([]*resource.Resource, len(resourceIDs))
+	for i, id := range resourceIDs {
+		res, err := s.BookingRepo.GetResourceByID(id)
+		if err != nil {
+			return nil, err
+		}
+		resources[i] = res
+		if !res.IsActive {
+			return nil, fmt.Errorf("%w: resource %d is not active", utils.ErrInvalidInput, id)
+		}
+		// Every member's slot rules and schedule must accept the shared window; members can be
+		// at locations with different time zones, opening hours and holiday calendars
+		schedule, err := s.scheduleFor(res, req.StartTime, req.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateWindowShape(req.StartTime, req.EndTime, res.SlotRules(), schedule.Zone); err != nil {
+			return nil, fmt.Errorf("resource %d: %w", id, err)
+		}
+		if err := validateWindowRules(req.StartTime, req.EndTime, schedule); err != nil {
+			return nil, fmt.Errorf("resource %d: %w", id, err)
+		}
+		if err := res.BookingWindowFor(req.Role).Check(req.StartTime, time.Now()); err != nil {
+			return nil, fmt.Errorf("resource %d: %w", id, err)
+		}
+		if err := validateQuantity(res, quantities[id]); err != nil {
+			return nil, fmt.Errorf("resource %d: %w", id, err)
+		}
+		if confirmationPathFor(res) == PathApprovalRequired {
+			path = PathApprovalRequired
+		}
+	}
+
+	// Report every busy member up front instead of failing on the first one
+	var busy []string
+	for _, id := range resourceIDs {
+		hasOverlap, err := s.BookingRepo.HasApprovedOverlap(id, req.StartTime, req.EndTime, quantities[id])
+		if err != nil {
+			return nil, err
+		}
+		if hasOverlap {
+			busy = append(busy, fmt.Sprintf("%d", id))
+		}
+	}
+	if len(busy) > 0 {
+		return nil, fmt.Errorf("%w: slot unavailable for resource(s) %s", utils.ErrConflict, strings.Join(busy, ", "))
+	}
+
+	// Every member counts towards the user's quota, along with the members before it
+	var planned []quota.Planned
+	for _, res := range resources {
+		if err := s.checkPlannedQuota(userID, res.TypeID, req.StartTime, req.EndTime, planned, nil); err != nil {
+			return nil, fmt.Errorf("resource %d: %w", res.ID, err)
+		}
+		planned = append(planned, quota.Planned{ResourceTypeID: res.TypeID, StartTime: req.StartTime, EndTime: req.EndTime})
+	}
+
+	bundle := &BookingBundle{
+		UserID:    userID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Purpose:   req.Purpose,
+		Status:    StatusPending,
+	}
+	bookings := make([]Booking, len(resourceIDs))
+	for i, id := range resourceIDs {
+		bookings[i] = Booking{
+			ResourceID: id,
+			UserID:     userID,
+			StartTime:  req.StartTime,
+			EndTime:    req.EndTime,
+			Purpose:    req.Purpose,
+			Status:     StatusPending,
+			Quantity:   quantities[id],
+		}
+	}
+	if path == PathInstant {
+		now := time.Now()
+		bundle.Status = StatusApproved
+		bundle.ApprovedAt = &now
+		for i := range bookings {
+			bookings[i].Status = StatusApproved
+			bookings[i].ApprovedAt = &now
+		}
+	}
+	rejected, err := s.BookingRepo.CreateBundle(bundle, bookings)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyConflictRejections(rejected)
+
+	summary, members, err := s.bundleSummary(bundle.ID)
+	if err != nil {
+		return nil, err
+	}
+	summary.ConfirmationPath = path
+	if len(members) > 0 {
+		body := fmt.Sprintf("Thank You for booking resources, Here is your bundle summary: \n\nBundle ID: %d\nResources: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s\n\n%s",
+			summary.ID, memberNames(members), members[0].User.Name, s.localTime(&members[0].Resource, summary.StartTime), s.localTime(&members[0].Resource, summary.EndTime), summary.Status, path.Describe())
+		utils.SendEmail(body, members[0].User.Email, "Bundle Booking Summary")
+	}
+	return summary, nil
+}
+
+func (s *BookingService) GetBundle(id int) (*BundleSummary, error) {
+	summary, _, err := s.bundleSummary(id)
+	return summary, err
+}
+
+// UpdateBundleStatus approves or rejects every member of a pending bundle as a unit.
+func (s *BookingService) UpdateBundleStatus(id int, req *BookingStatusUpdate, approverID string) error {
+	bundle, err := s.BookingRepo.GetBundleByID(id)
+	if err != nil {
+		return err
+	}
+	if req.Status != StatusApproved && req.Status != StatusRejected {
+		return fmt.Errorf("%w: status can only be set to approved or rejected", utils.ErrInvalidInput)
+	}
+	if err := CheckTransition(bundle.Status, req.Status, TriggerAdmin); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	bundle.ApprovedBy = &approverID
+	bundle.ApprovedAt = &now
+
+	switch req.Status {
+	case StatusApproved:
+		// The owner may have hit a limit since requesting; the members count together
+		if err := s.checkBundleQuota(bundle); err != nil {
+			return err
+		}
+		bundle.Status = StatusApproved
+		rejected, err := s.BookingRepo.ApproveBundleAndRejectConflicts(bundle)
+		if err != nil {
+			return err
+		}
+		members, err := s.BookingRepo.GetBookingsByBundleID(bundle.ID)
+		if err != nil {
+			return err
+		}
+		if len(members) > 0 {
+			body := fmt.Sprintf("Your bundle booking has been approved!\n\nBundle ID: %d\nResources: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s",
+				bundle.ID, memberNames(members), s.localTime(&members[0].Resource, bundle.StartTime), s.localTime(&members[0].Resource, bundle.EndTime), bundle.Status)
+			utils.SendEmail(body, members[0].User.Email, "Bundle Approved!")
+		}
+		s.notifyConflictRejections(rejected)
+		return nil
+
+	default:
+		bundle.Status = StatusRejected
+		bundle.RejectionReason = req.RejectionReason
+		if err := s.BookingRepo.UpdateBundleStatus(bundle, approverID, req.RejectionReason); err != nil {
+			return err
+		}
+		members, err := s.BookingRepo.GetBookingsByBundleID(bundle.ID)
+		if err != nil {
+			return err
+		}
+		if len(members) > 0 {
+			body := fmt.Sprintf("Your bundle booking has been rejected!\n\nBundle ID: %d\nResources: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s\n Reason: %s",
+				bundle.ID, memberNames(members), s.localTime(&members[0].Resource, bundle.StartTime), s.localTime(&members[0].Resource, bundle.EndTime), bundle.Status, bundle.RejectionReason)
+			utils.SendEmail(body, members[0].User.Email, "Bundle Rejected!")
+		}
+		for _, m := range members {
+			s.promoteWaitlist(m.ResourceID, m.StartTime, m.EndTime)
+		}
+		return nil
+	}
+}
+
+// checkBundleQuota checks the bundle's pending members against the owner's quota as if approved
+// together: each counts along with the members before it, in place of the stored requests.
+func (s *BookingService) checkBundleQuota(bundle *BookingBundle) error {
+	members, err := s.BookingRepo.GetBookingsByBundleID(bundle.ID)
+	if err != nil {
+		return err
+	}
+	ids := make([]int, len(members))
+	for i, m := range members {
+		ids[i] = m.ID
+	}
+	var planned []quota.Planned
+	for _, m := range members {
+		if err := s.checkPlannedQuota(bundle.UserID, m.Resource.TypeID, m.StartTime, m.EndTime, planned, ids); err != nil {
+			return fmt.Errorf("resource %d: %w", m.ResourceID, err)
+		}
+		planned = append(planned, quota.Planned{ResourceTypeID: m.Resource.TypeID, StartTime: m.StartTime, EndTime: m.EndTime})
+	}
+	return nil
+}
+
+// CancelBundle cancels every member of the caller's bundle.
+func (s *BookingService) CancelBundle(id int, userID string) error {
+	bundle, err := s.BookingRepo.GetBundleByID(id)
+	if err != nil {
+		return err
+	}
+	if bundle.UserID != userID {
+		return fmt.Errorf("%w: you can only cancel your own bookings", utils.ErrUnauthorized)
+	}
+	if err := CheckTransition(bundle.Status, StatusCancelled, TriggerOwner); err != nil {
+		return err
+	}
+	bundle.Status = StatusCancelled
+	if err := s.BookingRepo.UpdateBundleStatus(bundle, userID, "Bundle cancelled by user"); err != nil {
+		return err
+	}
+	members, err := s.BookingRepo.GetBookingsByBundleID(bundle.ID)
+	if err != nil {
+		return err
+	}
+	if len(members) > 0 {
+		body := fmt.Sprintf("Your bundle booking has been cancelled!\n\nBundle ID: %d\nResources: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s",
+			bundle.ID, memberNames(members), s.localTime(&members[0].Resource, bundle.StartTime), s.localTime(&members[0].Resource, bundle.EndTime), bundle.Status)
+		utils.SendEmail(body, members[0].User.Email, "Bundle Cancelled!")
+	}
+	for _, m := range members {
+		s.promoteWaitlist(m.ResourceID, m.StartTime, m.EndTime)
+	}
+	return nil
+}
+
+func (s *BookingService) bundleSummary(id int) (*BundleSummary, []Booking, error) {
+	bundle, err := s.BookingRepo.GetBundleByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := s.BookingRepo.GetBookingsByBundleID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &BundleSummary{
+		ID:              bundle.ID,
+		UserID:          bundle.UserID,
+		StartTime:       bundle.StartTime,
+		EndTime:         bundle.EndTime,
+		Purpose:         bundle.Purpose,
+		Status:          bundle.Status,
+		RejectionReason: bundle.RejectionReason,
+		Bookings:        s.mapToSummary(members),
+	}, members, nil
+}
+
+func memberNames(members []Booking) string {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.Resource.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	var out []int
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,63 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SelfCheckIn lets the owner of a booking check in with their own token.
+func (s *BookingService) SelfCheckIn(bookingID int, userID string) error {
+	b, err := s.BookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		return err
+	}
+	if b.UserID != userID {
+		return fmt.Errorf("%w: you can only check in your own bookings", utils.ErrUnauthorized)
+	}
+	return s.checkIn(b, userID, TriggerOwner)
+}
+
+// CheckInWithCode checks in the booking currently starting on the resource the code was issued for.
+// Holding a valid code proves presence at the resource, so no user token is needed.
+func (s *BookingService) CheckInWithCode(code string) (*BookingSummary, error) {
+	now := time.Now()
+	resourceID, err := utils.VerifyCheckInCode(code, now)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.BookingRepo.GetCheckInCandidate(resourceID, now, CheckInWindow)
+	if err != nil {
+		return nil, err
+	}
+	// Holding the code stands for the owner being there
+	if err := s.checkIn(b, ActorCheckInCode, TriggerOwner); err != nil {
+		return nil, err
+	}
+	b.Status = StatusUtilized
+	b.CheckedInAt = &now
+	summary := s.mapToSummary([]Booking{*b})[0]
+	return &summary, nil
+}
+
+// GetCheckInCode issues a fresh code for display at the resource. When CheckInURL is configured
+// the QR payload is a link to it, otherwise the bare code.
+func (s *BookingService) GetCheckInCode(resourceID int) (*CheckInCode, error) {
+	res, err := s.BookingRepo.GetResourceByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if !res.IsActive {
+		return nil, fmt.Errorf("%w: resource is not active", utils.ErrInvalidInput)
+	}
+	code, expiresAt, err := utils.GenerateCheckInCode(resourceID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	payload := code
+	if s.CheckInURL != "" {
+		payload = s.CheckInURL + "?code=" + url.QueryEscape(code)
+	}
+	return &CheckInCode{ResourceID: resourceID, Code: code, QRPayload: payload, ExpiresAt: expiresAt}, nil
+}
@@ -0,0 +1,102 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"time"
+)
+
+// ExtendBooking pushes the end of the caller's booking that is currently in use (checked in) to
+// req.EndTime, provided the added window is free and within working hours, and the longer booking
+// stays within the user's quotas.
+func (s *BookingService) ExtendBooking(id int, req *BookingExtend, userID string) (*BookingSummary, error) {
+	b, err := s.inUseBooking(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !req.EndTime.After(b.EndTime) {
+		return nil, fmt.Errorf("%w: new end time must be after the current end time", utils.ErrInvalidInput)
+	}
+	schedule, err := s.scheduleFor(&b.Resource, b.EndTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWindowShape(b.StartTime, req.EndTime, b.Resource.SlotRules(), schedule.Zone); err != nil {
+		return nil, err
+	}
+	if err := schedule.IsWorkingHours(b.EndTime, req.EndTime); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
+	}
+
+	// The booking itself is left out: with turnover buffers it would collide with its own extension
+	hasOverlap, err := s.BookingRepo.HasApprovedOverlapExcluding(b.ResourceID, b.EndTime, req.EndTime, b.Units(), b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hasOverlap {
+		return nil, fmt.Errorf("%w: the following slot is already booked", utils.ErrConflict)
+	}
+	// The extended booking counts towards the quota in place of the stored one
+	if err := s.checkQuota(b.UserID, b.Resource.TypeID, b.StartTime, req.EndTime, b.ID); err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.BookingRepo.ExtendBooking(b, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyConflictRejections(rejected)
+
+	oldEnd := b.EndTime
+	b.EndTime = req.EndTime
+	subject := "Booking Extended!"
+	body := fmt.Sprintf("Your booking has been extended!\n\nBooking ID: %d\nResource: %s\nStart Time: %s\nEnd Time: %s -> %s", b.ID, b.Resource.Name, s.localTime(&b.Resource, b.StartTime), s.localTime(&b.Resource, oldEnd), s.localTime(&b.Resource, b.EndTime))
+	utils.SendEmail(body, b.User.Email, subject)
+
+	summary := s.mapToSummary([]Booking{*b})[0]
+	return &summary, nil
+}
+
+// CheckOutBooking ends the caller's booking that is in use now. The actual end time is recorded and
+// the booking is shortened to it, so the rest of the slot becomes bookable again.
+func (s *BookingService) CheckOutBooking(id int, userID string) (*BookingSummary, error) {
+	b, err := s.inUseBooking(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if now.Before(b.StartTime) {
+		return nil, fmt.Errorf("%w: booking has not started yet", utils.ErrInvalidInput)
+	}
+
+	if err := s.BookingRepo.CheckOutBooking(b.ID, now); err != nil {
+		return nil, err
+	}
+	s.promoteWaitlist(b.ResourceID, now, b.EndTime)
+
+	b.EndTime = now
+	b.CheckedOutAt = &now
+	summary := s.mapToSummary([]Booking{*b})[0]
+	return &summary, nil
+}
+
+// inUseBooking loads a checked in booking of the caller that hasn't ended or been checked out yet.
+func (s *BookingService) inUseBooking(id int, userID string) (*Booking, error) {
+	b, err := s.BookingRepo.GetBookingByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != userID {
+		return nil, fmt.Errorf("%w: you can only change your own bookings", utils.ErrUnauthorized)
+	}
+	if b.Status != StatusUtilized {
+		return nil, fmt.Errorf("%w: only checked in bookings can be extended or checked out", utils.ErrInvalidInput)
+	}
+	if b.CheckedOutAt != nil {
+		return nil, fmt.Errorf("%w: booking has already been checked out", utils.ErrInvalidInput)
+	}
+	if !b.EndTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: booking has already ended", utils.ErrInvalidInput)
+	}
+	return b, nil
+}
@@ -3,6 +3,8 @@ package booking
 import (
 	"ResourceAllocator/internal/api/resource"
 	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -19,6 +21,10 @@ const (
 	StatusUtilized  BookingStatus = "utilized"
 )
 
+// OccupyingStatuses are the statuses that hold a resource's slot: approved bookings and
+// bookings that have been checked in and are in use.
+var OccupyingStatuses = []BookingStatus{StatusApproved, StatusUtilized}
+
 type Booking struct {
 	ID           int               `json:"id" gorm:"primaryKey;autoIncrement"`
 	ResourceID   int               `json:"resource_id" binding:"required"`
@@ -31,16 +37,60 @@ type Booking struct {
 	EndTime      time.Time         `json:"end_time" binding:"required"`
 	Purpose      string            `json:"purpose"`
 	Status       BookingStatus     `json:"status" gorm:"default:'pending'"`
+	Quantity     int               `json:"quantity" gorm:"default:1"` // Units taken from a pooled resource
+	Priority     int               `json:"priority" gorm:"default:0"` // Weight of the request in batch allocation
 
 	// Approval / Rejection info
-	ApprovedBy      *string    `json:"approved_by"` // UUID of admin
+	ApprovedBy      *string    `json:"approved_by"` // UUID of admin, or the system actor that approved it
 	ApprovedAt      *time.Time `json:"approved_at"`
 	RejectionReason string     `json:"rejection_reason"`
 
 	// Check-in info
 	CheckedInAt *time.Time `json:"checked_in_at"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Actual end of a utilized booking that was checked out early
+	CheckedOutAt *time.Time `json:"checked_out_at"`
+
+	// Recurring series this booking is an occurrence of (nil for one-off bookings)
+	SeriesID *int `json:"series_id,omitempty" gorm:"index"`
+	// Bundle this booking was created in together with other resources (nil for standalone bookings)
+	BundleID *int `json:"bundle_id,omitempty" gorm:"index"`
+	// People attending besides the booker
+	Attendees []BookingAttendee `json:"attendees,omitempty" gorm:"foreignKey:BookingID"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BookingAttendee is someone attending a booking besides the booker: an internal user, whose name and
+// email are copied from their account, or an external guest known only by name and email.
+type BookingAttendee struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookingID int       `json:"-" gorm:"index"`
+	UserID    *string   `json:"user_id,omitempty" gorm:"index"` // UUID, nil for external guests
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// AttendeeInput names an attendee of a new booking: either an internal user by UUID, or an external
+// guest by name and email.
+type AttendeeInput struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email" binding:"omitempty,email"`
+}
+
+// BookingSeries is the parent record of a recurring booking. Each occurrence is a child Booking.
+type BookingSeries struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ResourceID int       `json:"resource_id"`
+	UserID     string    `json:"user_id"`
+	Recurrence string    `json:"recurrence"` // RRULE as submitted
+	StartTime  time.Time `json:"start_time"` // First occurrence
+	EndTime    time.Time `json:"end_time"`
+	Purpose    string    `json:"purpose"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 type BookingCreate struct {
@@ -49,25 +99,429 @@ type BookingCreate struct {
 	StartTime  time.Time `json:"start_time" binding:"required"`
 	EndTime    time.Time `json:"end_time" binding:"required"`
 	Purpose    string    `json:"purpose" binding:"required"`
+	// Units of a pooled resource (defaults to 1)
+	Quantity int `json:"quantity" binding:"omitempty,min=1"`
+	// How important the request is when competing requests are allocated in a batch (0 - 3)
+	Priority int `json:"priority" binding:"omitempty,min=0,max=3"`
+	// Optional RRULE (e.g. "FREQ=WEEKLY;BYDAY=MO;COUNT=10"). StartTime/EndTime describe the first occurrence.
+	Recurrence string `json:"recurrence"`
+	// People attending besides the booker; together with the booker they must fit the resource's capacity
+	Attendees []AttendeeInput `json:"attendees" binding:"omitempty,dive"`
+	// Role of the caller, like the user ID it comes from the token (picks the booking window that applies)
+	Role string `json:"-"`
+}
+
+// Which occurrences of a series an edit or cancellation applies to
+type SeriesScope string
+
+const (
+	ScopeThis      SeriesScope = "this"
+	ScopeFollowing SeriesScope = "following"
+	ScopeAll       SeriesScope = "all"
+)
+
+type SeriesCancelRequest struct {
+	Scope SeriesScope `json:"scope" binding:"required,oneof=this following all"`
+}
+
+type SeriesOccurrenceUpdate struct {
+	Scope     SeriesScope `json:"scope" binding:"required,oneof=this following all"`
+	StartTime *time.Time  `json:"start_time"` // New start of the selected occurrence; others shift by the same offset
+	EndTime   *time.Time  `json:"end_time"`
+	Purpose   *string     `json:"purpose"`
+	// Role of the caller, whose booking window applies to the moved occurrences
+	Role string `json:"-"`
+}
+
+type SkippedOccurrence struct {
+	BookingID int       `json:"booking_id,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+}
+
+type SeriesResult struct {
+	Series           BookingSeries       `json:"series"`
+	ConfirmationPath ConfirmationPath    `json:"confirmation_path,omitempty"`
+	Created          []BookingSummary    `json:"created"`
+	Skipped          []SkippedOccurrence `json:"skipped"`
+}
+
+// BookingBundle groups bookings of several resources for the same window.
+// Members are created, approved and rejected together.
+type BookingBundle struct {
+	ID              int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          string        `json:"user_id"`
+	StartTime       time.Time     `json:"start_time"`
+	EndTime         time.Time     `json:"end_time"`
+	Purpose         string        `json:"purpose"`
+	Status          BookingStatus `json:"status" gorm:"default:'pending'"`
+	ApprovedBy      *string       `json:"approved_by"`
+	ApprovedAt      *time.Time    `json:"approved_at"`
+	RejectionReason string        `json:"rejection_reason"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type BundleCreate struct {
+	ResourceIDs []int     `json:"resource_ids" binding:"required,min=2,dive,gt=0"`
+	StartTime   time.Time `json:"start_time" binding:"required"`
+	EndTime     time.Time `json:"end_time" binding:"required"`
+	Purpose     string    `json:"purpose" binding:"required"`
+	// Units of pooled members by resource id, e.g. {"12": 3}; members left out take 1
+	Quantities map[int]int `json:"quantities" binding:"omitempty,dive,min=1"`
+	// Role of the caller, whose booking window applies to every member
+	Role string `json:"-"`
+}
+
+type BundleSummary struct {
+	ID               int              `json:"id"`
+	ConfirmationPath ConfirmationPath `json:"confirmation_path,omitempty"` // Only set on creation
+	UserID           string           `json:"user_id"`
+	StartTime        time.Time        `json:"start_time"`
+	EndTime          time.Time        `json:"end_time"`
+	Purpose          string           `json:"purpose"`
+	Status           BookingStatus    `json:"status"`
+	RejectionReason  string           `json:"rejection_reason,omitempty"`
+	Bookings         []BookingSummary `json:"bookings"`
+}
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistPromoted  WaitlistStatus = "promoted"
+	WaitlistExpired   WaitlistStatus = "expired"
+	WaitlistCancelled WaitlistStatus = "cancelled"
+)
+
+// WaitlistEntry is a request for an occupied slot. When the slot frees up the oldest
+// eligible entry is turned into a booking.
+type WaitlistEntry struct {
+	ID           int               `json:"id" gorm:"primaryKey;autoIncrement"`
+	ResourceID   int               `json:"resource_id" gorm:"index"`
+	Resource     resource.Resource `json:"-" gorm:"foreignKey:ResourceID"`
+	ResourceName string            `json:"resource_name,omitempty" gorm:"-"`
+	UserID       string            `json:"user_id" gorm:"index"`
+	User         user.User         `json:"-" gorm:"foreignKey:UserID;references:UUID"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	Purpose      string            `json:"purpose"`
+	Quantity     int               `json:"quantity" gorm:"default:1"` // Units wanted from a pooled resource
+	Status       WaitlistStatus    `json:"status" gorm:"default:'waiting'"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	BookingID    *int              `json:"booking_id"` // Set once promoted
+	CreatedAt    time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type WaitlistCreate struct {
+	ResourceID int        `json:"resource_id" binding:"required"`
+	StartTime  time.Time  `json:"start_time" binding:"required"`
+	EndTime    time.Time  `json:"end_time" binding:"required"`
+	Purpose    string     `json:"purpose" binding:"required"`
+	Quantity   int        `json:"quantity" binding:"omitempty,min=1"` // Units of a pooled resource (defaults to 1)
+	ExpiresAt  *time.Time `json:"expires_at"`                         // Optional, defaults to the end time
+	// Role of the caller, whose booking window the slot must be in, as it may be promoted to a booking
+	Role string `json:"-"`
+}
+
+// WaitlistPolicy decides what a promoted waitlist entry becomes.
+type WaitlistPolicy string
+
+const (
+	PromoteToPending  WaitlistPolicy = "pending"
+	PromoteToApproved WaitlistPolicy = "approved"
+)
+
+// CheckInCode is the short-lived signed code displayed at a resource (as text or a QR code)
+// that lets whoever is in the room check in the booking that is starting there.
+type CheckInCode struct {
+	ResourceID int       `json:"resource_id"`
+	Code       string    `json:"code"`
+	QRPayload  string    `json:"qr_payload"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type CheckInCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// BookingExtend moves the end of a booking that is in use to a later time.
+type BookingExtend struct {
+	EndTime time.Time `json:"end_time" binding:"required"`
+}
+
+// ReschedulePolicy decides whether an approved booking keeps its approval when it is rescheduled.
+type ReschedulePolicy string
+
+const (
+	// Approval is kept when the booking stays on the same resource and the new window lies within the old one
+	KeepApprovalWhenShrinking ReschedulePolicy = "shrink"
+	// Any change of resource or window sends the booking back for approval
+	ReapproveOnChange ReschedulePolicy = "always"
+)
+
+// BookingReschedule changes an existing booking in place. Omitted fields keep their current value.
+type BookingReschedule struct {
+	ResourceID *int       `json:"resource_id" binding:"omitempty,gt=0"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+	Purpose    *string    `json:"purpose"`
+	// Role of the caller, whose booking window applies to a new start
+	Role string `json:"-"`
+}
+
+// BookingChange records one reschedule of a booking, before and after.
+type BookingChange struct {
+	ID            int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookingID     int           `json:"booking_id" gorm:"index"`
+	ChangedBy     string        `json:"changed_by"` // UUID
+	OldResourceID int           `json:"old_resource_id"`
+	NewResourceID int           `json:"new_resource_id"`
+	OldStartTime  time.Time     `json:"old_start_time"`
+	NewStartTime  time.Time     `json:"new_start_time"`
+	OldEndTime    time.Time     `json:"old_end_time"`
+	NewEndTime    time.Time     `json:"new_end_time"`
+	OldPurpose    string        `json:"old_purpose"`
+	NewPurpose    string        `json:"new_purpose"`
+	OldStatus     BookingStatus `json:"old_status"`
+	NewStatus     BookingStatus `json:"new_status"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BookingEvent records one status change of a booking: from and to what, who made it (a user, or the
+// background job that did) and why.
+type BookingEvent struct {
+	ID         int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookingID  int           `json:"booking_id" gorm:"index"`
+	FromStatus BookingStatus `json:"from_status"`
+	ToStatus   BookingStatus `json:"to_status"`
+	Actor      string        `json:"actor"` // User UUID, or one of the system actors below
+	Reason     string        `json:"reason,omitempty"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
 }
 
+// Actors of status changes no user made directly
+const (
+	ActorAutoReleaseJob = "auto-release-job" // Released an approved booking nobody checked in
+	ActorAutoCancelJob  = "auto-cancel-job"  // Cancelled a pending booking whose start passed unreviewed
+	ActorSystem         = "system"           // Rejected a pending request whose slot went to another booking
+	ActorCheckInCode    = "check-in-code"    // Checked in with the code displayed at the resource
+	ActorWaitlist       = "waitlist"         // Approved a waitlist entry promoted into a freed slot
+)
+
 type BookingStatusUpdate struct {
-	Status          BookingStatus `json:"status" binding:"required"`
+	Status          BookingStatus `json:"status" binding:"required,oneof=approved rejected"`
 	RejectionReason string        `json:"rejection_reason"` // Optional, only for rejection
 }
 
+// ConfirmationPath tells the client how a new booking was confirmed
+type ConfirmationPath string
+
+const (
+	PathInstant          ConfirmationPath = "instant"           // Resource doesn't require approval, booking is approved already
+	PathApprovalRequired ConfirmationPath = "approval_required" // Booking waits for an admin
+)
+
+func (p ConfirmationPath) Describe() string {
+	if p == PathInstant {
+		return "Your booking is confirmed, no approval is needed for this resource."
+	}
+	return "This resource requires approval. You will receive another email once an admin reviews your booking."
+}
+
 type BookingSummary struct {
-	ID           int           `json:"id"`
-	ResourceName string        `json:"resource_name"`
-	UserName     string        `json:"user_name"`
-	StartTime    time.Time     `json:"start_time"`
-	EndTime      time.Time     `json:"end_time"`
-	Purpose      string        `json:"purpose"`
-	Status       BookingStatus `json:"status"`
+	ID               int               `json:"id"`
+	ResourceName     string            `json:"resource_name"`
+	UserName         string            `json:"user_name"`
+	StartTime        time.Time         `json:"start_time"`
+	EndTime          time.Time         `json:"end_time"`
+	Purpose          string            `json:"purpose"`
+	Status           BookingStatus     `json:"status"`
+	Quantity         int               `json:"quantity"`
+	Priority         int               `json:"priority"`
+	SeriesID         *int              `json:"series_id,omitempty"`
+	BundleID         *int              `json:"bundle_id,omitempty"`
+	Attendees        []BookingAttendee `json:"attendees,omitempty"`
+	ConfirmationPath ConfirmationPath  `json:"confirmation_path,omitempty"` // Only set on creation
+}
+
+// SlotConflictError is the ErrConflict returned when the requested slot is taken. It carries what
+// is in the way and what could be booked instead, which clients get as the response's details.
+type SlotConflictError struct {
+	ConflictingBookingIDs []int                 `json:"conflicting_booking_ids"`
+	SuggestedSlots        []SuggestedSlot       `json:"suggested_slots"`       // Later slots on the same resource
+	AlternativeResources  []AlternativeResource `json:"alternative_resources"` // Other resources free for the same window (new bookings only)
+}
+
+type SuggestedSlot struct {
+	ResourceID int       `json:"resource_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+}
+
+// AlternativeResource is a resource free for the requested window, best matches first.
+type AlternativeResource struct {
+	SuggestedSlot
+	Name          string  `json:"name"`
+	Location      string  `json:"location"`
+	PropertyMatch float64 `json:"property_match"` // How closely its properties match the requested resource's (0 - 1)
+	LocationMatch float64 `json:"location_match"` // How close its location is to the requested resource's (0 - 1)
+}
+
+func (e *SlotConflictError) Error() string {
+	return fmt.Sprintf("%v: slot unavailable", utils.ErrConflict)
+}
+
+func (e *SlotConflictError) Unwrap() error {
+	return utils.ErrConflict
+}
+
+func (e *SlotConflictError) ErrorDetails() interface{} {
+	return e
+}
+
+// AvailabilityQuery is the stretch of time a free/busy timeline covers (RFC3339 query parameters).
+type AvailabilityQuery struct {
+	From time.Time `form:"from" binding:"required"`
+	To   time.Time `form:"to" binding:"required"`
+	// Count pending requests as busy too (by default only approved and in-use bookings are)
+	IncludePending bool `form:"include_pending"`
+}
+
+type TimeInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type BusyInterval struct {
+	TimeInterval
+	Status   BookingStatus `json:"status"`
+	Quantity int           `json:"quantity"` // Units taken from a pooled resource
+}
+
+// ResourceAvailability is a resource's free/busy timeline. Free intervals are the stretches in which
+// a booking could be made: working hours on working days, clear of bookings and their turnover
+// buffers (on a pool, with a unit left), cut to slot boundaries.
+type ResourceAvailability struct {
+	ResourceID   int            `json:"resource_id"`
+	ResourceName string         `json:"resource_name"`
+	Units        int            `json:"units"`
+	SlotMinutes  int            `json:"slot_minutes"`
+	Busy         []BusyInterval `json:"busy"`
+	Free         []TimeInterval `json:"free"`
+}
+
+// FindTimeRequest asks for windows in which one of the resources is free and none of the attendees
+// is booked elsewhere. Resources are given by ID, or by type plus property filters.
+type FindTimeRequest struct {
+	ResourceIDs     []int             `json:"resource_ids" binding:"dive,gt=0"`
+	ResourceTypeID  *int              `json:"resource_type_id"`
+	Properties      map[string]string `json:"properties"` // Matched like the prop_ filters of GET /resources, e.g. {"capacity[gte]": "10"}
+	Attendees       []string          `json:"attendees"`  // User UUIDs; the caller always attends
+	DurationMinutes int               `json:"duration_minutes" binding:"required,min=1"`
+	From            time.Time         `json:"from" binding:"required"`
+	To              time.Time         `json:"to" binding:"required"`
+	Limit           int               `json:"limit" binding:"omitempty,min=1,max=50"` // Defaults to 10
+	// Role of the caller, whose booking window applies
+	Role string `json:"-"`
+}
+
+// TimeCandidate is a window that meets every constraint, with the resources that are free for it.
+type TimeCandidate struct {
+	StartTime time.Time           `json:"start_time"`
+	EndTime   time.Time           `json:"end_time"`
+	Resources []CandidateResource `json:"resources"`
+}
+
+type CandidateResource struct {
+	ResourceID int    `json:"resource_id"`
+	Name       string `json:"name"`
+}
+
+// What batch allocation maximizes
+type AllocationObjective string
+
+const (
+	MaximizeHours    AllocationObjective = "hours"    // Total approved hours
+	MaximizePriority AllocationObjective = "priority" // Sum of (priority + 1) over approved requests
+)
+
+// AllocationRequest selects the pending requests to allocate: those of one resource or of every
+// resource of a type, overlapping [From, To).
+type AllocationRequest struct {
+	ResourceID     *int                `json:"resource_id"`
+	ResourceTypeID *int                `json:"resource_type_id"`
+	From           time.Time           `json:"from" binding:"required"`
+	To             time.Time           `json:"to" binding:"required"`
+	Objective      AllocationObjective `json:"objective" binding:"omitempty,oneof=hours priority"`
+}
+
+// AllocationPlan is the outcome of a batch allocation: which pending requests to approve and which
+// to reject. Skipped requests are left pending.
+type AllocationPlan struct {
+	Objective     AllocationObjective `json:"objective"`
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Approve       []BookingSummary    `json:"approve"`
+	Reject        []BookingSummary    `json:"reject"`
+	Skipped       []SkippedAllocation `json:"skipped"`
+	ApprovedHours float64             `json:"approved_hours"`
+	Score         float64             `json:"score"`
+}
+
+type SkippedAllocation struct {
+	BookingID int    `json:"booking_id"`
+	Reason    string `json:"reason"`
+}
+
+// AllocationCommit applies a previewed plan.
+type AllocationCommit struct {
+	ApproveIDs []int `json:"approve_ids" binding:"dive,gt=0"`
+	RejectIDs  []int `json:"reject_ids" binding:"dive,gt=0"`
+}
+
+// AllocationResult is what a committed plan changed. Rejected includes requests that lost out to an
+// approved one, as a single approval would reject them.
+type AllocationResult struct {
+	Approved []BookingSummary `json:"approved"`
+	Rejected []BookingSummary `json:"rejected"`
 }
 
 func (b *BookingCreate) Sanitize() {
 	b.Purpose = strings.TrimSpace(b.Purpose)
+	b.Recurrence = strings.TrimSpace(b.Recurrence)
+	if b.Quantity < 1 {
+		b.Quantity = 1
+	}
+	for i := range b.Attendees {
+		b.Attendees[i].UserID = strings.TrimSpace(b.Attendees[i].UserID)
+		b.Attendees[i].Name = strings.TrimSpace(b.Attendees[i].Name)
+		b.Attendees[i].Email = strings.ToLower(strings.TrimSpace(b.Attendees[i].Email))
+	}
+}
+
+func (w *WaitlistCreate) Sanitize() {
+	w.Purpose = strings.TrimSpace(w.Purpose)
+}
+
+func (b *BundleCreate) Sanitize() {
+	b.Purpose = strings.TrimSpace(b.Purpose)
+}
+
+func (r *BookingReschedule) Sanitize() {
+	if r.Purpose != nil {
+		trimmed := strings.TrimSpace(*r.Purpose)
+		r.Purpose = &trimmed
+	}
+}
+
+func (u *SeriesOccurrenceUpdate) Sanitize() {
+	if u.Purpose != nil {
+		trimmed := strings.TrimSpace(*u.Purpose)
+		u.Purpose = &trimmed
+	}
 }
 
 type DashboardResourceStat struct {
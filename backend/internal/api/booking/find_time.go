@@ -0,0 +1,181 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// How many windows a search returns unless asked for a different number
+const defaultFindTimeLimit = 10
+
+// Most resources a search by type can cover; broader searches must be narrowed down
+const maxFindTimeResources = 100
+
+// FindTime searches [From, To) for windows of the requested duration in which at least one of the
+// resources is free (working hours, holidays, turnover and slot rules included) and none of the
+// attendees has an approved booking, as booker or attendee. Resources too small for the caller and
+// the attendees are left out. Windows come back earliest first, each with the resources free
+// for it; ties go to the window with more rooms to choose from.
+func (s *BookingService) FindTime(req *FindTimeRequest, userID string) ([]TimeCandidate, error) {
+	if !req.To.After(req.From) {
+		return nil, fmt.Errorf("%w: 'to' must be after 'from'", utils.ErrInvalidInput)
+	}
+	if req.To.Sub(req.From) > maxAvailabilityDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: a search can cover at most %d days", utils.ErrInvalidInput, maxAvailabilityDays)
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultFindTimeLimit
+	}
+
+	resources, err := s.findTimeResources(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everyone's approved bookings in the range, the caller's included
+	attendees := uniqueStrings(append([]string{userID}, req.Attendees...))
+	attendeeBookings, err := s.BookingRepo.GetFutureApprovedBookingsByUsers(attendees, req.From)
+	if err != nil {
+		return nil, err
+	}
+	var attendeesBusy []TimeInterval
+	for _, b := range attendeeBookings {
+		if b.StartTime.Before(req.To) {
+			attendeesBusy = append(attendeesBusy, TimeInterval{Start: b.StartTime, End: b.EndTime})
+		}
+	}
+
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	now := time.Now()
+	byWindow := make(map[time.Time]*TimeCandidate)
+	for i := range resources {
+		res := &resources[i]
+		rules := res.SlotRules()
+		window := res.BookingWindowFor(req.Role)
+		if !res.IsActive || validateHeadcount(res, len(attendees)-1) != nil {
+			continue
+		}
+		schedule, err := s.scheduleFor(res, req.From, req.To)
+		if err != nil {
+			return nil, err
+		}
+		// Only resources the meeting's length is allowed on
+		probe := alignToSlot(schedule.Local(req.From), rules.Granularity)
+		if validateWindowShape(probe, probe.Add(duration), rules, schedule.Zone) != nil {
+			continue
+		}
+
+		turnover := res.Buffers().Turnover()
+		bookings, err := s.BookingRepo.GetFutureApprovedBookings(res.ID, req.From.Add(-turnover))
+		if err != nil {
+			return nil, err
+		}
+		for _, free := range freeIntervals(res, bookings, schedule, req.From, req.To, now) {
+			for _, gap := range subtractIntervals(free, attendeesBusy) {
+				for start := alignToSlot(gap.Start, rules.Granularity); !start.Add(duration).After(gap.End); start = start.Add(rules.Granularity) {
+					if window.Check(start, now) != nil {
+						continue
+					}
+					key := start.UTC()
+					c, ok := byWindow[key]
+					if !ok {
+						c = &TimeCandidate{StartTime: start, EndTime: start.Add(duration), Resources: []CandidateResource{}}
+						byWindow[key] = c
+					}
+					c.Resources = append(c.Resources, CandidateResource{ResourceID: res.ID, Name: res.Name})
+				}
+			}
+		}
+	}
+
+	candidates := make([]TimeCandidate, 0, len(byWindow))
+	for _, c := range byWindow {
+		candidates = append(candidates, *c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].StartTime.Equal(candidates[j].StartTime) {
+			return candidates[i].StartTime.Before(candidates[j].StartTime)
+		}
+		return len(candidates[i].Resources) > len(candidates[j].Resources)
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// findTimeResources loads the resources a search covers: the ones listed, or those of the type
+// matching the property filters. A type search matching more than maxFindTimeResources fails rather
+// than search only some of them.
+func (s *BookingService) findTimeResources(req *FindTimeRequest) ([]resource.Resource, error) {
+	if (len(req.ResourceIDs) == 0) == (req.ResourceTypeID == nil) {
+		return nil, fmt.Errorf("%w: specify either resource_ids or resource_type_id", utils.ErrInvalidInput)
+	}
+	ids := uniqueInts(req.ResourceIDs)
+	if req.ResourceTypeID != nil {
+		if s.Resources == nil {
+			return nil, fmt.Errorf("%w: resource listing is not configured", utils.ErrInternal)
+		}
+		summaries, total, err := s.Resources.GetAllResources(req.ResourceTypeID, nil, "", resource.PropertyQuery{Filters: resource.PropertyFilters(req.Properties)}, nil, nil, req.Role, utils.PaginationQuery{Page: 1, Limit: maxFindTimeResources})
+		if err != nil {
+			return nil, err
+		}
+		if total > maxFindTimeResources {
+			return nil, fmt.Errorf("%w: %d resources match, narrow the search with properties to at most %d",
+				utils.ErrInvalidInput, total, maxFindTimeResources)
+		}
+		for _, summary := range summaries {
+			ids = append(ids, summary.ID)
+		}
+		if len(ids) == 0 {
+			return []resource.Resource{}, nil
+		}
+	}
+
+	resources, err := s.BookingRepo.GetResourcesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if req.ResourceTypeID == nil && len(resources) != len(ids) {
+		return nil, fmt.Errorf("%w: some of the resources do not exist", utils.ErrNotFound)
+	}
+	return resources, nil
+}
+
+// subtractIntervals returns the parts of iv not covered by any of the busy intervals, in order.
+func subtractIntervals(iv TimeInterval, busy []TimeInterval) []TimeInterval {
+	sorted := append([]TimeInterval(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var parts []TimeInterval
+	cursor := iv.Start
+	for _, b := range sorted {
+		if !b.End.After(cursor) || !b.Start.Before(iv.End) {
+			continue
+		}
+		if b.Start.After(cursor) {
+			parts = append(parts, TimeInterval{Start: cursor, End: b.Start.In(cursor.Location())})
+		}
+		cursor = b.End.In(cursor.Location())
+	}
+	if cursor.Before(iv.End) {
+		parts = append(parts, TimeInterval{Start: cursor, End: iv.End})
+	}
+	return parts
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
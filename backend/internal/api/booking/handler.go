@@ -1,6 +1,7 @@
 package booking
 
 import (
+	"ResourceAllocator/internal/api/resource"
 	"ResourceAllocator/internal/api/utils"
 	"net/http"
 	"strconv"
@@ -11,11 +12,34 @@ import (
 // 1. Service Interface
 type IBookingService interface {
 	CreateBooking(req *BookingCreate, userID string) (*BookingSummary, error)
+	CreateBookingSeries(req *BookingCreate, userID string) (*SeriesResult, error)
+	CancelSeriesOccurrences(bookingID int, scope SeriesScope, userID string) (int, error)
+	UpdateSeriesOccurrences(bookingID int, req *SeriesOccurrenceUpdate, userID string) (*SeriesResult, error)
+	CreateBundle(req *BundleCreate, userID string) (*BundleSummary, error)
+	GetBundle(id int) (*BundleSummary, error)
+	UpdateBundleStatus(id int, req *BookingStatusUpdate, approverID string) error
+	CancelBundle(id int, userID string) error
+	JoinWaitlist(req *WaitlistCreate, userID string) (*WaitlistEntry, error)
+	GetMyWaitlist(userID string, pagination utils.PaginationQuery) ([]WaitlistEntry, int64, error)
+	LeaveWaitlist(id int, userID string) error
 	GetMyBookings(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]BookingSummary, int64, error)
+	GetAttendingBookings(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]BookingSummary, int64, error)
 	GetAllBookings(filters map[string]interface{}, pagination utils.PaginationQuery) ([]BookingSummary, int64, error)
 	CancelBooking(id int, userID string) error
+	RescheduleBooking(id int, req *BookingReschedule, userID string) (*BookingSummary, error)
 	UpdateStatus(id int, req *BookingStatusUpdate, approverID string) error
-	CheckInBooking(bookingId int) error
+	CheckInBooking(bookingId int, actorID string) error
+	GetBookingHistory(id int, userID, role string) ([]BookingEvent, error)
+	SelfCheckIn(bookingID int, userID string) error
+	CheckInWithCode(code string) (*BookingSummary, error)
+	GetCheckInCode(resourceID int) (*CheckInCode, error)
+	ExtendBooking(id int, req *BookingExtend, userID string) (*BookingSummary, error)
+	CheckOutBooking(id int, userID string) (*BookingSummary, error)
+	PreviewAllocation(req *AllocationRequest) (*AllocationPlan, error)
+	CommitAllocation(req *AllocationCommit, approverID string) (*AllocationResult, error)
+	GetResourceAvailability(resourceID int, q *AvailabilityQuery) (*ResourceAvailability, error)
+	FindTime(req *FindTimeRequest, userID string) ([]TimeCandidate, error)
+	GetAvailabilityGrid(filter *resource.ResourceFilter, q *AvailabilityQuery, pagination utils.PaginationQuery) ([]ResourceAvailability, int64, error)
 	GetDashboardResourceStats() ([]DashboardResourceStat, error)
 	GetDashboardUserStats() ([]DashboardUserStat, error)
 }
@@ -36,18 +60,140 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	}
 	var req BookingCreate
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid booking request")
+		utils.BindingError(c, err, "invalid booking request")
 		return
 	}
 	req.Sanitize()
+	req.Role = c.GetString("userRole")
+	if req.Recurrence != "" {
+		result, err := h.service.CreateBookingSeries(&req, userID.(string))
+		if err != nil {
+			utils.RespondError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, result)
+		return
+	}
 	booking, err := h.service.CreateBooking(&req, userID.(string))
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, booking)
 }
 
+func (h *BookingHandler) CreateBundle(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	var req BundleCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid bundle request")
+		return
+	}
+	req.Sanitize()
+	req.Role = c.GetString("userRole")
+	bundle, err := h.service.CreateBundle(&req, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, bundle)
+}
+
+func (h *BookingHandler) GetBundle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid bundle ID")
+		return
+	}
+	bundle, err := h.service.GetBundle(id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bundle)
+}
+
+func (h *BookingHandler) CancelBundle(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid bundle ID")
+		return
+	}
+	if err := h.service.CancelBundle(id, userID.(string)); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "bundle cancelled successfully"})
+}
+
+func (h *BookingHandler) UpdateBundleStatus(c *gin.Context) {
+	approverID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid bundle ID")
+		return
+	}
+	var req BookingStatusUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid status update request")
+		return
+	}
+	if err := h.service.UpdateBundleStatus(id, &req, approverID.(string)); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "bundle status updated successfully"})
+}
+
+// PreviewAllocation shows the best way to settle the pending requests of a resource (or type) in a
+// date range, without changing anything.
+func (h *BookingHandler) PreviewAllocation(c *gin.Context) {
+	var req AllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid allocation request")
+		return
+	}
+	plan, err := h.service.PreviewAllocation(&req)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// CommitAllocation approves and rejects the requests of a previewed plan in one go.
+func (h *BookingHandler) CommitAllocation(c *gin.Context) {
+	approverID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	var req AllocationCommit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid allocation plan")
+		return
+	}
+	result, err := h.service.CommitAllocation(&req, approverID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *BookingHandler) ListMyBookings(c *gin.Context) {
 	userID, exists := c.Get("userUUID")
 	if !exists {
@@ -66,9 +212,20 @@ func (h *BookingHandler) ListMyBookings(c *gin.Context) {
 		filters["resource_id"] = resourceID
 	}
 
-	bookings, total, err := h.service.GetMyBookings(userID.(string), filters, pagination)
+	// ?attending=me lists the bookings the caller attends instead of the ones they made
+	getBookings := h.service.GetMyBookings
+	switch c.Query("attending") {
+	case "":
+	case "me":
+		getBookings = h.service.GetAttendingBookings
+	default:
+		utils.Error(c, http.StatusBadRequest, "attending only supports 'me'")
+		return
+	}
+
+	bookings, total, err := getBookings(userID.(string), filters, pagination)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, utils.GetPaginatedResponse(bookings, pagination.Page, pagination.Limit, total))
@@ -92,7 +249,7 @@ func (h *BookingHandler) ListAllBookings(c *gin.Context) {
 
 	bookings, total, err := h.service.GetAllBookings(filters, pagination)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, utils.GetPaginatedResponse(bookings, pagination.Page, pagination.Limit, total))
@@ -111,12 +268,161 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 		return
 	}
 	if err := h.service.CancelBooking(id, userID.(string)); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "booking cancelled successfully"})
 }
 
+func (h *BookingHandler) CancelSeries(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	var req SeriesCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid series cancel request")
+		return
+	}
+	cancelled, err := h.service.CancelSeriesOccurrences(id, req.Scope, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "occurrences cancelled successfully", "cancelled": cancelled})
+}
+
+func (h *BookingHandler) UpdateSeries(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	var req SeriesOccurrenceUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid series update request")
+		return
+	}
+	req.Sanitize()
+	req.Role = c.GetString("userRole")
+	result, err := h.service.UpdateSeriesOccurrences(id, &req, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *BookingHandler) RescheduleBooking(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	var req BookingReschedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid reschedule request")
+		return
+	}
+	req.Sanitize()
+	req.Role = c.GetString("userRole")
+	booking, err := h.service.RescheduleBooking(id, &req, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *BookingHandler) GetBookingHistory(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	events, err := h.service.GetBookingHistory(id, userID.(string), c.GetString("userRole"))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+func (h *BookingHandler) JoinWaitlist(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	var req WaitlistCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid waitlist request")
+		return
+	}
+	req.Sanitize()
+	req.Role = c.GetString("userRole")
+	entry, err := h.service.JoinWaitlist(&req, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *BookingHandler) ListMyWaitlist(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	pagination := utils.GetPaginationParams(c)
+	entries, total, err := h.service.GetMyWaitlist(userID.(string), pagination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.GetPaginatedResponse(entries, pagination.Page, pagination.Limit, total))
+}
+
+func (h *BookingHandler) LeaveWaitlist(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid waitlist entry ID")
+		return
+	}
+	if err := h.service.LeaveWaitlist(id, userID.(string)); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "removed from waitlist successfully"})
+}
+
 func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 	approverID, exists := c.Get("userUUID")
 	if !exists {
@@ -131,11 +437,11 @@ func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 	}
 	var req BookingStatusUpdate
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid status update request")
+		utils.BindingError(c, err, "invalid status update request")
 		return
 	}
 	if err := h.service.UpdateStatus(id, &req, approverID.(string)); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "booking status updated successfully"})
@@ -149,19 +455,171 @@ func (h *BookingHandler) CheckIn(c *gin.Context) {
 		return
 	}
 
-	err = h.service.CheckInBooking(id)
+	err = h.service.CheckInBooking(id, c.GetString("userUUID"))
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "utilized", "message": "Booking checked in successfully"})
 }
 
+func (h *BookingHandler) SelfCheckIn(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	if err := h.service.SelfCheckIn(id, userID.(string)); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "utilized", "message": "Booking checked in successfully"})
+}
+
+func (h *BookingHandler) CheckInWithCode(c *gin.Context) {
+	var req CheckInCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid check-in request")
+		return
+	}
+	booking, err := h.service.CheckInWithCode(req.Code)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *BookingHandler) GetCheckInCode(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+	code, err := h.service.GetCheckInCode(id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, code)
+}
+
+func (h *BookingHandler) ExtendBooking(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	var req BookingExtend
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid extend request")
+		return
+	}
+	booking, err := h.service.ExtendBooking(id, &req, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *BookingHandler) CheckOut(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+	booking, err := h.service.CheckOutBooking(id, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// GetResourceAvailability returns a resource's busy and free intervals between from and to.
+func (h *BookingHandler) GetResourceAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+	var q AvailabilityQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.BindingError(c, err, "invalid availability query")
+		return
+	}
+	availability, err := h.service.GetResourceAvailability(id, &q)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, availability)
+}
+
+// GetAvailabilityGrid returns the same timeline for every resource matching the ListResources
+// filters, a page at a time.
+func (h *BookingHandler) GetAvailabilityGrid(c *gin.Context) {
+	pagination := utils.GetPaginationParams(c)
+	filter, err := resource.ParseResourceFilter(c)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	var q AvailabilityQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.BindingError(c, err, "invalid availability query")
+		return
+	}
+	grid, total, err := h.service.GetAvailabilityGrid(filter, &q, pagination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.GetPaginatedResponse(grid, pagination.Page, pagination.Limit, total))
+}
+
+// FindTime suggests windows in which a room is free and every attendee can make it.
+func (h *BookingHandler) FindTime(c *gin.Context) {
+	userID, exists := c.Get("userUUID")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "user identity missing")
+		return
+	}
+	var req FindTimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingError(c, err, "invalid find-a-time request")
+		return
+	}
+	req.Role = c.GetString("userRole")
+	candidates, err := h.service.FindTime(&req, userID.(string))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"candidates": candidates})
+}
+
 func (h *BookingHandler) GetDashboardResourceStats(c *gin.Context) {
 	stats, err := h.service.GetDashboardResourceStats()
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	if stats == nil {
@@ -174,7 +632,7 @@ func (h *BookingHandler) GetDashboardResourceStats(c *gin.Context) {
 func (h *BookingHandler) GetDashboardUserStats(c *gin.Context) {
 	stats, err := h.service.GetDashboardUserStats()
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	if stats == nil {
@@ -0,0 +1,77 @@
+package booking
+
+import (
+	"sort"
+	"time"
+)
+
+// Units is the number of units a booking holds. Bookings made before pooled resources existed count as one.
+func (b *Booking) Units() int {
+	if b.Quantity < 1 {
+		return 1
+	}
+	return b.Quantity
+}
+
+// Units is the number of units a waitlist entry waits for.
+func (e *WaitlistEntry) Units() int {
+	if e.Quantity < 1 {
+		return 1
+	}
+	return e.Quantity
+}
+
+// PeakQuantity returns the highest number of units held at the same moment within [start, end)
+// by the given bookings. Bookings outside the window are ignored.
+func PeakQuantity(bookings []Booking, start, end time.Time) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	var events []event
+	for i := range bookings {
+		b := &bookings[i]
+		if !b.StartTime.Before(end) || !b.EndTime.After(start) {
+			continue
+		}
+		from, to := b.StartTime, b.EndTime
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		events = append(events, event{from, b.Units()}, event{to, -b.Units()})
+	}
+	// Releases before acquisitions at the same instant: back-to-back bookings don't overlap
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	peak, current := 0, 0
+	for _, e := range events {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
+
+// WithTurnover returns copies of the bookings stretched by a resource's turnover time, i.e. the span
+// each of them keeps the resource from being booked again. Checking a new window [start, end+turnover)
+// against them then respects both the buffer after the earlier booking and the one before the later.
+func WithTurnover(bookings []Booking, turnover time.Duration) []Booking {
+	if turnover == 0 {
+		return bookings
+	}
+	stretched := make([]Booking, len(bookings))
+	for i, b := range bookings {
+		stretched[i] = b
+		stretched[i].EndTime = b.EndTime.Add(turnover)
+	}
+	return stretched
+}
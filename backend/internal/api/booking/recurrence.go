@@ -0,0 +1,293 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits applied when expanding a recurrence rule so a single request can't flood the table.
+const (
+	MaxSeriesOccurrences = 100
+	MaxSeriesHorizon     = 365 * 24 * time.Hour
+)
+
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+)
+
+// ByDay is one BYDAY entry. Ordinal is only meaningful for MONTHLY rules
+// (e.g. "1MO" = first Monday, "-1FR" = last Friday, "MO" = every Monday).
+type ByDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RecurrenceRule is the supported subset of an RFC 5545 RRULE:
+// FREQ (DAILY/WEEKLY/MONTHLY), INTERVAL, COUNT, UNTIL and BYDAY.
+type RecurrenceRule struct {
+	Freq     Frequency
+	Interval int
+	Count    int
+	Until    *time.Time
+	ByDay    []ByDay
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ParseRecurrenceRule parses strings like "FREQ=WEEKLY;BYDAY=MO;COUNT=10".
+// A leading "RRULE:" prefix is accepted. Either COUNT or UNTIL is required.
+func ParseRecurrenceRule(raw string) (*RecurrenceRule, error) {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "RRULE:")
+	if raw == "" {
+		return nil, fmt.Errorf("%w: recurrence rule is empty", utils.ErrInvalidInput)
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(raw, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: malformed recurrence part '%s'", utils.ErrInvalidInput, part)
+		}
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				rule.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ '%s' (use DAILY, WEEKLY or MONTHLY)", utils.ErrInvalidInput, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive integer", utils.ErrInvalidInput)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive integer", utils.ErrInvalidInput)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, err := parseByDay(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported recurrence part '%s'", utils.ErrInvalidInput, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: recurrence rule requires FREQ", utils.ErrInvalidInput)
+	}
+	if rule.Count == 0 && rule.Until == nil {
+		return nil, fmt.Errorf("%w: recurrence rule requires COUNT or UNTIL", utils.ErrInvalidInput)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be used together", utils.ErrInvalidInput)
+	}
+	if rule.Count > MaxSeriesOccurrences {
+		return nil, fmt.Errorf("%w: COUNT cannot exceed %d", utils.ErrInvalidInput, MaxSeriesOccurrences)
+	}
+	for _, d := range rule.ByDay {
+		if d.Ordinal != 0 && rule.Freq != FreqMonthly {
+			return nil, fmt.Errorf("%w: numbered BYDAY entries are only allowed with FREQ=MONTHLY", utils.ErrInvalidInput)
+		}
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	layouts := []string{"20060102T150405Z", "20060102T150405", "20060102"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: UNTIL must look like 20260131T000000Z", utils.ErrInvalidInput)
+}
+
+func parseByDay(code string) (ByDay, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return ByDay{}, fmt.Errorf("%w: invalid BYDAY value '%s'", utils.ErrInvalidInput, code)
+	}
+	weekday, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return ByDay{}, fmt.Errorf("%w: invalid BYDAY value '%s'", utils.ErrInvalidInput, code)
+	}
+	ordinal := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n > 5 || n < -5 {
+			return ByDay{}, fmt.Errorf("%w: invalid BYDAY ordinal '%s'", utils.ErrInvalidInput, code)
+		}
+		ordinal = n
+	}
+	return ByDay{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+// Expand returns the occurrence start times generated by the rule, beginning at dtstart.
+// Every occurrence keeps the wall-clock time of dtstart in dtstart's location.
+func (r *RecurrenceRule) Expand(dtstart time.Time) []time.Time {
+	horizon := dtstart.Add(MaxSeriesHorizon)
+	limit := MaxSeriesOccurrences
+	if r.Count > 0 {
+		limit = r.Count
+	}
+
+	var occurrences []time.Time
+	for period := 0; len(occurrences) < limit; period++ {
+		candidates := r.periodCandidates(dtstart, period)
+		if len(candidates) == 0 && r.periodStart(dtstart, period).After(horizon) {
+			break
+		}
+		done := false
+		for _, c := range candidates {
+			if c.Before(dtstart) {
+				continue
+			}
+			if c.After(horizon) || (r.Until != nil && c.After(*r.Until)) {
+				done = true
+				break
+			}
+			occurrences = append(occurrences, c)
+			if len(occurrences) == limit {
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+	return occurrences
+}
+
+// periodStart returns the first day of the n-th period (day, week or month) of the rule.
+func (r *RecurrenceRule) periodStart(dtstart time.Time, n int) time.Time {
+	y, m, d := dtstart.Date()
+	h, mi, s := dtstart.Clock()
+	loc := dtstart.Location()
+	switch r.Freq {
+	case FreqWeekly:
+		// Weeks start on Monday (RFC 5545 default WKST=MO)
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset+7*r.Interval*n, h, mi, s, 0, loc)
+	case FreqMonthly:
+		return time.Date(y, m+time.Month(r.Interval*n), 1, h, mi, s, 0, loc)
+	default:
+		return time.Date(y, m, d+r.Interval*n, h, mi, s, 0, loc)
+	}
+}
+
+// periodCandidates lists the sorted occurrence times that fall in the n-th period.
+func (r *RecurrenceRule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	start := r.periodStart(dtstart, n)
+	y, m, d := start.Date()
+	h, mi, s := start.Clock()
+	loc := start.Location()
+
+	var out []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		if len(r.ByDay) == 0 || r.matchesWeekday(start.Weekday()) {
+			out = append(out, start)
+		}
+	case FreqWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []ByDay{{Weekday: dtstart.Weekday()}}
+		}
+		for _, bd := range days {
+			offset := (int(bd.Weekday) + 6) % 7
+			out = append(out, time.Date(y, m, d+offset, h, mi, s, 0, loc))
+		}
+	case FreqMonthly:
+		if len(r.ByDay) == 0 {
+			// Months without this day (e.g. the 31st) are skipped, as in RFC 5545
+			c := time.Date(y, m, dtstart.Day(), h, mi, s, 0, loc)
+			if c.Month() == m {
+				out = append(out, c)
+			}
+			break
+		}
+		daysInMonth := time.Date(y, m+1, 0, 0, 0, 0, 0, loc).Day()
+		for _, bd := range r.ByDay {
+			var matches []time.Time
+			for day := 1; day <= daysInMonth; day++ {
+				c := time.Date(y, m, day, h, mi, s, 0, loc)
+				if c.Weekday() == bd.Weekday {
+					matches = append(matches, c)
+				}
+			}
+			switch {
+			case bd.Ordinal == 0:
+				out = append(out, matches...)
+			case bd.Ordinal > 0 && bd.Ordinal <= len(matches):
+				out = append(out, matches[bd.Ordinal-1])
+			case bd.Ordinal < 0 && -bd.Ordinal <= len(matches):
+				out = append(out, matches[len(matches)+bd.Ordinal])
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupeTimes(out)
+}
+
+func (r *RecurrenceRule) matchesWeekday(w time.Weekday) bool {
+	for _, bd := range r.ByDay {
+		if bd.Weekday == w {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupeTimes(times []time.Time) []time.Time {
+	if len(times) < 2 {
+		return times
+	}
+	out := times[:1]
+	for _, t := range times[1:] {
+		if !t.Equal(out[len(out)-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RescheduleBooking changes the window, purpose and/or resource of the caller's pending or
+// approved booking. The new window goes through the same checks as CreateBooking; whether an
+// approved booking stays approved is decided by the service's ReschedulePolicy.
+func (s *BookingService) RescheduleBooking(id int, req *BookingReschedule, userID string) (*BookingSummary, error) {
+	b, err := s.BookingRepo.GetBookingByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != userID {
+		return nil, fmt.Errorf("%w: you can only change your own bookings", utils.ErrUnauthorized)
+	}
+	if b.Status != StatusPending && b.Status != StatusApproved {
+		return nil, fmt.Errorf("%w: only pending or approved bookings can be rescheduled", utils.ErrInvalidInput)
+	}
+	if b.BundleID != nil {
+		return nil, fmt.Errorf("%w: booking is part of bundle %d and can't be rescheduled on its own", utils.ErrInvalidInput, *b.BundleID)
+	}
+	if !b.StartTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: booking has already started", utils.ErrInvalidInput)
+	}
+
+	old := *b
+	updated := *b
+	if req.ResourceID != nil {
+		updated.ResourceID = *req.ResourceID
+	}
+	if req.StartTime != nil {
+		updated.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		updated.EndTime = *req.EndTime
+	}
+	if req.Purpose != nil {
+		updated.Purpose = *req.Purpose
+	}
+
+	windowChanged := updated.ResourceID != old.ResourceID ||
+		!updated.StartTime.Equal(old.StartTime) || !updated.EndTime.Equal(old.EndTime)
+	if !windowChanged && updated.Purpose == old.Purpose {
+		return nil, fmt.Errorf("%w: nothing to change", utils.ErrInvalidInput)
+	}
+
+	path := PathApprovalRequired
+	var res *resource.Resource
+	if windowChanged {
+		res, err = s.BookingRepo.GetResourceByID(updated.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		if !res.IsActive {
+			return nil, fmt.Errorf("%w: resource is not active", utils.ErrInvalidInput)
+		}
+		schedule, err := s.scheduleFor(res, updated.StartTime, updated.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateWindowShape(updated.StartTime, updated.EndTime, res.SlotRules(), schedule.Zone); err != nil {
+			return nil, err
+		}
+		if err := validateWindowRules(updated.StartTime, updated.EndTime, schedule); err != nil {
+			return nil, err
+		}
+		// A new start or resource has to be bookable now; only shortening the booking doesn't
+		if updated.ResourceID != old.ResourceID || !updated.StartTime.Equal(old.StartTime) {
+			if err := res.BookingWindowFor(req.Role).Check(updated.StartTime, time.Now()); err != nil {
+				return nil, err
+			}
+		}
+		if err := validateQuantity(res, updated.Units()); err != nil {
+			return nil, err
+		}
+		if err := validateHeadcount(res, len(b.Attendees)); err != nil {
+			return nil, err
+		}
+		path = confirmationPathFor(res)
+
+		hasOverlap, err := s.BookingRepo.HasApprovedOverlapExcluding(updated.ResourceID, updated.StartTime, updated.EndTime, updated.Units(), b.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasOverlap {
+			return nil, s.slotConflictError(res, updated.Units(), updated.StartTime, updated.EndTime.Sub(updated.StartTime), updated.ID)
+		}
+		// The new window replaces the old one in the user's quota
+		if err := s.checkQuota(b.UserID, res.TypeID, updated.StartTime, updated.EndTime, b.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case !windowChanged:
+		// Purpose only: status and approval are untouched
+	case path == PathInstant:
+		if updated.Status != StatusApproved {
+			now := time.Now()
+			updated.Status = StatusApproved
+			updated.ApprovedBy = nil
+			updated.ApprovedAt = &now
+		}
+	case updated.Status == StatusApproved && !s.keepsApproval(&old, &updated):
+		updated.Status = StatusPending
+		updated.ApprovedBy = nil
+		updated.ApprovedAt = nil
+	}
+
+	var event *BookingEvent
+	if updated.Status != old.Status {
+		// Instant confirmation approves, the owner's change sends an approval back
+		by, actor, reason := TriggerOwner, userID, "Rescheduled by user, needs approval again"
+		if updated.Status == StatusApproved {
+			by, actor, reason = TriggerSystem, ActorSystem, "Rescheduled onto a resource that confirms instantly"
+		}
+		if err := CheckTransition(old.Status, updated.Status, by); err != nil {
+			return nil, err
+		}
+		event = &BookingEvent{BookingID: b.ID, FromStatus: old.Status, ToStatus: updated.Status, Actor: actor, Reason: reason}
+	}
+
+	change := &BookingChange{
+		BookingID:     b.ID,
+		ChangedBy:     userID,
+		OldResourceID: old.ResourceID,
+		NewResourceID: updated.ResourceID,
+		OldStartTime:  old.StartTime,
+		NewStartTime:  updated.StartTime,
+		OldEndTime:    old.EndTime,
+		NewEndTime:    updated.EndTime,
+		OldPurpose:    old.Purpose,
+		NewPurpose:    updated.Purpose,
+		OldStatus:     old.Status,
+		NewStatus:     updated.Status,
+	}
+	rejected, err := s.BookingRepo.RescheduleBooking(&updated, change, event)
+	if errors.Is(err, utils.ErrConflict) && res != nil {
+		return nil, s.slotConflictError(res, updated.Units(), updated.StartTime, updated.EndTime.Sub(updated.StartTime), updated.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.notifyConflictRejections(rejected)
+
+	// Whatever part of the old window is no longer held goes to the waitlist
+	if windowChanged {
+		s.afterMove(old, updated.Status)
+	}
+
+	full, err := s.BookingRepo.GetBookingByID(b.ID)
+	if err != nil {
+		return nil, err
+	}
+	summary := s.mapToSummary([]Booking{*full})[0]
+
+	subject := "Booking Rescheduled!"
+	body := fmt.Sprintf("Your booking has been updated!\n\nBooking ID: %d\nResource: %s -> %s\nStart Time: %s -> %s\nEnd Time: %s -> %s\nPurpose: %s\nStatus: %s -> %s",
+		full.ID, old.Resource.Name, full.Resource.Name, s.localTime(&old.Resource, old.StartTime), s.localTime(&full.Resource, full.StartTime),
+		s.localTime(&old.Resource, old.EndTime), s.localTime(&full.Resource, full.EndTime), full.Purpose, old.Status, full.Status)
+	utils.SendEmail(body, full.User.Email, subject)
+
+	return &summary, nil
+}
+
+// keepsApproval applies the ReschedulePolicy to an approved booking whose window changed.
+func (s *BookingService) keepsApproval(old, updated *Booking) bool {
+	if s.ReschedulePolicy == ReapproveOnChange {
+		return false
+	}
+	return updated.ResourceID == old.ResourceID &&
+		!updated.StartTime.Before(old.StartTime) && !updated.EndTime.After(old.EndTime)
+}
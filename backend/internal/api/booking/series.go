@@ -0,0 +1,425 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CreateBookingSeries expands the recurrence rule of req into individual occurrences.
+// Each occurrence is validated on its own; the ones that fail are reported as skipped
+// instead of failing the whole request.
+func (s *BookingService) CreateBookingSeries(req *BookingCreate, userID string) (*SeriesResult, error) {
+	rule, err := ParseRecurrenceRule(req.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.BookingRepo.GetResourceByID(req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if !res.IsActive {
+		return nil, fmt.Errorf("%w: resource is not active", utils.ErrInvalidInput)
+	}
+	schedule, err := s.locationSchedule(res)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWindowShape(req.StartTime, req.EndTime, res.SlotRules(), schedule.Zone); err != nil {
+		return nil, err
+	}
+	duration := req.EndTime.Sub(req.StartTime)
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+	if err := validateQuantity(res, quantity); err != nil {
+		return nil, err
+	}
+	attendees, err := s.resolveAttendees(userID, req.Attendees)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateHeadcount(res, len(attendees)); err != nil {
+		return nil, err
+	}
+	path := confirmationPathFor(res)
+	window := res.BookingWindowFor(req.Role)
+	now := time.Now()
+	// Occurrences keep the wall-clock time at the resource's location, across its clock changes
+	starts := rule.Expand(schedule.Local(req.StartTime))
+	if len(starts) > 0 {
+		if err := s.addHolidays(&schedule, res, starts[0], starts[len(starts)-1].Add(duration)); err != nil {
+			return nil, err
+		}
+	}
+
+	var bookings []Booking
+	var skipped []SkippedOccurrence
+	var planned []quota.Planned
+	for _, start := range starts {
+		end := start.Add(duration)
+		if reason := s.occurrenceProblem(req.ResourceID, start, end, quantity, 0, schedule, window); reason != "" {
+			skipped = append(skipped, SkippedOccurrence{StartTime: start, EndTime: end, Reason: reason})
+			continue
+		}
+		// The occurrences kept so far count towards the user's quota too
+		reason, err := quotaProblem(s.checkPlannedQuota(userID, res.TypeID, start, end, planned, nil))
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			skipped = append(skipped, SkippedOccurrence{StartTime: start, EndTime: end, Reason: reason})
+			continue
+		}
+		planned = append(planned, quota.Planned{ResourceTypeID: res.TypeID, StartTime: start, EndTime: end})
+		b := Booking{
+			ResourceID: req.ResourceID,
+			UserID:     userID,
+			StartTime:  start,
+			EndTime:    end,
+			Purpose:    req.Purpose,
+			Status:     StatusPending,
+			Quantity:   quantity,
+			Priority:   req.Priority,
+			Attendees:  copyAttendees(attendees),
+		}
+		if path == PathInstant {
+			b.Status = StatusApproved
+			b.ApprovedAt = &now
+		}
+		bookings = append(bookings, b)
+	}
+	if len(bookings) == 0 {
+		return nil, fmt.Errorf("%w: none of the %d occurrences could be booked", utils.ErrConflict, len(skipped))
+	}
+
+	series := &BookingSeries{
+		ResourceID: req.ResourceID,
+		UserID:     userID,
+		Recurrence: req.Recurrence,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Purpose:    req.Purpose,
+	}
+	rejected, err := s.BookingRepo.CreateBookingSeries(series, bookings)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyConflictRejections(rejected)
+
+	created, err := s.BookingRepo.GetBookingsBySeriesID(series.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := &SeriesResult{Series: *series, ConfirmationPath: path, Created: s.mapToSummary(created), Skipped: skipped}
+	if result.Skipped == nil {
+		result.Skipped = []SkippedOccurrence{}
+	}
+
+	if len(created) > 0 {
+		first := created[0]
+		body := fmt.Sprintf("Thank You for booking a resource, Here is your recurring booking summary: \n\nSeries ID: %d\nResource: %s\nUser: %s\nRule: %s\nOccurrences booked: %d\nOccurrences skipped: %d\n\n%s\n\n%s",
+			series.ID, first.Resource.Name, first.User.Name, series.Recurrence, len(created), len(skipped), path.Describe(), s.describeSkipped(&first.Resource, skipped))
+		utils.SendEmail(body, first.User.Email, "Recurring Booking Summary")
+		if path == PathInstant {
+			body := fmt.Sprintf("A recurring booking you are attending has been confirmed!\n\nSeries ID: %d\nResource: %s\nBooked by: %s\nRule: %s\nFirst occurrence: %s\nOccurrences: %d",
+				series.ID, first.Resource.Name, first.User.Name, series.Recurrence, s.localTime(&first.Resource, first.StartTime), len(created))
+			for _, email := range attendeeEmails(created...) {
+				utils.SendEmail(body, email, "Recurring Booking Confirmed!")
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// CancelSeriesOccurrences cancels the occurrence bookingID, every occurrence from it onwards,
+// or the whole series depending on scope. Returns the number of cancelled bookings.
+func (s *BookingService) CancelSeriesOccurrences(bookingID int, scope SeriesScope, userID string) (int, error) {
+	anchor, targets, err := s.seriesTargets(bookingID, scope, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int
+	for _, b := range targets {
+		if CanTransition(b.Status, StatusCancelled, TriggerOwner) {
+			ids = append(ids, b.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: no cancellable occurrences in the selected scope", utils.ErrInvalidInput)
+	}
+	// Occurrences that changed status since they were read are left alone
+	cancelled, err := s.BookingRepo.UpdateBookingsStatus(ids, TransitionSources(StatusCancelled, TriggerOwner), StatusCancelled, "Cancelled by user", userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(cancelled) == 0 {
+		return 0, fmt.Errorf("%w: no cancellable occurrences in the selected scope", utils.ErrInvalidInput)
+	}
+	for i := range cancelled {
+		from := cancelled[i].Status
+		cancelled[i].Status = StatusCancelled
+		s.afterBatchTransition(&cancelled[i], from)
+	}
+	ids = ids[:0]
+	for _, b := range cancelled {
+		ids = append(ids, b.ID)
+	}
+
+	subject := "Recurring Booking Cancelled!"
+	body := fmt.Sprintf("Occurrences of your recurring booking have been cancelled!\n\nSeries ID: %d\nResource: %s\nScope: %s\nOccurrences cancelled: %d", *anchor.SeriesID, anchor.Resource.Name, scope, len(ids))
+	utils.SendEmail(body, anchor.User.Email, subject)
+	attendeeBody := fmt.Sprintf("Occurrences of a recurring booking you are attending have been cancelled!\n\nSeries ID: %d\nResource: %s\nBooked by: %s\nScope: %s\nOccurrences cancelled: %d", *anchor.SeriesID, anchor.Resource.Name, anchor.User.Name, scope, len(ids))
+	for _, email := range attendeeEmails(cancelled...) {
+		utils.SendEmail(attendeeBody, email, subject)
+	}
+	return len(ids), nil
+}
+
+// UpdateSeriesOccurrences moves and/or renames occurrences of a series. The selected occurrence
+// gets the new window; other occurrences in scope are shifted by the same offset and take the
+// same duration. Occurrences whose new window is invalid or taken are left untouched and reported.
+func (s *BookingService) UpdateSeriesOccurrences(bookingID int, req *SeriesOccurrenceUpdate, userID string) (*SeriesResult, error) {
+	anchor, targets, err := s.seriesTargets(bookingID, req.Scope, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	newStart, newEnd := anchor.StartTime, anchor.EndTime
+	if req.StartTime != nil {
+		newStart = *req.StartTime
+	}
+	if req.EndTime != nil {
+		newEnd = *req.EndTime
+	}
+	schedule, err := s.locationSchedule(&anchor.Resource)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWindowShape(newStart, newEnd, anchor.Resource.SlotRules(), schedule.Zone); err != nil {
+		return nil, err
+	}
+	offset := newStart.Sub(anchor.StartTime)
+	duration := newEnd.Sub(newStart)
+	timeChanged := offset != 0 || duration != anchor.EndTime.Sub(anchor.StartTime)
+	var window resource.BookingWindow
+	var path ConfirmationPath
+	if timeChanged {
+		// The occurrences' resource comes without its type, whose booking window applies
+		res, err := s.BookingRepo.GetResourceByID(anchor.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		window = res.BookingWindowFor(req.Role)
+		path = confirmationPathFor(res)
+	}
+	if timeChanged && len(targets) > 0 {
+		// Occurrences all move by offset, so the holidays between the first and last new window do
+		first, last := targets[0].StartTime, targets[0].StartTime
+		for _, b := range targets {
+			if b.StartTime.Before(first) {
+				first = b.StartTime
+			}
+			if b.StartTime.After(last) {
+				last = b.StartTime
+			}
+		}
+		if err := s.addHolidays(&schedule, &anchor.Resource, first.Add(offset), last.Add(offset+duration)); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []Booking
+	var events []BookingEvent
+	// Moved occurrences count towards the quota at their new window instead of the old one
+	var planned []quota.Planned
+	var moved []int
+	var updatedIDs []int
+	var skipped []SkippedOccurrence
+	var vacated []Booking
+	for i := range targets {
+		b := targets[i]
+		original := targets[i]
+		if b.Status != StatusPending && b.Status != StatusApproved {
+			continue
+		}
+		if req.Purpose != nil {
+			b.Purpose = *req.Purpose
+		}
+		if timeChanged {
+			start := b.StartTime.Add(offset)
+			end := start.Add(duration)
+			if reason := s.occurrenceProblem(b.ResourceID, start, end, b.Units(), b.ID, schedule, window); reason != "" {
+				skipped = append(skipped, SkippedOccurrence{BookingID: b.ID, StartTime: start, EndTime: end, Reason: reason})
+				continue
+			}
+			reason, err := quotaProblem(s.checkPlannedQuota(b.UserID, anchor.Resource.TypeID, start, end, planned, append(moved, b.ID)))
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				skipped = append(skipped, SkippedOccurrence{BookingID: b.ID, StartTime: start, EndTime: end, Reason: reason})
+				continue
+			}
+			planned = append(planned, quota.Planned{ResourceTypeID: anchor.Resource.TypeID, StartTime: start, EndTime: end})
+			moved = append(moved, b.ID)
+			b.StartTime, b.EndTime = start, end
+			// As for a reschedule: a resource that confirms instantly keeps the occurrence approved,
+			// elsewhere a moved occurrence needs a fresh approval unless the policy keeps it
+			switch {
+			case path == PathInstant:
+				if b.Status != StatusApproved {
+					now := time.Now()
+					b.Status = StatusApproved
+					b.ApprovedBy = nil
+					b.ApprovedAt = &now
+				}
+			case b.Status == StatusApproved && !s.keepsApproval(&original, &b):
+				b.Status = StatusPending
+				b.ApprovedBy = nil
+				b.ApprovedAt = nil
+			}
+		}
+		if b.Status != original.Status {
+			by, actor, reason := TriggerOwner, userID, "Series occurrence moved, needs approval again"
+			if b.Status == StatusApproved {
+				by, actor, reason = TriggerSystem, ActorSystem, "Series occurrence moved onto a resource that confirms instantly"
+			}
+			if err := CheckTransition(original.Status, b.Status, by); err != nil {
+				return nil, err
+			}
+			events = append(events, BookingEvent{BookingID: b.ID, FromStatus: original.Status, ToStatus: b.Status, Actor: actor, Reason: reason})
+		}
+		changes = append(changes, b)
+		updatedIDs = append(updatedIDs, b.ID)
+		if timeChanged {
+			vacated = append(vacated, original)
+		}
+	}
+	if len(changes) > 0 {
+		rejected, err := s.BookingRepo.UpdateBookingSchedules(changes, events)
+		if err != nil {
+			return nil, err
+		}
+		s.notifyConflictRejections(rejected)
+	}
+	// Moved occurrences are written in order, each with the window it left
+	for i, old := range vacated {
+		s.afterMove(old, changes[i].Status)
+	}
+	if len(updatedIDs) == 0 && len(skipped) == 0 {
+		return nil, fmt.Errorf("%w: no editable occurrences in the selected scope", utils.ErrInvalidInput)
+	}
+
+	series, err := s.BookingRepo.GetSeriesByID(*anchor.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Scope == ScopeAll && len(skipped) == 0 {
+		series.StartTime = series.StartTime.Add(offset)
+		series.EndTime = series.StartTime.Add(duration)
+		if req.Purpose != nil {
+			series.Purpose = *req.Purpose
+		}
+		if err := s.BookingRepo.UpdateSeries(series); err != nil {
+			return nil, err
+		}
+	}
+
+	occurrences, err := s.BookingRepo.GetBookingsBySeriesID(series.ID)
+	if err != nil {
+		return nil, err
+	}
+	var updated []Booking
+	for _, b := range occurrences {
+		for _, id := range updatedIDs {
+			if b.ID == id {
+				updated = append(updated, b)
+				break
+			}
+		}
+	}
+
+	if len(updated) > 0 {
+		subject := "Recurring Booking Updated!"
+		body := fmt.Sprintf("Occurrences of your recurring booking have been updated!\n\nSeries ID: %d\nResource: %s\nScope: %s\nOccurrences updated: %d\nOccurrences skipped: %d\n\n%s",
+			series.ID, anchor.Resource.Name, req.Scope, len(updated), len(skipped), s.describeSkipped(&anchor.Resource, skipped))
+		utils.SendEmail(body, anchor.User.Email, subject)
+	}
+
+	result := &SeriesResult{Series: *series, Created: s.mapToSummary(updated), Skipped: skipped}
+	if result.Skipped == nil {
+		result.Skipped = []SkippedOccurrence{}
+	}
+	return result, nil
+}
+
+// seriesTargets loads the occurrence bookingID and the occurrences selected by scope (sorted by start time).
+func (s *BookingService) seriesTargets(bookingID int, scope SeriesScope, userID string) (*Booking, []Booking, error) {
+	anchor, err := s.BookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if anchor.UserID != userID {
+		return nil, nil, fmt.Errorf("%w: you can only change your own bookings", utils.ErrUnauthorized)
+	}
+	if anchor.SeriesID == nil {
+		return nil, nil, fmt.Errorf("%w: booking is not part of a recurring series", utils.ErrInvalidInput)
+	}
+
+	switch scope {
+	case ScopeThis:
+		return anchor, []Booking{*anchor}, nil
+	case ScopeFollowing, ScopeAll:
+		occurrences, err := s.BookingRepo.GetBookingsBySeriesID(*anchor.SeriesID)
+		if err != nil {
+			return nil, nil, err
+		}
+		var targets []Booking
+		for _, b := range occurrences {
+			if scope == ScopeAll || !b.StartTime.Before(anchor.StartTime) {
+				targets = append(targets, b)
+			}
+		}
+		return anchor, targets, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: scope must be one of this, following, all", utils.ErrInvalidInput)
+	}
+}
+
+// occurrenceProblem returns a human readable reason why [start, end) can't be booked, or "" if it can.
+// excludeID lets an existing booking be moved without conflicting with itself; schedule is the
+// resource's, holidays included, and window the booking window of the caller's role.
+func (s *BookingService) occurrenceProblem(resourceID int, start, end time.Time, quantity, excludeID int, schedule utils.Schedule, window resource.BookingWindow) string {
+	if err := validateWindowRules(start, end, schedule); err != nil {
+		return strings.TrimPrefix(err.Error(), utils.ErrInvalidInput.Error()+": ")
+	}
+	if err := window.Check(start, time.Now()); err != nil {
+		return strings.TrimPrefix(err.Error(), utils.ErrInvalidInput.Error()+": ")
+	}
+	hasOverlap, err := s.BookingRepo.HasApprovedOverlapExcluding(resourceID, start, end, quantity, excludeID)
+	if err != nil {
+		return "could not check availability"
+	}
+	if hasOverlap {
+		return "slot unavailable"
+	}
+	return ""
+}
+
+func (s *BookingService) describeSkipped(res *resource.Resource, skipped []SkippedOccurrence) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	lines := []string{"Skipped occurrences:"}
+	for _, sk := range skipped {
+		lines = append(lines, fmt.Sprintf("%s - %s", s.localTime(res, sk.StartTime), sk.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,6 +1,9 @@
 package booking
 
 import (
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
 	"ResourceAllocator/internal/api/utils"
 	"errors"
 	"fmt"
@@ -12,48 +15,182 @@ import (
 type IBookingRepo interface {
 	CreateBooking(b *Booking) error
 	GetBookingByID(id int) (*Booking, error)
-	HasApprovedOverlap(resourceID int, start, end time.Time) (bool, error)
+	GetResourceByID(id int) (*resource.Resource, error)
+	GetActiveResourcesByType(typeID int) ([]resource.Resource, error)
+	GetResourcesByIDs(ids []int) ([]resource.Resource, error)
+	GetBookingsInRange(resourceIDs []int, statuses []BookingStatus, from, to time.Time) ([]Booking, error)
+	CreateApprovedBooking(b *Booking) ([]Booking, error)
+	HasApprovedOverlap(resourceID int, start, end time.Time, quantity int) (bool, error)
 	GetPendingOverlaps(resourceID int, start, end time.Time) ([]Booking, error)
 	UpdateBooking(b *Booking) error
+	UpdateBookingStatus(b *Booking, event *BookingEvent) error
+	GetBookingEvents(bookingID int) ([]BookingEvent, error)
 	ApproveBookingAndRejectConflicts(targetBooking *Booking) ([]Booking, error)
 	GetBookingsByUserID(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]Booking, int64, error)
 	GetAllBookings(filters map[string]interface{}, pagination utils.PaginationQuery) ([]Booking, int64, error)
 	GetFutureApprovedBookings(resourceID int, startTime time.Time) ([]Booking, error)
-	CheckInBooking(bookingId int) error
-	ReleaseUncheckedBookings(cutoffTime time.Time) error
-	GetApprovedBookingsStartingAt(startTime time.Time) ([]Booking, error)
-	CancelExpiredPendingBookings(cutoffTime time.Time) error
+	GetFutureApprovedBookingsByUsers(userIDs []string, startTime time.Time) ([]Booking, error)
+	CheckInBooking(bookingId int, actor string) error
+	GetCheckInCandidate(resourceID int, now time.Time, window time.Duration) (*Booking, error)
+	ExtendBooking(b *Booking, newEnd time.Time) ([]Booking, error)
+	CheckOutBooking(bookingID int, checkedOutAt time.Time) error
+	ReleaseUncheckedBookings(cutoffTime time.Time) ([]Booking, error)
+	GetApprovedBookingsStartingBetween(from, to time.Time) ([]Booking, error)
+	CancelExpiredPendingBookings(cutoffTime time.Time) ([]Booking, error)
 	GetTopBookedResources(limit int) ([]DashboardResourceStat, error)
 	GetTopReleasingUsers(limit int) ([]DashboardUserStat, error)
+
+	// Recurring series
+	HasApprovedOverlapExcluding(resourceID int, start, end time.Time, quantity, excludeID int) (bool, error)
+	CreateBookingSeries(series *BookingSeries, bookings []Booking) ([]Booking, error)
+	GetSeriesByID(id int) (*BookingSeries, error)
+	UpdateSeries(series *BookingSeries) error
+	GetBookingsBySeriesID(seriesID int) ([]Booking, error)
+	UpdateBookingsStatus(ids []int, from []BookingStatus, status BookingStatus, reason, actor string) ([]Booking, error)
+	UpdateBookingSchedules(bookings []Booking, events []BookingEvent) ([]Booking, error)
+
+	// Reschedule
+	RescheduleBooking(b *Booking, change *BookingChange, event *BookingEvent) ([]Booking, error)
+
+	// Bundles
+	CreateBundle(bundle *BookingBundle, bookings []Booking) ([]Booking, error)
+	GetBundleByID(id int) (*BookingBundle, error)
+	GetBookingsByBundleID(bundleID int) ([]Booking, error)
+	ApproveBundleAndRejectConflicts(bundle *BookingBundle) ([]Booking, error)
+	UpdateBundleStatus(bundle *BookingBundle, actor, reason string) error
+
+	// Waitlist
+	CreateWaitlistEntry(entry *WaitlistEntry) error
+	GetWaitlistEntryByID(id int) (*WaitlistEntry, error)
+	GetWaitlistEntriesByUserID(userID string, pagination utils.PaginationQuery) ([]WaitlistEntry, int64, error)
+	UpdateWaitlistEntry(entry *WaitlistEntry) error
+	GetWaitingEntries(resourceID int, start, end, now time.Time) ([]WaitlistEntry, error)
+	PromoteWaitlistEntry(entry *WaitlistEntry, b *Booking) error
+	ExpireWaitlistEntries(now time.Time) error
+
+	// Attendees
+	GetUsersByUUIDs(uuids []string) ([]user.User, error)
+	GetBookingsByAttendee(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]Booking, int64, error)
+
+	// Batch allocation
+	GetPendingForAllocation(resourceID, resourceTypeID *int, from, to time.Time) ([]Booking, error)
+	CommitAllocation(approveIDs, rejectIDs []int, approverID string, now time.Time) (approved, rejected []Booking, err error)
+}
+
+// QuotaChecker enforces per-user booking limits. excludeBookingID is a stored pending booking being
+// approved, which must not be counted twice. CheckBookings also counts the bookings planned in the
+// same request (series occurrences, bundle members, an allocation plan) that aren't stored yet.
+type QuotaChecker interface {
+	CheckBooking(userID string, resourceTypeID int, start, end time.Time, excludeBookingID int) error
+	CheckBookings(userID string, resourceTypeID int, start, end time.Time, planned []quota.Planned, excludeIDs []int) error
 }
 
+// HolidayLookup returns the public holidays, between two days, of the calendar resources at a
+// location follow. from and to are in the location's zone, whose days the holidays are.
+type HolidayLookup interface {
+	HolidaysFor(location string, from, to time.Time) (utils.Holidays, error)
+}
+
+// LocationLookup returns the time zone and opening hours at a location (nil for none).
+type LocationLookup interface {
+	ScheduleAt(locationID *int) (utils.Schedule, error)
+}
+
+// How long after the start time a booking can still be checked in before it is auto-released
+const CheckInWindow = 15 * time.Minute
+
+// How long after the start time the check-in reminder goes out, and how often the reminder job runs
+const (
+	ReminderDelay    = 10 * time.Minute
+	ReminderInterval = 5 * time.Minute
+)
+
 type BookingService struct {
 	BookingRepo IBookingRepo
+	// What a waitlist entry becomes when its slot frees up (defaults to a pending booking)
+	WaitlistPolicy WaitlistPolicy
+	// Whether a rescheduled approved booking keeps its approval (defaults to keeping it when the window shrinks)
+	ReschedulePolicy ReschedulePolicy
+	// Page the check-in QR code points to (the code is appended as ?code=); empty means the QR holds the bare code
+	CheckInURL string
+	// Per-user booking limits checked on create and approval; nil means no quotas
+	Quotas QuotaChecker
+	// Resource listing behind the multi-resource availability grid; nil disables the grid
+	Resources ResourceLister
+	// Holiday calendars of the resources' locations; nil leaves only weekends closed
+	Holidays HolidayLookup
+	// Time zones and opening hours of the resources' locations; nil puts every resource on the default schedule
+	Locations LocationLookup
 }
 
 func NewBookingService(repo IBookingRepo) *BookingService {
 	return &BookingService{BookingRepo: repo}
 }
 
+// scheduleFor returns when the resource can be booked: the zone and opening hours of its location,
+// with the holidays of its calendar from the local day of from to that of to.
+func (s *BookingService) scheduleFor(res *resource.Resource, from, to time.Time) (utils.Schedule, error) {
+	schedule, err := s.locationSchedule(res)
+	if err != nil {
+		return utils.Schedule{}, err
+	}
+	if err := s.addHolidays(&schedule, res, from, to); err != nil {
+		return utils.Schedule{}, err
+	}
+	return schedule, nil
+}
+
+// locationSchedule returns the zone and opening hours of the resource's location, without holidays.
+func (s *BookingService) locationSchedule(res *resource.Resource) (utils.Schedule, error) {
+	if s.Locations == nil {
+		return utils.DefaultSchedule(), nil
+	}
+	return s.Locations.ScheduleAt(res.LocationID)
+}
+
+// localTime formats t for an email about a booking of res, in the time zone of the resource's location.
+func (s *BookingService) localTime(res *resource.Resource, t time.Time) string {
+	schedule, err := s.locationSchedule(res)
+	if err != nil {
+		schedule = utils.DefaultSchedule()
+	}
+	return schedule.Local(t).Format("Mon, 02 Jan 2006 15:04 MST")
+}
+
+// addHolidays fills in the holidays of the resource's calendar from the local day of from to that of to.
+func (s *BookingService) addHolidays(schedule *utils.Schedule, res *resource.Resource, from, to time.Time) error {
+	if s.Holidays == nil {
+		return nil
+	}
+	holidays, err := s.Holidays.HolidaysFor(res.Location, schedule.Local(from), schedule.Local(to))
+	if err != nil {
+		return err
+	}
+	schedule.Holidays = holidays
+	return nil
+}
+
 // Helper: Check if a specific slot is valid (Time, History, Weekend)
-func isValidSlot(start time.Time, duration time.Duration) error {
+func isValidSlot(start time.Time, duration time.Duration, schedule utils.Schedule) error {
 	end := start.Add(duration)
-	if err := utils.IsWorkingHours(start, end); err != nil {
+	if err := schedule.IsWorkingHours(start, end); err != nil {
 		return err
 	}
-	return utils.IsHoliday(start)
+	return schedule.IsHoliday(start)
 }
 
-func (s *BookingService) findNextAvailableSlots(resourceID int, initialStart time.Time, duration time.Duration, limit int) ([]time.Time, error) {
-	// 1. Fetch bookings sorted by StartTime (Make sure your Repo sorts them!)
-	bookings, err := s.BookingRepo.GetFutureApprovedBookings(resourceID, initialStart)
-	if err != nil {
-		return nil, err
-	}
+// findNextAvailableSlots suggests start times from initialStart on where quantity units of the
+// resource are free for duration, given its occupying bookings stretched by the turnover (see
+// WithTurnover) and its schedule. Candidates step by the resource's slot granularity, in its local time.
+func findNextAvailableSlots(res *resource.Resource, bookings []Booking, schedule utils.Schedule, quantity int, initialStart time.Time, duration time.Duration, limit int) []time.Time {
+	// A candidate needs its own turnover free after it
+	occupied := duration + res.Buffers().Turnover()
 	var suggestions []time.Time
+	capacity := poolSize(res)
+	step := res.SlotRules().Granularity
 
 	// Start looking from the requested time
-	candidate := initialStart
+	candidate := alignToSlot(schedule.Local(initialStart), step)
 	// Safety limit: look ahead max 7 days
 	endTimeLimit := initialStart.AddDate(0, 0, 7)
 	// Index to track which booking we are currently "near" to avoid re-scanning past bookings
@@ -65,98 +202,198 @@ func (s *BookingService) findNextAvailableSlots(resourceID int, initialStart tim
 		}
 		// 2. Adjust candidate if it falls outside working hours or on a holiday
 		// If this function moves the time forward, loop again to check the new time against bookings
-		if err := isValidSlot(candidate, duration); err != nil {
-			// Jump to next 9 AM
-			nextDay := candidate.AddDate(0, 0, 1)
-			candidate = time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), 9, 0, 0, 0, nextDay.Location())
+		if err := isValidSlot(candidate, duration, schedule); err != nil {
+			// Jump to the opening of the day, if that is still ahead, else to the next day the location opens
+			if open, _, ok := schedule.WorkingHoursOn(candidate); ok && candidate.Before(open) {
+				candidate = alignToSlot(open, step)
+				continue
+			}
+			candidate = alignToSlot(nextOpening(candidate, schedule, endTimeLimit), step)
 			continue
 		}
 		// 3. Fast-Forward past bookings that end before our candidate starts
 		for bookingIdx < totalBookings && bookings[bookingIdx].EndTime.Before(candidate.Add(time.Second)) {
 			bookingIdx++
 		}
-		// 4. Check Collision with the current relevant booking
+		// 4. Check Collision with the bookings overlapping the candidate window
+		// (We already know bookings from bookingIdx on end after Candidate Start from step 3)
 		isOverlapping := false
-		if bookingIdx < totalBookings {
-			b := bookings[bookingIdx]
-			// We only care if the Booking Start is before our Candidate End
-			// (We already know Booking End is after Candidate Start from step 3)
-			if b.StartTime.Before(candidate.Add(duration)) {
-				isOverlapping = true
-				// Optimization: Jump straight to the end of this blocking booking
-				candidate = b.EndTime
+		var overlapping []Booking
+		for i := bookingIdx; i < totalBookings && bookings[i].StartTime.Before(candidate.Add(occupied)); i++ {
+			if bookings[i].EndTime.After(candidate) {
+				overlapping = append(overlapping, bookings[i])
+			}
+		}
+		if len(overlapping) > 0 && PeakQuantity(overlapping, candidate, candidate.Add(occupied))+quantity > capacity {
+			isOverlapping = true
+			// Optimization: Jump straight to the earliest moment a unit is given back
+			next := overlapping[0].EndTime
+			for _, b := range overlapping[1:] {
+				if b.EndTime.Before(next) {
+					next = b.EndTime
+				}
 			}
+			candidate = alignToSlot(schedule.Local(next), step)
 		}
 		// 5. If valid, add to suggestions
 		if !isOverlapping {
 			suggestions = append(suggestions, candidate)
-			// Move forward by 1 hour to give the user alternative start times
-			candidate = candidate.Add(1 * time.Hour)
+			// Move forward by one slot to give the user alternative start times
+			candidate = candidate.Add(step)
 		}
 	}
-	if len(suggestions) == 0 {
-		return nil, errors.New("no slots available in next 7 days")
+	return suggestions
+}
+
+// nextOpening returns the opening time of the first day after t's on which the schedule is open, or
+// limit when there is none before it.
+func nextOpening(t time.Time, schedule utils.Schedule, limit time.Time) time.Time {
+	day := schedule.Local(t)
+	for day.Before(limit) {
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 12, 0, 0, 0, day.Location())
+		if open, _, ok := schedule.WorkingHoursOn(day); ok {
+			return open
+		}
 	}
-	return suggestions, nil
+	return limit.Add(time.Nanosecond)
 }
 
-func (s *BookingService) CreateBooking(req *BookingCreate, userID string) (*BookingSummary, error) {
-	// Use robust timezone loading
-	loc, err := time.LoadLocation("Asia/Kolkata")
-	if err != nil {
-		// Fallback to strict offset if DB is missing (5h 30m = 19800s)
-		loc = time.FixedZone("IST", 5*3600+30*60)
+// validateWindowShape checks the parts of a booking window that don't depend on the calendar:
+// ordering, alignment to the resource's slot granularity (on the clock of zone) and its minimum /
+// maximum duration.
+func validateWindowShape(start, end time.Time, rules resource.SlotRules, zone *time.Location) error {
+	if !end.After(start) {
+		return fmt.Errorf("%w: end time must be after start time", utils.ErrInvalidInput)
 	}
 
-	if req.StartTime.Before(time.Now().In(loc)) {
-		return nil, fmt.Errorf("%w: start time must be in the future", utils.ErrInvalidInput)
+	// Slots are counted from local midnight, so 15 minute slots start at :00, :15, :30 and :45
+	if !isSlotAligned(start.In(zone), rules.Granularity) {
+		return fmt.Errorf("%w: bookings must start on a %s boundary (e.g. 10:00:00)", utils.ErrInvalidInput, describeMinutes(rules.Granularity))
+	}
+
+	// Make sure duration is a multiple of the slot
+	duration := end.Sub(start)
+	if duration%rules.Granularity != 0 {
+		return fmt.Errorf("%w: booking duration must be multiples of %s", utils.ErrInvalidInput, describeMinutes(rules.Granularity))
+	}
+	if duration < rules.MinDuration {
+		return fmt.Errorf("%w: booking must last at least %s", utils.ErrInvalidInput, describeMinutes(rules.MinDuration))
 	}
+	if rules.MaxDuration > 0 && duration > rules.MaxDuration {
+		return fmt.Errorf("%w: booking must not last longer than %s", utils.ErrInvalidInput, describeMinutes(rules.MaxDuration))
+	}
+	return nil
+}
 
-	// A. Validate Time
-	if req.EndTime.Before(req.StartTime) {
-		return nil, fmt.Errorf("%w: end time must be after start time", utils.ErrInvalidInput)
+func isSlotAligned(t time.Time, granularity time.Duration) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return t.Sub(midnight)%granularity == 0
+}
+
+// alignToSlot moves t forward to the next slot boundary (t itself if it is on one).
+func alignToSlot(t time.Time, granularity time.Duration) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if rem := t.Sub(midnight) % granularity; rem != 0 {
+		return t.Add(granularity - rem)
 	}
+	return t
+}
 
-	// [NEW] Strict Whole-Hour Validation
-	if req.StartTime.Minute() != 0 || req.StartTime.Second() != 0 || req.StartTime.Nanosecond() != 0 {
-		return nil, fmt.Errorf("%w: bookings must start exactly at the top of the hour (e.g. 10:00:00)", utils.ErrInvalidInput)
+// alignDownToSlot moves t back to the slot boundary at or before it.
+func alignDownToSlot(t time.Time, granularity time.Duration) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return t.Add(-(t.Sub(midnight) % granularity))
+}
+
+// describeMinutes formats a duration as "1 hour", "3 hours" or "15 minutes".
+func describeMinutes(d time.Duration) string {
+	switch {
+	case d == time.Hour:
+		return "1 hour"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	case d == time.Minute:
+		return "1 minute"
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
 	}
+}
 
-	duration := req.EndTime.Sub(req.StartTime)
-	// Make sure duration is a multiple of 60 minutes
-	if int(duration.Minutes())%60 != 0 {
-		return nil, fmt.Errorf("%w: booking duration must be multiples of 1 hour", utils.ErrInvalidInput)
+// validateWindowRules checks a booking window against the clock and the resource's schedule: it must
+// be in the future, within the opening hours of its location and not on a weekend or one of the holidays.
+func validateWindowRules(start, end time.Time, schedule utils.Schedule) error {
+	if start.Before(time.Now()) {
+		return fmt.Errorf("%w: start time must be in the future", utils.ErrInvalidInput)
 	}
 
-	// 9AM - 5PM check
-	if err := utils.IsWorkingHours(req.StartTime, req.EndTime); err != nil {
-		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
+	// Opening hours check, in the location's time
+	if err := schedule.IsWorkingHours(start, end); err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
 	}
 
 	// Holiday Logic
-	if err := utils.IsHoliday(req.StartTime); err != nil {
-		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
+	if err := schedule.IsHoliday(start); err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+func (s *BookingService) CreateBooking(req *BookingCreate, userID string) (*BookingSummary, error) {
+	if req.Recurrence != "" {
+		return nil, fmt.Errorf("%w: use CreateBookingSeries for recurring bookings", utils.ErrInvalidInput)
+	}
+
+	// A. Resource must exist and be bookable
+	res, err := s.BookingRepo.GetResourceByID(req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if !res.IsActive {
+		return nil, fmt.Errorf("%w: resource is not active", utils.ErrInvalidInput)
+	}
+
+	// B. Validate Time against the resource's slot rules and schedule
+	schedule, err := s.scheduleFor(res, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWindowShape(req.StartTime, req.EndTime, res.SlotRules(), schedule.Zone); err != nil {
+		return nil, err
+	}
+	if err := validateWindowRules(req.StartTime, req.EndTime, schedule); err != nil {
+		return nil, err
+	}
+	// How close to / far ahead of its start this resource can be booked
+	if err := res.BookingWindowFor(req.Role).Check(req.StartTime, time.Now()); err != nil {
+		return nil, err
+	}
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+	if err := validateQuantity(res, quantity); err != nil {
+		return nil, err
+	}
+	attendees, err := s.resolveAttendees(userID, req.Attendees)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateHeadcount(res, len(attendees)); err != nil {
+		return nil, err
 	}
 
-	// B. Approved Overlap Check (Strict)
-	hasOverlap, err := s.BookingRepo.HasApprovedOverlap(req.ResourceID, req.StartTime, req.EndTime)
+	// C. Approved Overlap Check (Strict; on a pool, enough units must be left)
+	hasOverlap, err := s.BookingRepo.HasApprovedOverlap(req.ResourceID, req.StartTime, req.EndTime, quantity)
 	if err != nil {
 		return nil, err
 	}
 	if hasOverlap {
-		slots, err := s.findNextAvailableSlots(req.ResourceID, req.StartTime, duration, 4)
-		msg := "slot unavailable"
-		if err == nil && len(slots) > 0 {
-			var slotStrings []string
-			for _, slot := range slots {
-				slotStrings = append(slotStrings, slot.Format("Mon, 02 Jan 15:04"))
-			}
-			// add a new line character after each time.
-			msg = fmt.Sprintf("slot unavailable. Suggested slots: \n%v", strings.Join(slotStrings, "\n"))
-		}
-		return nil, fmt.Errorf("%w: %s", utils.ErrConflict, msg)
+		return nil, s.bookingConflictError(res, req, quantity, len(attendees))
 	}
-	// C. Create
+	if err := s.checkQuota(userID, res.TypeID, req.StartTime, req.EndTime, 0); err != nil {
+		return nil, err
+	}
+	// D. Create
 	booking := &Booking{
 		ResourceID: req.ResourceID,
 		UserID:     userID,
@@ -164,8 +401,25 @@ func (s *BookingService) CreateBooking(req *BookingCreate, userID string) (*Book
 		EndTime:    req.EndTime,
 		Purpose:    req.Purpose,
 		Status:     StatusPending,
+		Quantity:   quantity,
+		Priority:   req.Priority,
+		Attendees:  attendees,
 	}
-	if err := s.BookingRepo.CreateBooking(booking); err != nil {
+	path := confirmationPathFor(res)
+	if path == PathInstant {
+		// No admin in the loop: approve atomically, the repo re-checks the slot under a lock
+		now := time.Now()
+		booking.Status = StatusApproved
+		booking.ApprovedAt = &now
+		rejected, err := s.BookingRepo.CreateApprovedBooking(booking)
+		if errors.Is(err, utils.ErrConflict) {
+			return nil, s.bookingConflictError(res, req, quantity, len(attendees))
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.notifyConflictRejections(rejected)
+	} else if err := s.BookingRepo.CreateBooking(booking); err != nil {
 		return nil, err
 	}
 
@@ -178,80 +432,183 @@ func (s *BookingService) CreateBooking(req *BookingCreate, userID string) (*Book
 
 	// Map to Summary
 	summary := &BookingSummary{
-		ID:           fullBooking.ID,
-		ResourceName: fullBooking.Resource.Name,
-		UserName:     fullBooking.User.Name,
-		StartTime:    fullBooking.StartTime,
-		EndTime:      fullBooking.EndTime,
-		Status:       fullBooking.Status,
+		ID:               fullBooking.ID,
+		ResourceName:     fullBooking.Resource.Name,
+		UserName:         fullBooking.User.Name,
+		StartTime:        fullBooking.StartTime,
+		EndTime:          fullBooking.EndTime,
+		Status:           fullBooking.Status,
+		Quantity:         fullBooking.Units(),
+		Attendees:        fullBooking.Attendees,
+		ConfirmationPath: path,
 	}
 
-	summaryEmailBody := fmt.Sprintf("Thank You for booking a resource, Here is your summary: \n\n Booking ID: %d\nResource: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s", summary.ID, summary.ResourceName, summary.UserName, summary.StartTime, summary.EndTime, summary.Status)
+	summaryEmailBody := fmt.Sprintf("Thank You for booking a resource, Here is your summary: \n\n Booking ID: %d\nResource: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s\n\n%s", summary.ID, summary.ResourceName, summary.UserName, s.localTime(&fullBooking.Resource, summary.StartTime), s.localTime(&fullBooking.Resource, summary.EndTime), summary.Status, path.Describe())
 
 	utils.SendEmail(summaryEmailBody, fullBooking.User.Email, "Booking Summary")
+	if path == PathInstant {
+		s.notifyAttendees(fullBooking, "Booking Confirmed!", "A booking you are attending has been confirmed!")
+	}
 
 	return summary, nil
 }
 
+// slotConflictError builds the ErrConflict returned when a slot is taken: the bookings in the way and
+// the next free slots. excludeID is a booking being moved, which is in nobody's way.
+func (s *BookingService) slotConflictError(res *resource.Resource, quantity int, start time.Time, duration time.Duration, excludeID int) *SlotConflictError {
+	conflict := &SlotConflictError{ConflictingBookingIDs: []int{}, SuggestedSlots: []SuggestedSlot{}, AlternativeResources: []AlternativeResource{}}
+	// Bookings sorted by StartTime, each blocking until its turnover is over
+	turnover := res.Buffers().Turnover()
+	found, err := s.BookingRepo.GetFutureApprovedBookings(res.ID, start.Add(-turnover))
+	if err != nil {
+		return conflict
+	}
+	var bookings []Booking
+	for _, b := range WithTurnover(found, turnover) {
+		if excludeID != 0 && b.ID == excludeID {
+			continue
+		}
+		bookings = append(bookings, b)
+		if b.StartTime.Before(start.Add(duration+turnover)) && b.EndTime.After(start) {
+			conflict.ConflictingBookingIDs = append(conflict.ConflictingBookingIDs, b.ID)
+		}
+	}
+	// Suggestions look up to a week ahead
+	schedule, err := s.scheduleFor(res, start, start.AddDate(0, 0, 8))
+	if err != nil {
+		return conflict
+	}
+	for _, slot := range findNextAvailableSlots(res, bookings, schedule, quantity, start, duration, 4) {
+		conflict.SuggestedSlots = append(conflict.SuggestedSlots, SuggestedSlot{ResourceID: res.ID, StartTime: slot, EndTime: slot.Add(duration)})
+	}
+	return conflict
+}
+
+// bookingConflictError is the slot conflict for a new booking, which can also move to another
+// resource of the same type that is free at that time and fits its attendees.
+func (s *BookingService) bookingConflictError(res *resource.Resource, req *BookingCreate, quantity, attendees int) error {
+	conflict := s.slotConflictError(res, quantity, req.StartTime, req.EndTime.Sub(req.StartTime), 0)
+	conflict.AlternativeResources = s.findAlternativeResources(res, quantity, attendees, req.StartTime, req.EndTime, req.Role)
+	return conflict
+}
+
+// poolSize is the number of units a resource has; ordinary resources have one.
+func poolSize(res *resource.Resource) int {
+	if res.Quantity < 1 {
+		return 1
+	}
+	return res.Quantity
+}
+
+func validateQuantity(res *resource.Resource, quantity int) error {
+	if quantity > poolSize(res) {
+		return fmt.Errorf("%w: requested quantity %d exceeds the %d unit(s) of this resource", utils.ErrInvalidInput, quantity, poolSize(res))
+	}
+	return nil
+}
+
+func confirmationPathFor(res *resource.Resource) ConfirmationPath {
+	if res.RequiresApproval {
+		return PathApprovalRequired
+	}
+	return PathInstant
+}
+
 func (s *BookingService) UpdateStatus(id int, req *BookingStatusUpdate, approverID string) error {
 	booking, err := s.BookingRepo.GetBookingByID(id)
 	if err != nil {
 		return err
 	}
-	if booking.Status != StatusPending {
-		return fmt.Errorf("%w: can only change status of pending bookings", utils.ErrInvalidInput)
+	if req.Status != StatusApproved && req.Status != StatusRejected {
+		return fmt.Errorf("%w: status can only be set to approved or rejected", utils.ErrInvalidInput)
 	}
+	if err := CheckTransition(booking.Status, req.Status, TriggerAdmin); err != nil {
+		return err
+	}
+	if booking.BundleID != nil {
+		return fmt.Errorf("%w: booking is part of bundle %d, approve or reject the bundle instead", utils.ErrInvalidInput, *booking.BundleID)
+	}
+	from := booking.Status
 	// APPROVE
 	if req.Status == StatusApproved {
-		// 1. Prepare data for approval
+		// 1. The owner may have hit a limit since requesting
+		if err := s.checkQuota(booking.UserID, booking.Resource.TypeID, booking.StartTime, booking.EndTime, booking.ID); err != nil {
+			return err
+		}
+		// 2. Prepare data for approval
 		now := time.Now()
 		booking.Status = StatusApproved
 		booking.ApprovedBy = &approverID
 		booking.ApprovedAt = &now
 
-		// 2. Execute Transaction (Approve + Reject Conflicts in DB)
+		// 3. Execute Transaction (Approve + Reject Conflicts in DB)
 		// Now receives list of rejected bookings for email notification
 		rejectedBookings, err := s.BookingRepo.ApproveBookingAndRejectConflicts(booking)
 		if err != nil {
 			return err
 		}
 
-		// 3. Send Approval Email
-		subject := "Resource Approved!"
-		body := fmt.Sprintf("Your booking has been approved!\n\nBooking ID: %d\nResource: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s", booking.ID, booking.Resource.Name, booking.User.Name, booking.StartTime, booking.EndTime, booking.Status)
-		utils.SendEmail(body, booking.User.Email, subject)
-
-		// 4. Send Rejection Emails (Async preferred but Sync for now)
-		for _, rb := range rejectedBookings {
-			rejectSubject := "Booking Rejected due to Conflict"
-			rejectBody := fmt.Sprintf("Your booking has been rejected because the slot was approved for another request.\n\nBooking ID: %d\nResource: %s\nStart Time: %v\nEnd Time: %v", rb.ID, rb.Resource.Name, rb.StartTime, rb.EndTime)
-			// Ensure we have the user email. Preload in repo handles this.
-			if rb.User.Email != "" {
-				utils.SendEmail(rejectBody, rb.User.Email, rejectSubject)
-			}
-		}
+		// 4. Send Approval Emails
+		s.afterTransition(booking, from)
+
+		// 5. Send Rejection Emails (Async preferred but Sync for now)
+		s.notifyConflictRejections(rejectedBookings)
 
 		return nil
 	}
 	// REJECT
-	if req.Status == StatusRejected {
-		booking.Status = StatusRejected
-		booking.RejectionReason = req.RejectionReason
-		approverIDVal := approverID
-		booking.ApprovedBy = &approverIDVal // Track who rejected it
-		now := time.Now()
-		booking.ApprovedAt = &now // Track when it was rejected
-		// Use UpdateBooking (since UpdateStatus was not available in your repo)
-		err := s.BookingRepo.UpdateBooking(booking)
-		if err != nil {
-			return err
-		}
-		subject := "Resource Rejected!"
-		body := fmt.Sprintf("Your booking has been rejected!\n\nBooking ID: %d\nResource: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s\n Reason: %s", booking.ID, booking.Resource.Name, booking.User.Name, booking.StartTime, booking.EndTime, booking.Status, booking.RejectionReason)
-		utils.SendEmail(body, booking.User.Email, subject)
+	event := &BookingEvent{BookingID: booking.ID, FromStatus: from, ToStatus: StatusRejected, Actor: approverID, Reason: req.RejectionReason}
+	booking.Status = StatusRejected
+	booking.RejectionReason = req.RejectionReason
+	approverIDVal := approverID
+	booking.ApprovedBy = &approverIDVal // Track who rejected it
+	now := time.Now()
+	booking.ApprovedAt = &now // Track when it was rejected
+	if err := s.BookingRepo.UpdateBookingStatus(booking, event); err != nil {
+		return err
+	}
+	s.afterTransition(booking, from)
+	return nil
+}
+
+func (s *BookingService) checkQuota(userID string, resourceTypeID int, start, end time.Time, excludeBookingID int) error {
+	if s.Quotas == nil {
+		return nil
+	}
+	return s.Quotas.CheckBooking(userID, resourceTypeID, start, end, excludeBookingID)
+}
+
+// checkPlannedQuota is checkQuota for a booking decided together with the planned ones; the stored
+// bookings in excludeIDs are being replaced and aren't counted.
+func (s *BookingService) checkPlannedQuota(userID string, resourceTypeID int, start, end time.Time, planned []quota.Planned, excludeIDs []int) error {
+	if s.Quotas == nil {
 		return nil
 	}
-	return fmt.Errorf("%w: invalid status transition", utils.ErrInvalidInput)
+	return s.Quotas.CheckBookings(userID, resourceTypeID, start, end, planned, excludeIDs)
+}
+
+// quotaProblem turns a quota check into a reason for skipping an occurrence, like occurrenceProblem.
+// Errors other than an exceeded quota are returned.
+func quotaProblem(err error) (string, error) {
+	if err == nil {
+		return "", nil
+	}
+	if !errors.Is(err, utils.ErrConflict) {
+		return "", err
+	}
+	return strings.TrimPrefix(err.Error(), utils.ErrConflict.Error()+": "), nil
+}
+
+// notifyConflictRejections emails the owners of bookings auto-rejected because an overlapping request was approved.
+func (s *BookingService) notifyConflictRejections(rejectedBookings []Booking) {
+	for _, rb := range rejectedBookings {
+		rejectSubject := "Booking Rejected due to Conflict"
+		rejectBody := fmt.Sprintf("Your booking has been rejected because the slot was approved for another request.\n\nBooking ID: %d\nResource: %s\nStart Time: %s\nEnd Time: %s\nReason: %s", rb.ID, rb.Resource.Name, s.localTime(&rb.Resource, rb.StartTime), s.localTime(&rb.Resource, rb.EndTime), rb.RejectionReason)
+		// Ensure we have the user email. Preload in repo handles this.
+		if rb.User.Email != "" {
+			utils.SendEmail(rejectBody, rb.User.Email, rejectSubject)
+		}
+	}
 }
 
 func (s *BookingService) CancelBooking(id int, userID string) error {
@@ -263,21 +620,43 @@ func (s *BookingService) CancelBooking(id int, userID string) error {
 	if booking.UserID != userID {
 		return fmt.Errorf("%w: you can only cancel your own bookings", utils.ErrUnauthorized)
 	}
-	if booking.Status == StatusCancelled || booking.Status == StatusRejected {
-		return fmt.Errorf("%w: booking is already cancelled or rejected", utils.ErrInvalidInput)
+	if booking.BundleID != nil {
+		return fmt.Errorf("%w: booking is part of bundle %d, cancel the whole bundle with PATCH /api/bookings/bundles/%d/cancel", utils.ErrInvalidInput, *booking.BundleID, *booking.BundleID)
+	}
+	if err := CheckTransition(booking.Status, StatusCancelled, TriggerOwner); err != nil {
+		return err
 	}
-	// Reuse Update logic, but specifically for Cancel
+	from := booking.Status
+	event := &BookingEvent{BookingID: booking.ID, FromStatus: from, ToStatus: StatusCancelled, Actor: userID, Reason: "Cancelled by user"}
 	booking.Status = StatusCancelled
-	err = s.BookingRepo.UpdateBooking(booking)
+	err = s.BookingRepo.UpdateBookingStatus(booking, event)
 	if err != nil {
 		return err
 	}
-	subject := "Resource Cancelled!"
-	body := fmt.Sprintf("Your booking has been cancelled!\n\nBooking ID: %d\nResource: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s", booking.ID, booking.Resource.Name, booking.User.Name, booking.StartTime, booking.EndTime, booking.Status)
-	utils.SendEmail(body, booking.User.Email, subject)
+	s.afterTransition(booking, from)
 	return nil
 }
 
+// GetBookingHistory returns the status changes of a booking, oldest first. Only its owner and admins
+// can see them.
+func (s *BookingService) GetBookingHistory(id int, userID, role string) ([]BookingEvent, error) {
+	booking, err := s.BookingRepo.GetBookingByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID && role != string(user.RoleAdmin) {
+		return nil, fmt.Errorf("%w: you can only see the history of your own bookings", utils.ErrUnauthorized)
+	}
+	events, err := s.BookingRepo.GetBookingEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []BookingEvent{}
+	}
+	return events, nil
+}
+
 func (s *BookingService) GetMyBookings(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]BookingSummary, int64, error) {
 	bookings, total, err := s.BookingRepo.GetBookingsByUserID(userID, filters, pagination)
 	if err != nil {
@@ -306,64 +685,96 @@ func (s *BookingService) mapToSummary(bookings []Booking) []BookingSummary {
 			EndTime:      b.EndTime,
 			Purpose:      b.Purpose,
 			Status:       b.Status,
+			Quantity:     b.Units(),
+			Priority:     b.Priority,
+			SeriesID:     b.SeriesID,
+			BundleID:     b.BundleID,
+			Attendees:    b.Attendees,
 		}
 	}
 	return summaries
 }
 
-func (s *BookingService) CheckInBooking(bookingId int) error {
+// CheckInBooking checks in a booking on behalf of its owner (admins).
+func (s *BookingService) CheckInBooking(bookingId int, actorID string) error {
 	booking, err := s.BookingRepo.GetBookingByID(bookingId)
 
 	if err != nil {
 		return err
 	}
 
-	if booking.Status != BookingStatus(StatusApproved) {
-		return fmt.Errorf("%w: Cannot checkin unapproved/ released bookings", utils.ErrInvalidInput)
+	return s.checkIn(booking, actorID, TriggerAdmin)
+}
+
+// checkIn applies the check-in window rules shared by every way of checking in. actor is recorded
+// in the booking's history.
+func (s *BookingService) checkIn(booking *Booking, actor string, by Trigger) error {
+	if err := CheckTransition(booking.Status, StatusUtilized, by); err != nil {
+		return err
 	}
 
 	if booking.StartTime.After(time.Now()) {
 		return fmt.Errorf("%w: Checkin can only be done within 15 minutes of start time", utils.ErrInvalidInput)
 	}
 
-	if time.Now().After(booking.StartTime.Add(15 * time.Minute)) {
+	if time.Now().After(booking.StartTime.Add(CheckInWindow)) {
 		return fmt.Errorf("%w: Checkin time expired", utils.ErrUnauthorized)
 	}
 
-	return s.BookingRepo.CheckInBooking(bookingId)
+	return s.BookingRepo.CheckInBooking(booking.ID, actor)
 }
 
 // RunAutoReleaseJob finds approved bookings started >15 mins ago that haven't been checked in
 // and releases them. Run this via a background ticker.
 func (s *BookingService) RunAutoReleaseJob() error {
+	if err := CheckTransition(StatusApproved, StatusReleased, TriggerSystem); err != nil {
+		return err
+	}
 	// 15 minutes ago
-	cutoffTime := time.Now().Add(-15 * time.Minute)
-	return s.BookingRepo.ReleaseUncheckedBookings(cutoffTime)
+	cutoffTime := time.Now().Add(-CheckInWindow)
+	released, err := s.BookingRepo.ReleaseUncheckedBookings(cutoffTime)
+	if err != nil {
+		return err
+	}
+	// The unused remainder of each released booking goes to the waitlist
+	for i := range released {
+		released[i].Status = StatusReleased
+		s.afterTransition(&released[i], StatusApproved)
+	}
+	return nil
 }
 
+// SendCheckInReminders reminds the owners of approved bookings that started ReminderDelay ago, give or
+// take the ReminderInterval since the previous run, to check in. Runs are aligned to the interval, so
+// every booking is reminded once whatever the time zone of its resource.
 func (s *BookingService) SendCheckInReminders() error {
-	// 1. Calculate the booking start time we are interested in.
-	// Use time.Date to safely truncate to the hour in Local time (Handles IST +5:30 correctly)
-	now := time.Now()
-	bookingStartTime := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	// 1. Calculate the start times we are interested in: those of the last interval, ReminderDelay ago
+	to := time.Now().Truncate(ReminderInterval).Add(-ReminderDelay)
+	from := to.Add(-ReminderInterval)
 
 	// 2. Find customers who haven't checked in yet
-	bookings, err := s.BookingRepo.GetApprovedBookingsStartingAt(bookingStartTime)
+	bookings, err := s.BookingRepo.GetApprovedBookingsStartingBetween(from, to)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("Check-in Reminder Job: Looking for bookings at %s. Found %d bookings.", bookingStartTime, len(bookings))
+	log.Printf("Check-in Reminder Job: Looking for bookings started in (%s, %s]. Found %d bookings.", from.UTC(), to.UTC(), len(bookings))
 
-	// 3. Send Reminder Emails
+	// 3. Send Reminder Emails, with the start time on the clock of the resource's location
 	for _, b := range bookings {
+		schedule, err := s.locationSchedule(&b.Resource)
+		if err != nil {
+			schedule = utils.DefaultSchedule()
+		}
+		started := schedule.Local(b.StartTime).Format("15:04 MST")
 		log.Printf("Sending reminder to user %s (%s) for booking %d", b.User.Name, b.User.Email, b.ID)
 		subject := "Reminder: Check-in to your Booking!"
-		body := fmt.Sprintf("Hello %s,\n\nYou have a booking for %s that started at %s.\n\nPlease check in within the next 5 minutes to avoid auto-cancellation!",
-			b.User.Name, b.Resource.Name, b.StartTime.Format("15:04"))
+		body := fmt.Sprintf("Hello %s,\n\nYou have a booking for %s that started at %s.\n\nPlease check in within the next %s to avoid auto-cancellation!",
+			b.User.Name, b.Resource.Name, started, describeMinutes(CheckInWindow-ReminderDelay))
 
 		// This uses your new async email worker!
 		utils.SendEmail(body, b.User.Email, subject)
+		s.notifyAttendees(&b, "Reminder: Your Booking Has Started!", fmt.Sprintf("A booking you are attending on %s started at %s.", b.Resource.Name, started))
 	}
 
 	return nil
@@ -371,7 +782,15 @@ func (s *BookingService) SendCheckInReminders() error {
 
 func (s *BookingService) RunAutoCancellationJob() error {
 	// Cancel any pending booking where start_time < now
-	return s.BookingRepo.CancelExpiredPendingBookings(time.Now())
+	cancelled, err := s.BookingRepo.CancelExpiredPendingBookings(time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range cancelled {
+		cancelled[i].Status = StatusCancelled
+		s.afterTransition(&cancelled[i], StatusPending)
+	}
+	return nil
 }
 
 func (s *BookingService) GetDashboardResourceStats() ([]DashboardResourceStat, error) {
@@ -0,0 +1,131 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Trigger is who asks for a status change.
+type Trigger string
+
+const (
+	TriggerOwner  Trigger = "owner"  // The user who made the booking (or whoever checks in at the resource for them)
+	TriggerAdmin  Trigger = "admin"  // An admin deciding on or acting for a booking
+	TriggerSystem Trigger = "system" // Background jobs, instant confirmation and conflict resolution
+)
+
+// transitionRule is one allowed status change: who may make it and what follows once it is stored.
+type transitionRule struct {
+	triggers []Trigger
+	// The owner is emailed "Your booking has been <status>!" and, with notifyAttendees, the attendees too
+	notifyOwner     bool
+	notifyAttendees bool
+	// The slot the booking held is given back and offered to the waitlist
+	freesSlot bool
+}
+
+// transitions is the booking state machine: from status -> to status -> rule. Bundles follow it too.
+// Anything not listed is illegal; rejected, cancelled and released are final.
+var transitions = map[BookingStatus]map[BookingStatus]transitionRule{
+	StatusPending: {
+		StatusApproved:  {triggers: []Trigger{TriggerAdmin, TriggerSystem}, notifyOwner: true, notifyAttendees: true},
+		StatusRejected:  {triggers: []Trigger{TriggerAdmin, TriggerSystem}, notifyOwner: true, freesSlot: true},
+		StatusCancelled: {triggers: []Trigger{TriggerOwner, TriggerSystem}, notifyOwner: true, notifyAttendees: true, freesSlot: true},
+	},
+	StatusApproved: {
+		StatusPending:   {triggers: []Trigger{TriggerOwner}, freesSlot: true}, // Rescheduled, waits for approval again
+		StatusUtilized:  {triggers: []Trigger{TriggerOwner, TriggerAdmin}},
+		StatusCancelled: {triggers: []Trigger{TriggerOwner}, notifyOwner: true, notifyAttendees: true, freesSlot: true},
+		StatusReleased:  {triggers: []Trigger{TriggerSystem}, freesSlot: true},
+	},
+}
+
+// CheckTransition returns an ErrInvalidInput unless by may move a booking from one status to the other.
+func CheckTransition(from, to BookingStatus, by Trigger) error {
+	rule, ok := transitions[from][to]
+	if !ok {
+		return fmt.Errorf("%w: a %s booking can't become %s", utils.ErrInvalidInput, from, to)
+	}
+	for _, t := range rule.triggers {
+		if t == by {
+			return nil
+		}
+	}
+	var allowed []string
+	for _, t := range rule.triggers {
+		allowed = append(allowed, string(t))
+	}
+	return fmt.Errorf("%w: a %s booking can only become %s by the %s", utils.ErrInvalidInput, from, to, strings.Join(allowed, " or "))
+}
+
+// CanTransition reports whether by may move a booking from one status to the other.
+func CanTransition(from, to BookingStatus, by Trigger) bool {
+	return CheckTransition(from, to, by) == nil
+}
+
+// TransitionSources lists the statuses from which by may move a booking to the status to.
+func TransitionSources(to BookingStatus, by Trigger) []BookingStatus {
+	var from []BookingStatus
+	for status := range transitions {
+		if CanTransition(status, to, by) {
+			from = append(from, status)
+		}
+	}
+	sort.Slice(from, func(i, j int) bool { return from[i] < from[j] })
+	return from
+}
+
+// afterTransition runs the side effects the state machine declares for a stored change of b from
+// the status from to its current one.
+func (s *BookingService) afterTransition(b *Booking, from BookingStatus) {
+	rule := transitions[from][b.Status]
+	if rule.notifyOwner {
+		subject := fmt.Sprintf("Resource %s!", statusTitle(b.Status))
+		body := fmt.Sprintf("Your booking has been %s!\n\nBooking ID: %d\nResource: %s\nUser: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s", b.Status, b.ID, b.Resource.Name, b.User.Name, s.localTime(&b.Resource, b.StartTime), s.localTime(&b.Resource, b.EndTime), b.Status)
+		if b.Status != StatusApproved && b.RejectionReason != "" {
+			body += fmt.Sprintf("\n Reason: %s", b.RejectionReason)
+		}
+		utils.SendEmail(body, b.User.Email, subject)
+		if rule.notifyAttendees {
+			s.notifyAttendees(b, subject, fmt.Sprintf("A booking you are attending has been %s!", b.Status))
+		}
+	}
+	if rule.freesSlot {
+		s.promoteWaitlist(b.ResourceID, b.StartTime, b.EndTime)
+	}
+}
+
+// afterBatchTransition runs the side effects of a change made to many bookings at once, whose owner
+// gets a single summary email instead of one per booking: only the slot is dealt with here.
+func (s *BookingService) afterBatchTransition(b *Booking, from BookingStatus) {
+	if transitions[from][b.Status].freesSlot {
+		s.promoteWaitlist(b.ResourceID, b.StartTime, b.EndTime)
+	}
+}
+
+// afterMove runs the side effects of moving old, as stored before, to another window with the status
+// to. The move frees the old window, so the change's rule applies to it; a booking that stays
+// approved frees it all the same. The owner is emailed about the move by the caller.
+func (s *BookingService) afterMove(old Booking, to BookingStatus) {
+	// Only an approved booking held its window
+	if old.Status != StatusApproved {
+		return
+	}
+	if to == StatusApproved {
+		s.promoteWaitlist(old.ResourceID, old.StartTime, old.EndTime)
+		return
+	}
+	from := old.Status
+	old.Status = to
+	s.afterBatchTransition(&old, from)
+}
+
+// statusTitle is the status capitalized for email subjects, e.g. "Approved".
+func statusTitle(status BookingStatus) string {
+	if status == "" {
+		return ""
+	}
+	return strings.ToUpper(string(status[:1])) + string(status[1:])
+}
@@ -0,0 +1,190 @@
+package booking
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"log"
+	"time"
+)
+
+// JoinWaitlist queues the user for an occupied slot. Free slots should be booked directly.
+func (s *BookingService) JoinWaitlist(req *WaitlistCreate, userID string) (*WaitlistEntry, error) {
+	res, err := s.BookingRepo.GetResourceByID(req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.scheduleFor(res, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWindowShape(req.StartTime, req.EndTime, res.SlotRules(), schedule.Zone); err != nil {
+		return nil, err
+	}
+	if err := validateWindowRules(req.StartTime, req.EndTime, schedule); err != nil {
+		return nil, err
+	}
+	// A promoted entry becomes a booking, so it must be one the user could make
+	if err := res.BookingWindowFor(req.Role).Check(req.StartTime, time.Now()); err != nil {
+		return nil, err
+	}
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+	if err := validateQuantity(res, quantity); err != nil {
+		return nil, err
+	}
+
+	hasOverlap, err := s.BookingRepo.HasApprovedOverlap(req.ResourceID, req.StartTime, req.EndTime, quantity)
+	if err != nil {
+		return nil, err
+	}
+	if !hasOverlap {
+		return nil, fmt.Errorf("%w: slot is available, book it directly instead of joining the waitlist", utils.ErrInvalidInput)
+	}
+
+	// Until it ends, what is left of the window can still be promoted
+	expiresAt := req.EndTime
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) || req.ExpiresAt.After(req.EndTime) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future and no later than the end time", utils.ErrInvalidInput)
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	entry := &WaitlistEntry{
+		ResourceID: req.ResourceID,
+		UserID:     userID,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Purpose:    req.Purpose,
+		Quantity:   quantity,
+		Status:     WaitlistWaiting,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.BookingRepo.CreateWaitlistEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *BookingService) GetMyWaitlist(userID string, pagination utils.PaginationQuery) ([]WaitlistEntry, int64, error) {
+	entries, total, err := s.BookingRepo.GetWaitlistEntriesByUserID(userID, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range entries {
+		entries[i].ResourceName = entries[i].Resource.Name
+	}
+	return entries, total, nil
+}
+
+func (s *BookingService) LeaveWaitlist(id int, userID string) error {
+	entry, err := s.BookingRepo.GetWaitlistEntryByID(id)
+	if err != nil {
+		return err
+	}
+	if entry.UserID != userID {
+		return fmt.Errorf("%w: you can only remove your own waitlist entries", utils.ErrUnauthorized)
+	}
+	if entry.Status != WaitlistWaiting {
+		return fmt.Errorf("%w: waitlist entry is already %s", utils.ErrInvalidInput, entry.Status)
+	}
+	entry.Status = WaitlistCancelled
+	return s.BookingRepo.UpdateWaitlistEntry(entry)
+}
+
+// RunWaitlistExpiryJob marks entries whose expiry time has passed as expired.
+func (s *BookingService) RunWaitlistExpiryJob() error {
+	return s.BookingRepo.ExpireWaitlistEntries(time.Now())
+}
+
+// promoteWaitlist is called whenever [start, end) on a resource frees up (cancel, release, reject,
+// early check-out). Entries are considered oldest first; an entry whose window has already started
+// is offered the rest of it. An entry is promoted when that window is free and it doesn't come
+// within the turnover time of an entry promoted earlier in the same pass. Errors are logged, not
+// returned, since the action that freed the slot has already succeeded.
+func (s *BookingService) promoteWaitlist(resourceID int, start, end time.Time) {
+	now := time.Now()
+	if !end.After(now) {
+		return
+	}
+	entries, err := s.BookingRepo.GetWaitingEntries(resourceID, start, end, now)
+	if err != nil {
+		log.Printf("Waitlist: failed to load entries for resource %d: %v", resourceID, err)
+		return
+	}
+
+	var promoted []WaitlistEntry
+	for i := range entries {
+		entry := entries[i]
+		if entry.StartTime.Before(now) {
+			entry.StartTime = now.Truncate(time.Minute)
+		}
+		if overlapsAny(entry, promoted, entry.Resource.Buffers().Turnover()) {
+			continue
+		}
+		busy, err := s.BookingRepo.HasApprovedOverlap(entry.ResourceID, entry.StartTime, entry.EndTime, entry.Units())
+		if err != nil {
+			log.Printf("Waitlist: failed to check availability for entry %d: %v", entry.ID, err)
+			continue
+		}
+		if busy {
+			continue
+		}
+		if err := s.promoteEntry(&entry); err != nil {
+			log.Printf("Waitlist: failed to promote entry %d: %v", entry.ID, err)
+			continue
+		}
+		promoted = append(promoted, entry)
+	}
+}
+
+func (s *BookingService) promoteEntry(entry *WaitlistEntry) error {
+	// An entry the user no longer has quota for stays waiting
+	if err := s.checkQuota(entry.UserID, entry.Resource.TypeID, entry.StartTime, entry.EndTime, 0); err != nil {
+		return err
+	}
+	b := &Booking{
+		ResourceID: entry.ResourceID,
+		UserID:     entry.UserID,
+		StartTime:  entry.StartTime,
+		EndTime:    entry.EndTime,
+		Purpose:    entry.Purpose,
+		Status:     StatusPending,
+		Quantity:   entry.Units(),
+	}
+	if err := s.BookingRepo.PromoteWaitlistEntry(entry, b); err != nil {
+		return err
+	}
+
+	// Resources without approval confirm instantly anyway; for the rest the configured policy decides
+	if s.WaitlistPolicy == PromoteToApproved || !entry.Resource.RequiresApproval {
+		now := time.Now()
+		actor := ActorWaitlist
+		b.Status = StatusApproved
+		b.ApprovedBy = &actor
+		b.ApprovedAt = &now
+		rejected, err := s.BookingRepo.ApproveBookingAndRejectConflicts(b)
+		if err != nil {
+			return err
+		}
+		s.notifyConflictRejections(rejected)
+	}
+
+	subject := "Waitlisted Slot Available!"
+	body := fmt.Sprintf("Good news! A slot you were waiting for has freed up and has been booked for you.\n\nBooking ID: %d\nResource: %s\nStart Time: %s\nEnd Time: %s\nStatus: %s",
+		b.ID, entry.Resource.Name, s.localTime(&entry.Resource, b.StartTime), s.localTime(&entry.Resource, b.EndTime), b.Status)
+	utils.SendEmail(body, entry.User.Email, subject)
+	return nil
+}
+
+// overlapsAny reports whether entry comes within turnover of any of the others.
+func overlapsAny(entry WaitlistEntry, others []WaitlistEntry, turnover time.Duration) bool {
+	for _, o := range others {
+		if entry.StartTime.Before(o.EndTime.Add(turnover)) && entry.EndTime.Add(turnover).After(o.StartTime) {
+			return true
+		}
+	}
+	return false
+}
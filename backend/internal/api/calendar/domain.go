@@ -0,0 +1,53 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+)
+
+// HolidayCalendar is a set of public holidays. It applies to the resources at the locations it
+// lists; resources elsewhere follow the default calendar, if there is one.
+type HolidayCalendar struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" binding:"required" gorm:"unique"`
+	Description string    `json:"description"`
+	IsDefault   bool      `json:"is_default" gorm:"default:false"` // At most one calendar is the default
+	Locations   []string  `json:"locations" gorm:"type:jsonb;serializer:json"`
+	Holidays    []Holiday `json:"holidays,omitempty" gorm:"foreignKey:CalendarID"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Holiday is one closed day of a calendar. The date is a local date: a resource is closed on it in
+// the time zone of its own location.
+type Holiday struct {
+	ID         int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	CalendarID int    `json:"calendar_id" gorm:"uniqueIndex:idx_calendar_holiday_date"`
+	Date       string `json:"date" binding:"required,datetime=2006-01-02" gorm:"size:10;uniqueIndex:idx_calendar_holiday_date"`
+	Name       string `json:"name" binding:"required"`
+}
+
+// ImportResult reports what an iCalendar import did: holidays added, existing dates renamed and
+// events that couldn't be read.
+type ImportResult struct {
+	Added   int      `json:"added"`
+	Updated int      `json:"updated"`
+	Skipped []string `json:"skipped"`
+}
+
+func (c *HolidayCalendar) Sanitize() {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	var locations []string
+	for _, l := range c.Locations {
+		if l = strings.TrimSpace(l); l != "" {
+			locations = append(locations, l)
+		}
+	}
+	c.Locations = locations
+}
+
+func (h *Holiday) Sanitize() {
+	h.Date = strings.TrimSpace(h.Date)
+	h.Name = strings.TrimSpace(h.Name)
+}
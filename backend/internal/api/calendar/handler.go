@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Largest .ics file an import accepts
+const maxImportBytes = 1 << 20
+
+type ICalendarService interface {
+	CreateCalendar(cal *HolidayCalendar) error
+	GetCalendarByID(id int) (*HolidayCalendar, error)
+	GetAllCalendars(pagination utils.PaginationQuery) ([]HolidayCalendar, int64, error)
+	UpdateCalendar(cal *HolidayCalendar) error
+	DeleteCalendar(id int) error
+	AddHoliday(calendarID int, holiday *Holiday) error
+	DeleteHoliday(calendarID, holidayID int) error
+	ImportICS(calendarID int, r io.Reader) (*ImportResult, error)
+}
+
+type CalendarHandler struct {
+	iservice ICalendarService
+}
+
+func NewCalendarHandler(iservice ICalendarService) *CalendarHandler {
+	return &CalendarHandler{iservice: iservice}
+}
+
+func (h *CalendarHandler) CreateCalendar(c *gin.Context) {
+	var cal HolidayCalendar
+	if err := c.ShouldBindJSON(&cal); err != nil {
+		utils.BindingError(c, err, "invalid holiday calendar")
+		return
+	}
+	cal.Sanitize()
+	if err := h.iservice.CreateCalendar(&cal); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, cal)
+}
+
+func (h *CalendarHandler) ListCalendars(c *gin.Context) {
+	pagination := utils.GetPaginationParams(c)
+	calendars, total, err := h.iservice.GetAllCalendars(pagination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.GetPaginatedResponse(calendars, pagination.Page, pagination.Limit, total))
+}
+
+// GetCalendar returns a calendar with all of its holidays.
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid calendar ID")
+		return
+	}
+	cal, err := h.iservice.GetCalendarByID(id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cal)
+}
+
+// UpdateCalendar changes a calendar's name, description, locations and default flag; its holidays
+// are managed through their own endpoints.
+func (h *CalendarHandler) UpdateCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid calendar ID")
+		return
+	}
+	var cal HolidayCalendar
+	if err := c.ShouldBindJSON(&cal); err != nil {
+		utils.BindingError(c, err, "invalid holiday calendar")
+		return
+	}
+	cal.Sanitize()
+	cal.ID = id
+	if err := h.iservice.UpdateCalendar(&cal); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cal)
+}
+
+func (h *CalendarHandler) DeleteCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid calendar ID")
+		return
+	}
+	if err := h.iservice.DeleteCalendar(id); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "holiday calendar deleted successfully"})
+}
+
+func (h *CalendarHandler) AddHoliday(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid calendar ID")
+		return
+	}
+	var holiday Holiday
+	if err := c.ShouldBindJSON(&holiday); err != nil {
+		utils.BindingError(c, err, "invalid holiday")
+		return
+	}
+	holiday.Sanitize()
+	if err := h.iservice.AddHoliday(id, &holiday); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, holiday)
+}
+
+func (h *CalendarHandler) DeleteHoliday(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid calendar ID")
+		return
+	}
+	holidayID, err := strconv.Atoi(c.Param("holiday_id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid holiday ID")
+		return
+	}
+	if err := h.iservice.DeleteHoliday(id, holidayID); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "holiday deleted successfully"})
+}
+
+// ImportICS adds the events of an iCalendar file to a calendar. The file is either the request body
+// (Content-Type: text/calendar) or the "file" field of a multipart form.
+func (h *CalendarHandler) ImportICS(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid calendar ID")
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "a .ics file is required in the 'file' field")
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "could not read the uploaded file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.iservice.ImportICS(id, body)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,166 @@
+package calendar
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Longest event an import expands into single days, so a stray multi-year event can't flood the calendar
+const maxEventDays = 31
+
+// ParseICS reads the VEVENTs of an iCalendar (.ics) file as holidays, one per day an event covers.
+// All-day events end the day before their DTEND; timed events count on the IST days they touch.
+// Recurrence rules are not expanded, so each occurrence must be listed as its own event, as the
+// published public holiday feeds do. Events that can't be read are returned as skipped.
+func ParseICS(r io.Reader) ([]Holiday, []string, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var holidays []Holiday
+	var skipped []string
+	var event map[string]string
+	nested := 0 // Components inside the event, such as VALARM, whose properties aren't the event's
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = map[string]string{}
+			nested = 0
+		case event != nil && strings.HasPrefix(line, "BEGIN:"):
+			nested++
+		case event != nil && nested > 0:
+			if strings.HasPrefix(line, "END:") {
+				nested--
+			}
+		case line == "END:VEVENT":
+			if event == nil {
+				continue
+			}
+			days, err := eventDays(event)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s: %v", eventLabel(event), err))
+			}
+			holidays = append(holidays, days...)
+			event = nil
+		case event != nil:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			// Parameters such as ;VALUE=DATE or ;TZID= come before the colon
+			name, params, _ := strings.Cut(name, ";")
+			name = strings.ToUpper(name)
+			event[name] = value
+			if params != "" {
+				event[name+";"] = params
+			}
+		}
+	}
+	if holidays == nil && skipped == nil {
+		return nil, nil, fmt.Errorf("%w: no events found in the calendar file", utils.ErrInvalidInput)
+	}
+	return holidays, skipped, nil
+}
+
+// unfoldICS splits the file into content lines, joining the continuation lines (starting with a
+// space or tab) that long lines are folded into.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: could not read the calendar file: %v", utils.ErrInvalidInput, err)
+	}
+	return lines, nil
+}
+
+// eventDays turns one event into a holiday per day it covers.
+func eventDays(event map[string]string) ([]Holiday, error) {
+	name := unescapeICS(event["SUMMARY"])
+	if name == "" {
+		return nil, fmt.Errorf("missing SUMMARY")
+	}
+	start, allDay, err := parseICSTime(event["DTSTART"], event["DTSTART;"])
+	if err != nil {
+		return nil, fmt.Errorf("DTSTART: %v", err)
+	}
+
+	last := start
+	if value, ok := event["DTEND"]; ok {
+		end, _, err := parseICSTime(value, event["DTEND;"])
+		if err != nil {
+			return nil, fmt.Errorf("DTEND: %v", err)
+		}
+		// An all-day event's end date is exclusive, a timed one ends within its last day
+		if allDay {
+			end = end.AddDate(0, 0, -1)
+		} else {
+			end = end.Add(-time.Nanosecond)
+		}
+		if end.After(last) {
+			last = end
+		}
+	}
+
+	// Days are counted on the wall clock of the start
+	last = last.In(start.Location())
+	var days []Holiday
+	for day := start; day.Format("2006-01-02") <= last.Format("2006-01-02"); day = day.AddDate(0, 0, 1) {
+		if len(days) == maxEventDays {
+			return nil, fmt.Errorf("events can span at most %d days", maxEventDays)
+		}
+		days = append(days, Holiday{Date: day.Format("2006-01-02"), Name: name})
+	}
+	return days, nil
+}
+
+// parseICSTime reads a DATE (20261225) or DATE-TIME (20261225T090000, with a Z for UTC) value. Dates
+// and floating times are taken as IST, as is a TZID that isn't known here; UTC times are moved to IST.
+func parseICSTime(value, params string) (time.Time, bool, error) {
+	loc := utils.IST()
+	if _, tzid, ok := strings.Cut(params, "TZID="); ok {
+		tzid, _, _ = strings.Cut(tzid, ";")
+		if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+			loc = l
+		}
+	}
+	value = strings.TrimSpace(value)
+	if len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t.In(utils.IST()), false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+func unescapeICS(value string) string {
+	return strings.TrimSpace(strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value))
+}
+
+func eventLabel(event map[string]string) string {
+	if name := unescapeICS(event["SUMMARY"]); name != "" {
+		return name
+	}
+	if uid := event["UID"]; uid != "" {
+		return uid
+	}
+	return "event"
+}
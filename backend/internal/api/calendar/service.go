@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"io"
+	"time"
+)
+
+type CalendarRepository interface {
+	CreateCalendar(cal *HolidayCalendar) error
+	GetCalendarByID(id int) (*HolidayCalendar, error)
+	GetAllCalendars(pagination utils.PaginationQuery) ([]HolidayCalendar, int64, error)
+	GetCalendars() ([]HolidayCalendar, error)
+	UpdateCalendar(cal *HolidayCalendar) error
+	DeleteCalendar(id int) error
+
+	AddHoliday(holiday *Holiday) error
+	DeleteHoliday(calendarID, holidayID int) error
+	UpsertHolidays(calendarID int, holidays []Holiday) (added int, updated int, err error)
+
+	GetCalendarForLocation(location string) (*HolidayCalendar, error)
+	GetHolidays(calendarID int, fromDate, toDate string) ([]Holiday, error)
+}
+
+type CalendarService struct {
+	Repo CalendarRepository
+}
+
+func NewCalendarService(repo CalendarRepository) *CalendarService {
+	return &CalendarService{Repo: repo}
+}
+
+func (s *CalendarService) CreateCalendar(cal *HolidayCalendar) error {
+	if err := s.validateLocations(cal); err != nil {
+		return err
+	}
+	cal.Holidays = nil // Holidays are added on their own or imported
+	return s.Repo.CreateCalendar(cal)
+}
+
+func (s *CalendarService) GetCalendarByID(id int) (*HolidayCalendar, error) {
+	return s.Repo.GetCalendarByID(id)
+}
+
+func (s *CalendarService) GetAllCalendars(pagination utils.PaginationQuery) ([]HolidayCalendar, int64, error) {
+	return s.Repo.GetAllCalendars(pagination)
+}
+
+func (s *CalendarService) UpdateCalendar(cal *HolidayCalendar) error {
+	if _, err := s.Repo.GetCalendarByID(cal.ID); err != nil {
+		return err
+	}
+	if err := s.validateLocations(cal); err != nil {
+		return err
+	}
+	cal.Holidays = nil
+	return s.Repo.UpdateCalendar(cal)
+}
+
+func (s *CalendarService) DeleteCalendar(id int) error {
+	return s.Repo.DeleteCalendar(id)
+}
+
+func (s *CalendarService) AddHoliday(calendarID int, holiday *Holiday) error {
+	if _, err := s.Repo.GetCalendarByID(calendarID); err != nil {
+		return err
+	}
+	holiday.ID = 0
+	holiday.CalendarID = calendarID
+	return s.Repo.AddHoliday(holiday)
+}
+
+func (s *CalendarService) DeleteHoliday(calendarID, holidayID int) error {
+	return s.Repo.DeleteHoliday(calendarID, holidayID)
+}
+
+// ImportICS adds the events of an iCalendar file to the calendar as holidays. A date the calendar
+// already has takes the imported name, so re-importing an updated feed is safe.
+func (s *CalendarService) ImportICS(calendarID int, r io.Reader) (*ImportResult, error) {
+	if _, err := s.Repo.GetCalendarByID(calendarID); err != nil {
+		return nil, err
+	}
+	parsed, skipped, err := ParseICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// One holiday per date; the first event of the file names it
+	seen := make(map[string]bool)
+	var holidays []Holiday
+	for _, h := range parsed {
+		if seen[h.Date] {
+			continue
+		}
+		seen[h.Date] = true
+		h.CalendarID = calendarID
+		holidays = append(holidays, h)
+	}
+
+	result := &ImportResult{Skipped: skipped}
+	if result.Skipped == nil {
+		result.Skipped = []string{}
+	}
+	if len(holidays) > 0 {
+		result.Added, result.Updated, err = s.Repo.UpsertHolidays(calendarID, holidays)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// HolidaysFor returns the holidays between from and to (both days included, on the clocks of the
+// times given, i.e. the location's zone) of the calendar that applies to a location: the one
+// listing it, or else the default calendar. Without either there are none.
+func (s *CalendarService) HolidaysFor(location string, from, to time.Time) (utils.Holidays, error) {
+	cal, err := s.Repo.GetCalendarForLocation(location)
+	if err != nil || cal == nil {
+		return nil, err
+	}
+	found, err := s.Repo.GetHolidays(cal.ID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	holidays := make(utils.Holidays, len(found))
+	for _, h := range found {
+		holidays[h.Date] = h.Name
+	}
+	return holidays, nil
+}
+
+// validateLocations returns an ErrConflict when another calendar already covers one of the
+// calendar's locations: each location follows a single calendar.
+func (s *CalendarService) validateLocations(cal *HolidayCalendar) error {
+	if len(cal.Locations) == 0 {
+		return nil
+	}
+	calendars, err := s.Repo.GetCalendars()
+	if err != nil {
+		return err
+	}
+	for _, other := range calendars {
+		if other.ID == cal.ID {
+			continue
+		}
+		for _, taken := range other.Locations {
+			for _, location := range cal.Locations {
+				if taken == location {
+					return fmt.Errorf("%w: location '%s' already follows calendar '%s'", utils.ErrConflict, location, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
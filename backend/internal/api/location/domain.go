@@ -0,0 +1,74 @@
+package location
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"strings"
+	"time"
+)
+
+// Kinds of location, outermost first: a campus holds buildings, a building floors, a floor zones.
+const (
+	KindCampus   = "campus"
+	KindBuilding = "building"
+	KindFloor    = "floor"
+	KindZone     = "zone"
+)
+
+// kindDepth orders the kinds; a location's parent must be of a shallower kind.
+var kindDepth = map[string]int{KindCampus: 0, KindBuilding: 1, KindFloor: 2, KindZone: 3}
+
+// PathSeparator joins the names from the root down to a location into its path.
+const PathSeparator = " / "
+
+// Location is a node of the site tree resources are placed in, e.g. a floor of a building. Its time
+// zone and opening hours decide when its resources can be booked; left empty, they are inherited
+// from the nearest ancestor setting them (and at the root, the default schedule). Instants are
+// still stored in UTC.
+type Location struct {
+	ID           int               `json:"id" gorm:"primaryKey;autoIncrement"`
+	ParentID     *int              `json:"parent_id" gorm:"index"`
+	Kind         string            `json:"kind" binding:"required,oneof=campus building floor zone"`
+	Name         string            `json:"name" binding:"required"`
+	Path         string            `json:"path" gorm:"uniqueIndex"` // Names from the root down, e.g. "HQ / Building A / Floor 1"
+	Address      string            `json:"address"`
+	TimeZone     string            `json:"time_zone"`                                       // IANA name, e.g. "Europe/London"
+	OpeningHours utils.WeeklyHours `json:"opening_hours" gorm:"type:jsonb;serializer:json"` // Days left out are the weekend
+	Children     []Location        `json:"children,omitempty" gorm:"-"`                     // Only filled in the tree
+	CreatedAt    time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (l *Location) Sanitize() {
+	l.Kind = strings.ToLower(strings.TrimSpace(l.Kind))
+	l.Name = strings.TrimSpace(l.Name)
+	l.Address = strings.TrimSpace(l.Address)
+	l.TimeZone = strings.TrimSpace(l.TimeZone)
+	l.Children = nil
+	if len(l.OpeningHours) == 0 {
+		l.OpeningHours = nil // Inherited
+		return
+	}
+	hours := make(utils.WeeklyHours, len(l.OpeningHours))
+	for day, h := range l.OpeningHours {
+		hours[strings.ToLower(strings.TrimSpace(day))] = utils.DayHours{Open: strings.TrimSpace(h.Open), Close: strings.TrimSpace(h.Close)}
+	}
+	l.OpeningHours = hours
+}
+
+// scheduleOf returns the time zone and opening hours that apply at the first location of chain, given
+// the chain of it and its ancestors, nearest first. Holidays aren't included.
+func scheduleOf(chain []Location) utils.Schedule {
+	schedule := utils.DefaultSchedule()
+	zoneSet, hoursSet := false, false
+	for _, l := range chain {
+		if !zoneSet && l.TimeZone != "" {
+			schedule.Zone = utils.LoadZone(l.TimeZone)
+			zoneSet = true
+		}
+		if !hoursSet && len(l.OpeningHours) > 0 {
+			schedule.Hours = l.OpeningHours
+			hoursSet = true
+		}
+	}
+	return schedule
+}
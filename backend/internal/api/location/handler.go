@@ -0,0 +1,108 @@
+package location
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ILocationService interface {
+	CreateLocation(l *Location) error
+	GetLocationByID(id int) (*Location, error)
+	GetAllLocations(pagination utils.PaginationQuery) ([]Location, int64, error)
+	GetLocationTree() ([]Location, error)
+	UpdateLocation(l *Location) error
+	DeleteLocation(id int) error
+}
+
+type LocationHandler struct {
+	iservice ILocationService
+}
+
+func NewLocationHandler(iservice ILocationService) *LocationHandler {
+	return &LocationHandler{iservice: iservice}
+}
+
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var l Location
+	if err := c.ShouldBindJSON(&l); err != nil {
+		utils.BindingError(c, err, "invalid location")
+		return
+	}
+	l.Sanitize()
+	if err := h.iservice.CreateLocation(&l); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, l)
+}
+
+func (h *LocationHandler) ListLocations(c *gin.Context) {
+	pagination := utils.GetPaginationParams(c)
+	locations, total, err := h.iservice.GetAllLocations(pagination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.GetPaginatedResponse(locations, pagination.Page, pagination.Limit, total))
+}
+
+// GetLocationTree returns every location nested under its parent: campuses, their buildings, their
+// floors and their zones.
+func (h *LocationHandler) GetLocationTree(c *gin.Context) {
+	tree, err := h.iservice.GetLocationTree()
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *LocationHandler) GetLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid location ID")
+		return
+	}
+	l, err := h.iservice.GetLocationByID(id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, l)
+}
+
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid location ID")
+		return
+	}
+	var l Location
+	if err := c.ShouldBindJSON(&l); err != nil {
+		utils.BindingError(c, err, "invalid location")
+		return
+	}
+	l.Sanitize()
+	l.ID = id
+	if err := h.iservice.UpdateLocation(&l); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, l)
+}
+
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid location ID")
+		return
+	}
+	if err := h.iservice.DeleteLocation(id); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "location deleted successfully"})
+}
@@ -0,0 +1,207 @@
+package location
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type LocationRepository interface {
+	CreateLocation(l *Location) error
+	GetLocationByID(id int) (*Location, error)
+	GetLocationByPath(path string) (*Location, error)
+	GetAllLocations(pagination utils.PaginationQuery) ([]Location, int64, error)
+	GetLocations() ([]Location, error)
+	GetLocationChain(id int) ([]Location, error)
+	GetChildren(id int) ([]Location, error)
+	UpdateLocation(l *Location, oldPath string) error
+	DeleteLocation(id int) error
+
+	CountResourcesAt(id int) (int64, error)
+}
+
+type LocationService struct {
+	Repo LocationRepository
+}
+
+func NewLocationService(repo LocationRepository) *LocationService {
+	return &LocationService{Repo: repo}
+}
+
+func (s *LocationService) CreateLocation(l *Location) error {
+	if err := validateLocation(l); err != nil {
+		return err
+	}
+	if err := s.placeUnderParent(l); err != nil {
+		return err
+	}
+	return s.Repo.CreateLocation(l)
+}
+
+func (s *LocationService) GetLocationByID(id int) (*Location, error) {
+	return s.Repo.GetLocationByID(id)
+}
+
+func (s *LocationService) GetAllLocations(pagination utils.PaginationQuery) ([]Location, int64, error) {
+	return s.Repo.GetAllLocations(pagination)
+}
+
+// GetLocationTree returns every location, nested under its parent, roots first.
+func (s *LocationService) GetLocationTree() ([]Location, error) {
+	locations, err := s.Repo.GetLocations()
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int][]Location)
+	var roots []Location
+	for _, l := range locations {
+		if l.ParentID == nil {
+			roots = append(roots, l)
+			continue
+		}
+		children[*l.ParentID] = append(children[*l.ParentID], l)
+	}
+	var attach func(nodes []Location) []Location
+	attach = func(nodes []Location) []Location {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	if roots == nil {
+		return []Location{}, nil
+	}
+	return attach(roots), nil
+}
+
+// UpdateLocation saves the location. Renaming or moving it changes its path and those of everything
+// below it; its resources and holiday calendars follow.
+func (s *LocationService) UpdateLocation(l *Location) error {
+	existing, err := s.Repo.GetLocationByID(l.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateLocation(l); err != nil {
+		return err
+	}
+	if err := s.placeUnderParent(l); err != nil {
+		return err
+	}
+	children, err := s.Repo.GetChildren(l.ID)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if kindDepth[child.Kind] <= kindDepth[l.Kind] {
+			return fmt.Errorf("%w: a %s can't hold the %s '%s'", utils.ErrInvalidInput, l.Kind, child.Kind, child.Name)
+		}
+	}
+	l.CreatedAt = existing.CreatedAt
+	return s.Repo.UpdateLocation(l, existing.Path)
+}
+
+func (s *LocationService) DeleteLocation(id int) error {
+	if _, err := s.Repo.GetLocationByID(id); err != nil {
+		return err
+	}
+	children, err := s.Repo.GetChildren(id)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("%w: cannot delete location, move or delete the %d locations inside it first", utils.ErrConflict, len(children))
+	}
+	count, err := s.Repo.CountResourcesAt(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: cannot delete location, %d resources are still there", utils.ErrConflict, count)
+	}
+	return s.Repo.DeleteLocation(id)
+}
+
+// ScheduleAt returns the time zone and opening hours at a location, each inherited from the
+// nearest ancestor setting it. Resources without a location, or whose location is gone, follow
+// the default schedule.
+func (s *LocationService) ScheduleAt(locationID *int) (utils.Schedule, error) {
+	if locationID == nil {
+		return utils.DefaultSchedule(), nil
+	}
+	chain, err := s.Repo.GetLocationChain(*locationID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return utils.DefaultSchedule(), nil
+		}
+		return utils.Schedule{}, err
+	}
+	return scheduleOf(chain), nil
+}
+
+// ResolveLocation finds the location a resource is placed at, by its ID or else by its path, and
+// returns both. Neither given means no location.
+func (s *LocationService) ResolveLocation(id *int, path string) (*int, string, error) {
+	var l *Location
+	var err error
+	switch {
+	case id != nil:
+		l, err = s.Repo.GetLocationByID(*id)
+	case path != "":
+		l, err = s.Repo.GetLocationByPath(path)
+	default:
+		return nil, "", nil
+	}
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, "", fmt.Errorf("%w: unknown location, create it first", utils.ErrInvalidInput)
+		}
+		return nil, "", err
+	}
+	return &l.ID, l.Path, nil
+}
+
+// placeUnderParent checks that the parent exists, is of an outer kind and isn't the location itself
+// or below it, and sets the location's path.
+func (s *LocationService) placeUnderParent(l *Location) error {
+	if l.ParentID == nil {
+		l.Path = l.Name
+		return nil
+	}
+	chain, err := s.Repo.GetLocationChain(*l.ParentID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("%w: parent location not found", utils.ErrInvalidInput)
+		}
+		return err
+	}
+	for _, ancestor := range chain {
+		if ancestor.ID == l.ID {
+			return fmt.Errorf("%w: a location can't be moved inside itself", utils.ErrInvalidInput)
+		}
+	}
+	parent := chain[0]
+	if kindDepth[parent.Kind] >= kindDepth[l.Kind] {
+		return fmt.Errorf("%w: a %s can't be inside a %s", utils.ErrInvalidInput, l.Kind, parent.Kind)
+	}
+	l.Path = parent.Path + PathSeparator + l.Name
+	return nil
+}
+
+// validateLocation checks the name, kind, time zone and opening hours; an empty time zone or set of
+// opening hours is inherited.
+func validateLocation(l *Location) error {
+	if strings.Contains(l.Name, "/") {
+		return fmt.Errorf("%w: location names can't contain '/'", utils.ErrInvalidInput)
+	}
+	if _, ok := kindDepth[l.Kind]; !ok {
+		return fmt.Errorf("%w: kind must be one of campus, building, floor, zone", utils.ErrInvalidInput)
+	}
+	if l.TimeZone != "" {
+		if _, err := time.LoadLocation(l.TimeZone); err != nil || l.TimeZone == "Local" {
+			return fmt.Errorf("%w: '%s' is not an IANA time zone (e.g. Europe/London)", utils.ErrInvalidInput, l.TimeZone)
+		}
+	}
+	return l.OpeningHours.Validate()
+}
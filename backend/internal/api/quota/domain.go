@@ -0,0 +1,80 @@
+package quota
+
+import (
+	"ResourceAllocator/internal/api/user"
+	"strings"
+	"time"
+)
+
+// QuotaRule limits how much each matching user can book. A rule matches users by role and/or
+// group (empty matches everyone) and counts bookings of one resource type or of all types.
+// A limit of 0 means that dimension is not limited.
+type QuotaRule struct {
+	ID                int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name              string    `json:"name" binding:"required"`
+	Role              user.Role `json:"role,omitempty" binding:"omitempty,oneof=ADMIN EMPLOYEE"`
+	Group             string    `json:"group,omitempty"`
+	ResourceTypeID    *int      `json:"resource_type_id,omitempty"`
+	MaxActiveBookings int       `json:"max_active_bookings" binding:"min=0"` // Pending / approved bookings that haven't ended yet
+	MaxHoursPerWeek   int       `json:"max_hours_per_week" binding:"min=0"`  // Monday to Sunday
+	MaxBookingsPerDay int       `json:"max_bookings_per_day" binding:"min=0"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type LimitKind string
+
+const (
+	LimitActiveBookings LimitKind = "active_bookings"
+	LimitHoursPerWeek   LimitKind = "hours_per_week"
+	LimitBookingsPerDay LimitKind = "bookings_per_day"
+)
+
+// Usage is how much of one limit of a rule a user has used in the current period.
+type Usage struct {
+	RuleID   int       `json:"rule_id"`
+	RuleName string    `json:"rule_name"`
+	Limit    LimitKind `json:"limit"`
+	Max      int       `json:"max"`
+	Used     float64   `json:"used"`
+}
+
+type UserUsage struct {
+	UserUUID string  `json:"user_uuid"`
+	Role     string  `json:"role"`
+	Group    string  `json:"group"`
+	Usage    []Usage `json:"usage"`
+}
+
+// BookingFilter selects the bookings a rule counts for a user.
+type BookingFilter struct {
+	UserID         string
+	ResourceTypeID *int
+	ExcludeIDs     []int // Stored bookings being approved or replaced, so they aren't counted twice
+}
+
+// Planned is a booking decided in the same request as the one being checked but not stored yet,
+// such as an earlier occurrence of a new series or another member of a new bundle.
+type Planned struct {
+	ResourceTypeID int
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+func (q *QuotaRule) Sanitize() {
+	q.Name = strings.TrimSpace(q.Name)
+	q.Group = strings.TrimSpace(q.Group)
+}
+
+// AppliesTo reports whether the rule targets the user.
+func (q *QuotaRule) AppliesTo(u *user.User) bool {
+	if q.Role != "" && q.Role != u.Role {
+		return false
+	}
+	return q.Group == "" || strings.EqualFold(q.Group, u.Group)
+}
+
+// Counts reports whether bookings of the resource type count towards the rule.
+func (q *QuotaRule) Counts(resourceTypeID int) bool {
+	return q.ResourceTypeID == nil || *q.ResourceTypeID == resourceTypeID
+}
@@ -0,0 +1,107 @@
+package quota
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IQuotaService interface {
+	CreateRule(rule *QuotaRule) error
+	GetRuleByID(id int) (*QuotaRule, error)
+	GetAllRules(pagination utils.PaginationQuery) ([]QuotaRule, int64, error)
+	UpdateRule(rule *QuotaRule) error
+	DeleteRule(id int) error
+	GetUserUsage(userID string) (*UserUsage, error)
+}
+
+type QuotaHandler struct {
+	iservice IQuotaService
+}
+
+func NewQuotaHandler(iservice IQuotaService) *QuotaHandler {
+	return &QuotaHandler{iservice: iservice}
+}
+
+func (h *QuotaHandler) CreateRule(c *gin.Context) {
+	var rule QuotaRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		utils.BindingError(c, err, "invalid quota rule")
+		return
+	}
+	rule.Sanitize()
+	if err := h.iservice.CreateRule(&rule); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *QuotaHandler) ListRules(c *gin.Context) {
+	pagination := utils.GetPaginationParams(c)
+	rules, total, err := h.iservice.GetAllRules(pagination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.GetPaginatedResponse(rules, pagination.Page, pagination.Limit, total))
+}
+
+func (h *QuotaHandler) GetRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid quota rule ID")
+		return
+	}
+	rule, err := h.iservice.GetRuleByID(id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *QuotaHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid quota rule ID")
+		return
+	}
+	var rule QuotaRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		utils.BindingError(c, err, "invalid quota rule")
+		return
+	}
+	rule.Sanitize()
+	rule.ID = id
+	if err := h.iservice.UpdateRule(&rule); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *QuotaHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid quota rule ID")
+		return
+	}
+	if err := h.iservice.DeleteRule(id); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "quota rule deleted successfully"})
+}
+
+// GetUserUsage shows an admin how much of each applicable quota a user has used.
+func (h *QuotaHandler) GetUserUsage(c *gin.Context) {
+	usage, err := h.iservice.GetUserUsage(c.Param("uuid"))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
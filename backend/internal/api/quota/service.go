@@ -0,0 +1,216 @@
+package quota
+
+import (
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"time"
+)
+
+type QuotaRepository interface {
+	CreateRule(rule *QuotaRule) error
+	GetRuleByID(id int) (*QuotaRule, error)
+	GetAllRules(pagination utils.PaginationQuery) ([]QuotaRule, int64, error)
+	GetRules() ([]QuotaRule, error)
+	UpdateRule(rule *QuotaRule) error
+	DeleteRule(id int) error
+
+	GetUserByUUID(uuid string) (*user.User, error)
+	CountActiveBookings(filter BookingFilter, now time.Time) (int64, error)
+	SumBookedHours(filter BookingFilter, from, to time.Time) (float64, error)
+	CountBookingsStarting(filter BookingFilter, from, to time.Time) (int64, error)
+}
+
+type QuotaService struct {
+	Repo QuotaRepository
+}
+
+func NewQuotaService(repo QuotaRepository) *QuotaService {
+	return &QuotaService{Repo: repo}
+}
+
+func (s *QuotaService) CreateRule(rule *QuotaRule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	return s.Repo.CreateRule(rule)
+}
+
+func (s *QuotaService) GetRuleByID(id int) (*QuotaRule, error) {
+	return s.Repo.GetRuleByID(id)
+}
+
+func (s *QuotaService) GetAllRules(pagination utils.PaginationQuery) ([]QuotaRule, int64, error) {
+	return s.Repo.GetAllRules(pagination)
+}
+
+func (s *QuotaService) UpdateRule(rule *QuotaRule) error {
+	if _, err := s.Repo.GetRuleByID(rule.ID); err != nil {
+		return err
+	}
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	return s.Repo.UpdateRule(rule)
+}
+
+func (s *QuotaService) DeleteRule(id int) error {
+	return s.Repo.DeleteRule(id)
+}
+
+// GetUserUsage reports the user's usage against every rule that applies to them, for the
+// current week and day.
+func (s *QuotaService) GetUserUsage(userID string) (*UserUsage, error) {
+	u, err := s.Repo.GetUserByUUID(userID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.Repo.GetRules()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserUsage{UserUUID: u.UUID, Role: string(u.Role), Group: u.Group, Usage: []Usage{}}
+	now := time.Now()
+	for i := range rules {
+		if !rules[i].AppliesTo(u) {
+			continue
+		}
+		usage, err := s.usage(&rules[i], BookingFilter{UserID: userID, ResourceTypeID: rules[i].ResourceTypeID}, nil, now, now)
+		if err != nil {
+			return nil, err
+		}
+		result.Usage = append(result.Usage, usage...)
+	}
+	return result, nil
+}
+
+// CheckBooking returns an ErrConflict when a booking of [start, end) on a resource of the given type
+// would take the user over any rule that applies to them. excludeBookingID is a pending booking that
+// is being approved: it is already stored, so it is left out of the usage and counted as the new one.
+func (s *QuotaService) CheckBooking(userID string, resourceTypeID int, start, end time.Time, excludeBookingID int) error {
+	var exclude []int
+	if excludeBookingID != 0 {
+		exclude = []int{excludeBookingID}
+	}
+	return s.CheckBookings(userID, resourceTypeID, start, end, nil, exclude)
+}
+
+// CheckBookings is CheckBooking for a booking decided together with others: planned are counted as
+// if they were stored, and the stored bookings in excludeIDs (requests being approved or replaced)
+// are left out.
+func (s *QuotaService) CheckBookings(userID string, resourceTypeID int, start, end time.Time, planned []Planned, excludeIDs []int) error {
+	u, err := s.Repo.GetUserByUUID(userID)
+	if err != nil {
+		return err
+	}
+	rules, err := s.Repo.GetRules()
+	if err != nil {
+		return err
+	}
+
+	hours := end.Sub(start).Hours()
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.AppliesTo(u) || !rule.Counts(resourceTypeID) {
+			continue
+		}
+		filter := BookingFilter{UserID: userID, ResourceTypeID: rule.ResourceTypeID, ExcludeIDs: excludeIDs}
+		usage, err := s.usage(rule, filter, planned, start, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, limit := range usage {
+			requested := 1.0
+			if limit.Limit == LimitHoursPerWeek {
+				requested = hours
+			}
+			if limit.Used+requested > float64(limit.Max) {
+				return fmt.Errorf("%w: quota '%s' exceeded: %s", utils.ErrConflict, rule.Name, describeUsage(limit))
+			}
+		}
+	}
+	return nil
+}
+
+// usage measures each limit the rule sets, counting the planned bookings along with the stored ones;
+// the week and day are the ones containing at.
+func (s *QuotaService) usage(rule *QuotaRule, filter BookingFilter, planned []Planned, at, now time.Time) ([]Usage, error) {
+	var result []Usage
+	add := func(kind LimitKind, max int, used float64) {
+		result = append(result, Usage{RuleID: rule.ID, RuleName: rule.Name, Limit: kind, Max: max, Used: used})
+	}
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	var counted []Planned
+	for _, p := range planned {
+		if rule.Counts(p.ResourceTypeID) {
+			counted = append(counted, p)
+		}
+	}
+
+	if rule.MaxActiveBookings > 0 {
+		count, err := s.Repo.CountActiveBookings(filter, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range counted {
+			if p.EndTime.After(now) {
+				count++
+			}
+		}
+		add(LimitActiveBookings, rule.MaxActiveBookings, float64(count))
+	}
+	if rule.MaxHoursPerWeek > 0 {
+		// Weeks run Monday to Sunday
+		weekStart := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		weekEnd := weekStart.AddDate(0, 0, 7)
+		hours, err := s.Repo.SumBookedHours(filter, weekStart, weekEnd)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range counted {
+			from, to := p.StartTime, p.EndTime
+			if from.Before(weekStart) {
+				from = weekStart
+			}
+			if to.After(weekEnd) {
+				to = weekEnd
+			}
+			if to.After(from) {
+				hours += to.Sub(from).Hours()
+			}
+		}
+		add(LimitHoursPerWeek, rule.MaxHoursPerWeek, hours)
+	}
+	if rule.MaxBookingsPerDay > 0 {
+		count, err := s.Repo.CountBookingsStarting(filter, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range counted {
+			if !p.StartTime.Before(day) && p.StartTime.Before(day.AddDate(0, 0, 1)) {
+				count++
+			}
+		}
+		add(LimitBookingsPerDay, rule.MaxBookingsPerDay, float64(count))
+	}
+	return result, nil
+}
+
+func describeUsage(u Usage) string {
+	switch u.Limit {
+	case LimitHoursPerWeek:
+		return fmt.Sprintf("%g of %d hours this week already booked", u.Used, u.Max)
+	case LimitBookingsPerDay:
+		return fmt.Sprintf("%d of %d bookings that day already made", int(u.Used), u.Max)
+	default:
+		return fmt.Sprintf("%d of %d active bookings already held", int(u.Used), u.Max)
+	}
+}
+
+func validateRule(rule *QuotaRule) error {
+	if rule.MaxActiveBookings == 0 && rule.MaxHoursPerWeek == 0 && rule.MaxBookingsPerDay == 0 {
+		return fmt.Errorf("%w: a quota rule needs at least one limit", utils.ErrInvalidInput)
+	}
+	return nil
+}
@@ -1,6 +1,12 @@
 package resource
 
 import (
+	"ResourceAllocator/internal/api/utils"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -9,34 +15,337 @@ type Resource struct {
 	ID               int                    `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name             string                 `json:"name" binding:"required"`
 	TypeID           int                    `json:"type_id" binding:"required"`
-	Location         string                 `json:"location"`
+	LocationID       *int                   `json:"location_id" gorm:"index"`
+	Location         string                 `json:"location"` // Path of the location, e.g. "HQ / Building A / Floor 1"
 	Description      string                 `json:"description" binding:"required"`
 	IsActive         bool                   `json:"is_active" gorm:"default:true"`
 	RequiresApproval bool                   `json:"requires_approval" gorm:"default:false"`
+	Quantity         int                    `json:"quantity" gorm:"default:1" binding:"omitempty,min=1"` // Identical units in the pool (1 for an ordinary resource)
 	Properties       map[string]interface{} `json:"properties" gorm:"type:jsonb;serializer:json"`
-	CreatedAt        time.Time              `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt        time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+	// Per-resource overrides of the type's slot rules (null inherits from the type)
+	SlotMinutes        *int `json:"slot_minutes,omitempty" binding:"omitempty,min=1,max=1440"`
+	MinDurationMinutes *int `json:"min_duration_minutes,omitempty" binding:"omitempty,min=0"`
+	MaxDurationMinutes *int `json:"max_duration_minutes,omitempty" binding:"omitempty,min=0"`
+	// Per-resource overrides of the type's turnover buffers (null inherits from the type)
+	BufferBeforeMinutes *int          `json:"buffer_before_minutes,omitempty" binding:"omitempty,min=0"`
+	BufferAfterMinutes  *int          `json:"buffer_after_minutes,omitempty" binding:"omitempty,min=0"`
+	Type                *ResourceType `json:"-" gorm:"foreignKey:TypeID"`
+	CreatedAt           time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 type ResourceType struct {
-	ID               int               `json:"id" gorm:"primaryKey;autoIncrement"`
-	Type             string            `json:"type" binding:"required" gorm:"unique"`
-	SchemaDefinition map[string]string `json:"schema_definition" gorm:"type:jsonb;serializer:json"`
+	ID               int                       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type             string                    `json:"type" binding:"required" gorm:"unique"`
+	SchemaDefinition map[string]PropertySchema `json:"schema_definition" gorm:"type:jsonb;serializer:json"`
+	// Bookings start on and last whole multiples of SlotMinutes, counted from midnight
+	SlotMinutes        int `json:"slot_minutes" gorm:"default:60" binding:"omitempty,min=1,max=1440"`
+	MinDurationMinutes int `json:"min_duration_minutes" binding:"min=0"`
+	MaxDurationMinutes int `json:"max_duration_minutes" binding:"min=0"` // 0 means no upper limit
+	// Setup / cleaning time kept free before and after every booking
+	BufferBeforeMinutes int `json:"buffer_before_minutes" binding:"min=0"`
+	BufferAfterMinutes  int `json:"buffer_after_minutes" binding:"min=0"`
+	BookingWindow
+	// Replaces BookingWindow for the roles listed, e.g. {"ADMIN": {"max_horizon_days": 365}}
+	RoleBookingWindows map[string]BookingWindow `json:"role_booking_windows,omitempty" gorm:"type:jsonb;serializer:json"`
+}
+
+// PropertyType is the kind of value a resource property holds.
+type PropertyType string
+
+const (
+	PropertyString  PropertyType = "string"
+	PropertyInteger PropertyType = "integer"
+	PropertyNumber  PropertyType = "number"
+	PropertyBoolean PropertyType = "boolean"
+	PropertyEnum    PropertyType = "enum"
+	PropertyDate    PropertyType = "date" // "2006-01-02"
+)
+
+// PropertySchema describes one property of the resources of a type. Values are stored typed, so
+// integers and numbers are JSON numbers and booleans JSON booleans.
+type PropertySchema struct {
+	Type     PropertyType `json:"type"`
+	Required bool         `json:"required"`
+	Default  interface{}  `json:"default,omitempty"` // Filled in when an optional property is left out
+	Min      *float64     `json:"min,omitempty"`     // Least value of a number, shortest length of a string
+	Max      *float64     `json:"max,omitempty"`     // Greatest value of a number, longest length of a string
+	Pattern  string       `json:"pattern,omitempty"` // Regular expression a string must match
+	Values   []string     `json:"values,omitempty"`  // What an enum can be
+}
+
+// UnmarshalJSON also reads the schemas of older types, which were just the name of the type
+// ("int", "integer", "number", ...) and required.
+func (p *PropertySchema) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = PropertySchema{Type: normalizeType(name), Required: true}
+		return nil
+	}
+	type plain PropertySchema
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	p.Type = normalizeType(string(p.Type))
+	return nil
+}
+
+// typeAliases are the other names types used to go by.
+var typeAliases = map[string]PropertyType{
+	"text": PropertyString, "str": PropertyString,
+	"int":   PropertyInteger,
+	"float": PropertyNumber, "double": PropertyNumber, "decimal": PropertyNumber,
+	"bool": PropertyBoolean,
+}
+
+func normalizeType(name string) PropertyType {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if t, ok := typeAliases[name]; ok {
+		return t
+	}
+	return PropertyType(name)
+}
+
+// BookingWindow is how close to and how far ahead of its start a booking can be made.
+type BookingWindow struct {
+	MinLeadMinutes int `json:"min_lead_minutes" binding:"min=0"`
+	MaxHorizonDays int `json:"max_horizon_days" binding:"min=0"` // 0 means no limit
+}
+
+// BookingWindowFor returns the booking window that applies to users of the given role.
+func (rt *ResourceType) BookingWindowFor(role string) BookingWindow {
+	if w, ok := rt.RoleBookingWindows[role]; ok {
+		return w
+	}
+	return rt.BookingWindow
+}
+
+// Check returns an ErrInvalidInput saying when booking a slot starting at start is possible, if it
+// isn't at now.
+func (w BookingWindow) Check(start, now time.Time) error {
+	if w.MinLeadMinutes > 0 {
+		closes := start.Add(-time.Duration(w.MinLeadMinutes) * time.Minute)
+		if now.After(closes) {
+			return fmt.Errorf("%w: this slot had to be booked at least %d minutes ahead, the booking window closed at %s",
+				utils.ErrInvalidInput, w.MinLeadMinutes, closes.Format("Mon, 02 Jan 2006 15:04"))
+		}
+	}
+	if w.MaxHorizonDays > 0 {
+		opens := start.AddDate(0, 0, -w.MaxHorizonDays)
+		if now.Before(opens) {
+			return fmt.Errorf("%w: this slot can only be booked up to %d days ahead, the booking window opens at %s",
+				utils.ErrInvalidInput, w.MaxHorizonDays, opens.Format("Mon, 02 Jan 2006 15:04"))
+		}
+	}
+	return nil
+}
+
+// DefaultSlotMinutes is the granularity of types that don't set one: whole hours.
+const DefaultSlotMinutes = 60
+
+// SlotRules are the effective time constraints for booking a resource.
+type SlotRules struct {
+	Granularity time.Duration
+	MinDuration time.Duration
+	MaxDuration time.Duration // 0 means no upper limit
+}
+
+// SlotRules returns the rules of the resource's type with the resource's own overrides applied.
+// The type must be loaded for its settings to count; without it the defaults are used.
+func (r *Resource) SlotRules() SlotRules {
+	slot, minDur, maxDur := DefaultSlotMinutes, 0, 0
+	if r.Type != nil {
+		if r.Type.SlotMinutes > 0 {
+			slot = r.Type.SlotMinutes
+		}
+		minDur, maxDur = r.Type.MinDurationMinutes, r.Type.MaxDurationMinutes
+	}
+	if r.SlotMinutes != nil && *r.SlotMinutes > 0 {
+		slot = *r.SlotMinutes
+	}
+	if r.MinDurationMinutes != nil {
+		minDur = *r.MinDurationMinutes
+	}
+	if r.MaxDurationMinutes != nil {
+		maxDur = *r.MaxDurationMinutes
+	}
+	return SlotRules{
+		Granularity: time.Duration(slot) * time.Minute,
+		MinDuration: time.Duration(minDur) * time.Minute,
+		MaxDuration: time.Duration(maxDur) * time.Minute,
+	}
+}
+
+// BookingWindowFor returns the booking window of the resource's type for the role; without a loaded
+// type there is no restriction.
+func (r *Resource) BookingWindowFor(role string) BookingWindow {
+	if r.Type == nil {
+		return BookingWindow{}
+	}
+	return r.Type.BookingWindowFor(role)
+}
+
+// Buffers is the turnover time a resource needs around each booking.
+type Buffers struct {
+	Before time.Duration
+	After  time.Duration
+}
+
+// Turnover is the free time needed between the end of one booking and the start of the next.
+func (b Buffers) Turnover() time.Duration {
+	return b.Before + b.After
+}
+
+// Buffers returns the type's buffers with the resource's own overrides applied. Without a loaded
+// type only the overrides count.
+func (r *Resource) Buffers() Buffers {
+	before, after := 0, 0
+	if r.Type != nil {
+		before, after = r.Type.BufferBeforeMinutes, r.Type.BufferAfterMinutes
+	}
+	if r.BufferBeforeMinutes != nil {
+		before = *r.BufferBeforeMinutes
+	}
+	if r.BufferAfterMinutes != nil {
+		after = *r.BufferAfterMinutes
+	}
+	return Buffers{Before: time.Duration(before) * time.Minute, After: time.Duration(after) * time.Minute}
+}
+
+// CapacityProperty is the property holding how many people a resource holds. It only limits bookings
+// on types whose schema declares it.
+const CapacityProperty = "capacity"
+
+// Capacity returns how many people the resource holds, if its type declares a capacity and the
+// resource sets a whole number for it. The type must be loaded.
+func (r *Resource) Capacity() (int, bool) {
+	if r.Type == nil {
+		return 0, false
+	}
+	if _, declared := r.Type.SchemaDefinition[CapacityProperty]; !declared {
+		return 0, false
+	}
+	switch v := r.Properties[CapacityProperty].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// Validate checks that a booking of the minimum duration is possible and fits under the maximum.
+func (sr SlotRules) Validate() error {
+	if sr.MaxDuration > 0 && sr.MaxDuration < sr.MinDuration {
+		return fmt.Errorf("%w: max_duration_minutes must not be less than min_duration_minutes", utils.ErrInvalidInput)
+	}
+	if sr.MaxDuration > 0 && sr.MaxDuration < sr.Granularity {
+		return fmt.Errorf("%w: max_duration_minutes must allow at least one slot", utils.ErrInvalidInput)
+	}
+	return nil
 }
 
 type ResourceSummary struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	TypeID   int    `json:"type_id"`
-	Location string `json:"location"`
-	IsActive bool   `json:"is_active"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	TypeID     int    `json:"type_id"`
+	LocationID *int   `json:"location_id"`
+	Location   string `json:"location"`
+	IsActive   bool   `json:"is_active"`
+	Quantity   int    `json:"quantity"`
+	// Units still free in the requested window (only set when filtering by start_time/end_time)
+	Available *int `json:"available,omitempty"`
+}
+
+// ResourceFilter is what resources are listed by: type, location (by ID or path, anything inside it
+// included), properties (prop_<key>=value or prop_<key>[op]=value, needs the type) and, when both
+// times are set, a window they must be free for.
+type ResourceFilter struct {
+	TypeID     *int
+	LocationID *int
+	Location   string
+	Props      PropertyQuery
+	StartTime  *string
+	EndTime    *string
+	Role       string // Caller's role, whose booking window the temporal filter applies
+}
+
+// PropertyOperator is how a property filter compares the property with its value.
+type PropertyOperator string
+
+const (
+	OpEq       PropertyOperator = "eq"
+	OpGt       PropertyOperator = "gt"
+	OpGte      PropertyOperator = "gte"
+	OpLt       PropertyOperator = "lt"
+	OpLte      PropertyOperator = "lte"
+	OpIn       PropertyOperator = "in"       // Any of a comma separated list
+	OpContains PropertyOperator = "contains" // Substring of a string or enum, ignoring case
+	OpExists   PropertyOperator = "exists"   // "true" if the resource sets the property, "false" if it doesn't
+)
+
+// PropertyFilter is one condition on a property, e.g. capacity[gte]=10.
+type PropertyFilter struct {
+	Key   string
+	Op    PropertyOperator
+	Value string // As given
+	// Set once checked against the schema: the type the property compares as, and the value typed
+	// (several for in, a bool for exists)
+	Type   PropertyType
+	Values []interface{}
+}
+
+// PropertyQuery filters and sorts resources by their properties. Both need the resource type, whose
+// schema says how each property compares.
+type PropertyQuery struct {
+	Filters  []PropertyFilter
+	SortBy   string // Property to sort by (sort=prop_<key>), resources without it last
+	SortDesc bool   // sort=-prop_<key>
+	SortType PropertyType
+}
+
+// IsEmpty reports whether the query neither filters nor sorts.
+func (q PropertyQuery) IsEmpty() bool {
+	return len(q.Filters) == 0 && q.SortBy == ""
+}
+
+// ParsePropertyFilter reads one property filter: key "capacity" with value "10" is capacity = 10,
+// and key "capacity[gte]" is capacity >= 10. Operators are checked with the schema.
+func ParsePropertyFilter(key, value string) PropertyFilter {
+	op := OpEq
+	if i := strings.Index(key, "["); i > 0 && strings.HasSuffix(key, "]") {
+		op = PropertyOperator(strings.ToLower(key[i+1 : len(key)-1]))
+		key = key[:i]
+	}
+	return PropertyFilter{Key: key, Op: op, Value: value}
+}
+
+// PropertyFilters parses filters given as key to value, e.g. {"capacity[gte]": "10"}.
+func PropertyFilters(props map[string]string) []PropertyFilter {
+	filters := make([]PropertyFilter, 0, len(props))
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		filters = append(filters, ParsePropertyFilter(key, props[key]))
+	}
+	return filters
 }
 
 func (r *Resource) Sanitize() {
 	r.Name = strings.TrimSpace(r.Name)
 	r.Location = strings.TrimSpace(r.Location)
 	r.Description = strings.TrimSpace(r.Description)
+	if r.Quantity < 1 {
+		r.Quantity = 1
+	}
 }
 func (rt *ResourceType) Sanitize() {
 	rt.Type = strings.TrimSpace(rt.Type)
+	if rt.SlotMinutes < 1 {
+		rt.SlotMinutes = DefaultSlotMinutes
+	}
 }
@@ -2,15 +2,19 @@ package resource
 
 import (
 	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type IResourceService interface {
 	GetResourceByID(id int) (*Resource, error)
-	GetAllResources(typeID *int, location string, props map[string]string, startTime, endTime *string, pagination utils.PaginationQuery) ([]ResourceSummary, int64, error)
+	GetAllResources(typeID *int, locationID *int, location string, props PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]ResourceSummary, int64, error)
 	GetAllResourceTypes(pagination utils.PaginationQuery) ([]ResourceType, int64, error)
 	GetResourceTypeByID(id int) (*ResourceType, error)
 
@@ -35,12 +39,12 @@ func NewResourceHandler(iservice IResourceService) *ResourceHandler {
 func (h *ResourceHandler) CreateResource(c *gin.Context) {
 	var res Resource
 	if err := c.ShouldBindJSON(&res); err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid resource")
+		utils.BindingError(c, err, "invalid resource")
 		return
 	}
 	res.Sanitize()
 	if err := h.iservice.CreateResource(&res); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, res)
@@ -55,7 +59,7 @@ func (h *ResourceHandler) GetResource(c *gin.Context) {
 	}
 	res, err := h.iservice.GetResourceByID(id)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -65,42 +69,72 @@ func (h *ResourceHandler) ListResources(c *gin.Context) {
 	// 1. Pagination
 	pagination := utils.GetPaginationParams(c)
 
-	// 2. Standard Filters
-	var typeID *int
+	// 2. Filters
+	f, err := ParseResourceFilter(c)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	// 3. Call Service
+	resources, total, err := h.iservice.GetAllResources(f.TypeID, f.LocationID, f.Location, f.Props, f.StartTime, f.EndTime, f.Role, pagination)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.GetPaginatedResponse(resources, pagination.Page, pagination.Limit, total))
+}
+
+// ParseResourceFilter reads the ListResources filters from the query string, so other listings
+// over resources (e.g. availability) select them the same way.
+func ParseResourceFilter(c *gin.Context) (*ResourceFilter, error) {
+	f := &ResourceFilter{}
+
+	// 1. Standard Filters
 	if tID := c.Query("type_id"); tID != "" {
 		id, err := strconv.Atoi(tID)
 		if err != nil {
-			utils.Error(c, http.StatusBadRequest, "invalid type_id")
-			return
+			return nil, fmt.Errorf("%w: invalid type_id", utils.ErrInvalidInput)
 		}
-		typeID = &id
+		f.TypeID = &id
 	}
-	location := c.Query("location")
+	if lID := c.Query("location_id"); lID != "" {
+		id, err := strconv.Atoi(lID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid location_id", utils.ErrInvalidInput)
+		}
+		f.LocationID = &id
+	}
+	f.Location = c.Query("location")
 
-	// 3. Dynamic Filters
-	props := make(map[string]string)
-	for key, values := range c.Request.URL.Query() {
-		if len(key) > 5 && key[:5] == "prop_" && len(values) > 0 {
-			props[key[5:]] = values[0]
+	// 2. Dynamic Filters: prop_<key>=value, or prop_<key>[op]=value (e.g. prop_capacity[gte]=10)
+	query := c.Request.URL.Query()
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		if len(key) > 5 && key[:5] == "prop_" {
+			for _, value := range query[key] {
+				f.Props.Filters = append(f.Props.Filters, ParsePropertyFilter(key[5:], value))
+			}
+		}
+	}
+	if sort := c.Query("sort"); sort != "" {
+		f.Props.SortDesc = strings.HasPrefix(sort, "-")
+		by, ok := strings.CutPrefix(strings.TrimPrefix(sort, "-"), "prop_")
+		if !ok || by == "" {
+			return nil, fmt.Errorf("%w: sort must be prop_<key>, or -prop_<key> for descending", utils.ErrInvalidInput)
 		}
+		f.Props.SortBy = by
 	}
 
-	// 4. Temporal Filter
-	var startTime, endTime *string
+	// 3. Temporal Filter
 	if st := c.Query("start_time"); st != "" {
-		startTime = &st
+		f.StartTime = &st
 	}
 	if et := c.Query("end_time"); et != "" {
-		endTime = &et
+		f.EndTime = &et
 	}
-
-	// 5. Call Service
-	resources, total, err := h.iservice.GetAllResources(typeID, location, props, startTime, endTime, pagination)
-	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
-		return
-	}
-	c.JSON(http.StatusOK, utils.GetPaginatedResponse(resources, pagination.Page, pagination.Limit, total))
+	// The caller's role decides how far ahead they may book
+	f.Role = c.GetString("userRole")
+	return f, nil
 }
 
 func (h *ResourceHandler) UpdateResource(c *gin.Context) {
@@ -112,13 +146,13 @@ func (h *ResourceHandler) UpdateResource(c *gin.Context) {
 	}
 	var res Resource
 	if err := c.ShouldBindJSON(&res); err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid resource")
+		utils.BindingError(c, err, "invalid resource")
 		return
 	}
 	res.Sanitize()
 	res.ID = id
 	if err := h.iservice.UpdateResource(&res); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
@@ -132,7 +166,7 @@ func (h *ResourceHandler) DeleteResource(c *gin.Context) {
 		return
 	}
 	if err := h.iservice.DeleteResource(id); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "resource deleted successfully"})
@@ -141,12 +175,12 @@ func (h *ResourceHandler) DeleteResource(c *gin.Context) {
 func (h *ResourceHandler) CreateResourceType(c *gin.Context) {
 	var resType ResourceType
 	if err := c.ShouldBindJSON(&resType); err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid resource type")
+		utils.BindingError(c, err, "invalid resource type")
 		return
 	}
 	resType.Sanitize()
 	if err := h.iservice.CreateResourceType(&resType); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resType)
@@ -156,7 +190,7 @@ func (h *ResourceHandler) ListResourceTypes(c *gin.Context) {
 	pagination := utils.GetPaginationParams(c)
 	types, total, err := h.iservice.GetAllResourceTypes(pagination)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, utils.GetPaginatedResponse(types, pagination.Page, pagination.Limit, total))
@@ -171,7 +205,7 @@ func (h *ResourceHandler) GetResourceType(c *gin.Context) {
 	}
 	resType, err := h.iservice.GetResourceTypeByID(id)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resType)
@@ -186,13 +220,13 @@ func (h *ResourceHandler) UpdateResourceType(c *gin.Context) {
 	}
 	var resType ResourceType
 	if err := c.ShouldBindJSON(&resType); err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid resource type")
+		utils.BindingError(c, err, "invalid resource type")
 		return
 	}
 	resType.Sanitize()
 	resType.ID = id
 	if err := h.iservice.UpdateResourceType(&resType); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resType)
@@ -206,7 +240,7 @@ func (h *ResourceHandler) DeleteResourceType(c *gin.Context) {
 		return
 	}
 	if err := h.iservice.DeleteResourceType(id); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "resource type deleted successfully"})
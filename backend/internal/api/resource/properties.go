@@ -0,0 +1,358 @@
+package resource
+
+import (
+	"ResourceAllocator/internal/api/utils"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// validateSchema checks the property schemas of a type, reporting every problem under
+// "schema_definition.<key>".
+func validateSchema(schema map[string]PropertySchema) error {
+	var fields []utils.FieldError
+	for _, key := range slices.Sorted(maps.Keys(schema)) {
+		p := schema[key]
+		field := "schema_definition." + key
+		fail := func(rule, message string) {
+			fields = append(fields, utils.FieldError{Field: field, Rule: rule, Message: message})
+		}
+		if strings.TrimSpace(key) == "" {
+			fields = append(fields, utils.FieldError{Field: "schema_definition", Rule: "required", Message: "property names must not be empty"})
+			continue
+		}
+		switch p.Type {
+		case PropertyString, PropertyInteger, PropertyNumber, PropertyBoolean, PropertyEnum, PropertyDate:
+		default:
+			fail("type", "must be one of: string, integer, number, boolean, enum, date")
+			continue
+		}
+		before := len(fields)
+		if p.Type == PropertyEnum && len(p.Values) == 0 {
+			fail("values", "an enum needs the values it can take")
+		}
+		if p.Type != PropertyEnum && len(p.Values) > 0 {
+			fail("values", "only an enum has values")
+		}
+		if p.Min != nil || p.Max != nil {
+			if p.Type != PropertyString && p.Type != PropertyInteger && p.Type != PropertyNumber {
+				fail("min", "min and max only apply to strings, integers and numbers")
+			} else if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+				fail("max", "must not be less than min")
+			}
+		}
+		if p.Pattern != "" {
+			if p.Type != PropertyString {
+				fail("pattern", "only a string has a pattern")
+			} else if _, err := regexp.Compile(p.Pattern); err != nil {
+				fail("pattern", fmt.Sprintf("is not a valid regular expression: %v", err))
+			}
+		}
+		// The default has to pass the rules above, so only check it once they hold
+		if p.Default != nil && len(fields) == before {
+			if _, problem := p.check(p.Default); problem != nil {
+				fail(problem.Rule, "default "+problem.Message)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return &utils.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateProperties checks the properties of a resource against the schema of its type and returns
+// them typed, with the defaults of optional properties that were left out filled in. Every problem
+// is reported, under "properties.<key>".
+func validateProperties(schema map[string]PropertySchema, props map[string]interface{}) (map[string]interface{}, error) {
+	typed := make(map[string]interface{}, len(schema))
+	var fields []utils.FieldError
+	for _, key := range slices.Sorted(maps.Keys(schema)) {
+		p := schema[key]
+		v, set := props[key]
+		if !set || v == nil || v == "" {
+			switch {
+			case p.Default != nil:
+				if value, problem := p.check(p.Default); problem == nil {
+					typed[key] = value
+				}
+			case p.Required:
+				fields = append(fields, utils.FieldError{Field: "properties." + key, Rule: "required", Message: "is required"})
+			}
+			continue
+		}
+		value, problem := p.check(v)
+		if problem != nil {
+			problem.Field = "properties." + key
+			fields = append(fields, *problem)
+			continue
+		}
+		typed[key] = value
+	}
+
+	// Check for extra fields
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		if _, exists := schema[key]; !exists {
+			fields = append(fields, utils.FieldError{Field: "properties." + key, Rule: "unknown", Message: "is not a property of this resource type"})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, &utils.ValidationError{Fields: fields}
+	}
+	return typed, nil
+}
+
+// checkPropertyQuery checks property filters and sorting against the schema, and types the values
+// of the filters so that numbers compare as numbers. Problems are reported by query parameter
+// (prop_<key> or sort).
+func checkPropertyQuery(schema map[string]PropertySchema, q *PropertyQuery) error {
+	var fields []utils.FieldError
+	for i := range q.Filters {
+		f := &q.Filters[i]
+		field := "prop_" + f.Key
+		fail := func(rule, message string) {
+			fields = append(fields, utils.FieldError{Field: field, Rule: rule, Message: message})
+		}
+		p, ok := schema[f.Key]
+		if !ok {
+			fail("unknown", "is not a property of this resource type")
+			continue
+		}
+		f.Type = p.Type
+		switch f.Op {
+		case OpEq:
+			value, problem := p.parseFilter(f.Value)
+			if problem != nil {
+				fail(problem.Rule, problem.Message)
+				continue
+			}
+			f.Values = []interface{}{value}
+		case OpGt, OpGte, OpLt, OpLte:
+			if p.Type != PropertyInteger && p.Type != PropertyNumber && p.Type != PropertyDate {
+				fail("operator", fmt.Sprintf("%s only compares integers, numbers and dates", f.Op))
+				continue
+			}
+			value, problem := p.parse(f.Value)
+			if problem != nil {
+				fail(problem.Rule, problem.Message)
+				continue
+			}
+			f.Values = []interface{}{value}
+		case OpIn:
+			if p.Type == PropertyBoolean {
+				fail("operator", "in doesn't apply to booleans (use eq)")
+				continue
+			}
+			f.Values = nil
+			for _, item := range strings.Split(f.Value, ",") {
+				value, problem := p.parseFilter(strings.TrimSpace(item))
+				if problem != nil {
+					fail(problem.Rule, "each value "+problem.Message)
+					break
+				}
+				f.Values = append(f.Values, value)
+			}
+		case OpContains:
+			if p.Type != PropertyString && p.Type != PropertyEnum {
+				fail("operator", "contains only applies to strings and enums")
+				continue
+			}
+			f.Values = []interface{}{f.Value}
+		case OpExists:
+			exists := true
+			if f.Value != "" {
+				parsed, err := strconv.ParseBool(f.Value)
+				if err != nil {
+					fail("type", "exists must be true or false")
+					continue
+				}
+				exists = parsed
+			}
+			f.Values = []interface{}{exists}
+		default:
+			fail("operator", fmt.Sprintf("'%s' is not an operator (use eq, gt, gte, lt, lte, in, contains or exists)", f.Op))
+		}
+	}
+	if q.SortBy != "" {
+		if p, ok := schema[q.SortBy]; ok {
+			q.SortType = p.Type
+		} else {
+			fields = append(fields, utils.FieldError{Field: "sort", Rule: "unknown", Message: fmt.Sprintf("'%s' is not a property of this resource type", q.SortBy)})
+		}
+	}
+	if len(fields) > 0 {
+		return &utils.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// TypeProperties converts the properties that fit the type's schema to their stored form, leaving
+// anything else as it is. It brings properties saved before schemas were typed up to date.
+func (rt *ResourceType) TypeProperties(props map[string]interface{}) map[string]interface{} {
+	typed := make(map[string]interface{}, len(props))
+	for key, v := range props {
+		typed[key] = v
+		if p, ok := rt.SchemaDefinition[key]; ok && v != nil {
+			if value, problem := p.check(v); problem == nil {
+				typed[key] = value
+			}
+		}
+	}
+	return typed
+}
+
+// check returns v as it is stored for the property (see parse), if it keeps to the schema's rules.
+// If it doesn't, the FieldError says which rule it breaks.
+func (p PropertySchema) check(v interface{}) (interface{}, *utils.FieldError) {
+	value, problem := p.parse(v)
+	if problem != nil {
+		return nil, problem
+	}
+	switch x := value.(type) {
+	case string:
+		if p.Type == PropertyEnum {
+			if !slices.Contains(p.Values, x) {
+				return nil, broken("enum", fmt.Sprintf("must be one of: %s", strings.Join(p.Values, ", ")))
+			}
+			break
+		}
+		if p.Type != PropertyString {
+			break
+		}
+		length := float64(utf8.RuneCountInString(x))
+		if p.Min != nil && length < *p.Min {
+			return nil, broken("min", fmt.Sprintf("must be at least %s characters long", formatLimit(*p.Min)))
+		}
+		if p.Max != nil && length > *p.Max {
+			return nil, broken("max", fmt.Sprintf("must be at most %s characters long", formatLimit(*p.Max)))
+		}
+		if p.Pattern != "" {
+			if re, err := regexp.Compile(p.Pattern); err == nil && !re.MatchString(x) {
+				return nil, broken("pattern", fmt.Sprintf("must match %s", p.Pattern))
+			}
+		}
+	case int64:
+		problem = p.checkRange(float64(x))
+	case float64:
+		problem = p.checkRange(x)
+	}
+	if problem != nil {
+		return nil, problem
+	}
+	return value, nil
+}
+
+// parse returns v as it is stored for the property, without the schema's other rules: strings and
+// enums as given, integers as int64, numbers as float64, booleans as bool and dates as "2006-01-02".
+// Numbers and booleans may come as strings, e.g. from a form or a query string.
+func (p PropertySchema) parse(v interface{}) (interface{}, *utils.FieldError) {
+	switch p.Type {
+	case PropertyString, PropertyEnum:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return nil, broken("type", "must be a string")
+	case PropertyInteger:
+		n, ok := toNumber(v)
+		if !ok || n != math.Trunc(n) {
+			return nil, broken("type", "must be a whole number")
+		}
+		return int64(n), nil
+	case PropertyNumber:
+		n, ok := toNumber(v)
+		if !ok {
+			return nil, broken("type", "must be a number")
+		}
+		return n, nil
+	case PropertyBoolean:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(strings.TrimSpace(b)); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, broken("type", "must be true or false")
+	case PropertyDate:
+		if s, ok := v.(string); ok {
+			if d, err := time.Parse("2006-01-02", strings.TrimSpace(s)); err == nil {
+				return d.Format("2006-01-02"), nil
+			}
+		}
+		return nil, broken("type", "must be a date (YYYY-MM-DD)")
+	}
+	// Types this version doesn't know are kept as they are
+	return v, nil
+}
+
+// parseFilter parses a value to filter the property by. An enum value has to be one of the enum's
+// values; the other rules of the schema don't apply, since a filter may look for any value.
+func (p PropertySchema) parseFilter(v interface{}) (interface{}, *utils.FieldError) {
+	value, problem := p.parse(v)
+	if problem != nil {
+		return nil, problem
+	}
+	if s, ok := value.(string); ok && p.Type == PropertyEnum && !slices.Contains(p.Values, s) {
+		return nil, broken("enum", fmt.Sprintf("must be one of: %s", strings.Join(p.Values, ", ")))
+	}
+	return value, nil
+}
+
+func (p PropertySchema) checkRange(n float64) *utils.FieldError {
+	if p.Min != nil && n < *p.Min {
+		return broken("min", fmt.Sprintf("must be at least %s", formatLimit(*p.Min)))
+	}
+	if p.Max != nil && n > *p.Max {
+		return broken("max", fmt.Sprintf("must be at most %s", formatLimit(*p.Max)))
+	}
+	return nil
+}
+
+// toNumber reads a JSON number, a Go number or a numeric string.
+func toNumber(v interface{}) (float64, bool) {
+	var n float64
+	switch x := v.(type) {
+	case float64:
+		n = x
+	case float32:
+		n = float64(x)
+	case int:
+		n = float64(x)
+	case int64:
+		n = float64(x)
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return 0, false
+		}
+		n = f
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		if err != nil {
+			return 0, false
+		}
+		n = f
+	default:
+		return 0, false
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+func broken(rule, message string) *utils.FieldError {
+	return &utils.FieldError{Rule: rule, Message: message}
+}
+
+func formatLimit(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"ResourceAllocator/internal/api/user"
 	"ResourceAllocator/internal/api/utils"
 	"fmt"
 	"time"
@@ -8,7 +9,7 @@ import (
 
 type ResourceRepository interface {
 	GetResourceByID(id int) (*Resource, error)
-	GetAllResources(typeID *int, location string, props map[string]string, startTime, endTime *string, pagination utils.PaginationQuery) ([]ResourceSummary, int64, error)
+	GetAllResources(typeID *int, locationID *int, location string, props PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]ResourceSummary, int64, error)
 	GetAllResourceTypes(pagination utils.PaginationQuery) ([]ResourceType, int64, error)
 	GetResourceTypeByID(id int) (*ResourceType, error)
 
@@ -24,8 +25,16 @@ type ResourceRepository interface {
 	CountResourcesByType(typeID int) (int64, error)
 }
 
+// LocationResolver finds the location a resource is placed at, by its ID or else by its path, and
+// returns both.
+type LocationResolver interface {
+	ResolveLocation(id *int, path string) (*int, string, error)
+}
+
 type ResourceService struct {
 	Repo ResourceRepository
+	// Location tree resources are placed in; nil keeps the location as given
+	Locations LocationResolver
 }
 
 func NewResourceService(repo ResourceRepository) *ResourceService {
@@ -39,7 +48,15 @@ func (s *ResourceService) CreateResource(res *Resource) error {
 		return err
 	}
 	// 2. [NEW] Validate Properties
-	if err := validateProperties(resType.SchemaDefinition, res.Properties); err != nil {
+	props, err := validateProperties(resType.SchemaDefinition, res.Properties)
+	if err != nil {
+		return err
+	}
+	res.Properties = props
+	if err := validateSlotOverrides(res, resType); err != nil {
+		return err
+	}
+	if err := s.placeResource(res); err != nil {
 		return err
 	}
 	return s.Repo.CreateResource(res)
@@ -49,21 +66,19 @@ func (s *ResourceService) GetResourceByID(id int) (*Resource, error) {
 	return s.Repo.GetResourceByID(id)
 }
 
-func (s *ResourceService) GetAllResources(typeID *int, location string, props map[string]string, startTime, endTime *string, pagination utils.PaginationQuery) ([]ResourceSummary, int64, error) {
+func (s *ResourceService) GetAllResources(typeID *int, locationID *int, location string, props PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]ResourceSummary, int64, error) {
 	// VALIDATION LOGIC
-	if len(props) > 0 {
+	if !props.IsEmpty() {
 		if typeID == nil {
-			return nil, 0, fmt.Errorf("%w: cannot filter by properties without specifying type_id", utils.ErrInvalidInput)
+			return nil, 0, fmt.Errorf("%w: cannot filter or sort by properties without specifying type_id", utils.ErrInvalidInput)
 		}
-		// Verify properties against Schema
+		// Verify properties against Schema, which also says how each one compares
 		resType, err := s.Repo.GetResourceTypeByID(*typeID)
 		if err != nil {
 			return nil, 0, err
 		}
-		for key := range props {
-			if _, ok := resType.SchemaDefinition[key]; !ok {
-				return nil, 0, fmt.Errorf("%w: property '%s' is not valid for this resource type", utils.ErrInvalidInput, key)
-			}
+		if err := checkPropertyQuery(resType.SchemaDefinition, &props); err != nil {
+			return nil, 0, err
 		}
 	}
 
@@ -81,17 +96,11 @@ func (s *ResourceService) GetAllResources(typeID *int, location string, props ma
 		if end.Before(start) {
 			return nil, 0, fmt.Errorf("%w: end_time must be after start_time", utils.ErrInvalidInput)
 		}
-
-		// 9-5 and Holiday Checks
-		if err := utils.IsWorkingHours(start, end); err != nil {
-			return nil, 0, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
-		}
-		if err := utils.IsHoliday(start); err != nil {
-			return nil, 0, fmt.Errorf("%w: %v", utils.ErrInvalidInput, err)
-		}
+		// Opening hours, weekends and holidays depend on each resource's location, so resources
+		// closed for the window are left out by the query
 	}
 
-	return s.Repo.GetAllResources(typeID, location, props, startTime, endTime, pagination)
+	return s.Repo.GetAllResources(typeID, locationID, location, props, startTime, endTime, role, pagination)
 }
 
 func (s *ResourceService) UpdateResource(res *Resource) error {
@@ -105,7 +114,15 @@ func (s *ResourceService) UpdateResource(res *Resource) error {
 	}
 
 	// 2. [NEW] Validate Properties
-	if err := validateProperties(resType.SchemaDefinition, res.Properties); err != nil {
+	props, err := validateProperties(resType.SchemaDefinition, res.Properties)
+	if err != nil {
+		return err
+	}
+	res.Properties = props
+	if err := validateSlotOverrides(res, resType); err != nil {
+		return err
+	}
+	if err := s.placeResource(res); err != nil {
 		return err
 	}
 
@@ -118,6 +135,15 @@ func (s *ResourceService) DeleteResource(id int) error {
 }
 
 func (s *ResourceService) CreateResourceType(resType *ResourceType) error {
+	if err := (&Resource{Type: resType}).SlotRules().Validate(); err != nil {
+		return err
+	}
+	if err := validateBookingWindows(resType); err != nil {
+		return err
+	}
+	if err := validateSchema(resType.SchemaDefinition); err != nil {
+		return err
+	}
 	return s.Repo.CreateResourceType(resType)
 }
 
@@ -130,6 +156,15 @@ func (s *ResourceService) GetResourceTypeByID(id int) (*ResourceType, error) {
 }
 
 func (s *ResourceService) UpdateResourceType(resType *ResourceType) error {
+	if err := (&Resource{Type: resType}).SlotRules().Validate(); err != nil {
+		return err
+	}
+	if err := validateBookingWindows(resType); err != nil {
+		return err
+	}
+	if err := validateSchema(resType.SchemaDefinition); err != nil {
+		return err
+	}
 	return s.Repo.UpdateResourceType(resType)
 }
 
@@ -144,19 +179,37 @@ func (s *ResourceService) DeleteResourceType(id int) error {
 	return s.Repo.DeleteResourceType(id)
 }
 
-func validateProperties(schema map[string]string, props map[string]interface{}) error {
-	for key := range schema {
-		if _, exists := props[key]; !exists {
-			// Return typed error!
-			// We use fmt.Errorf to Wrap the sentinel with details
-			return fmt.Errorf("%w: missing required property '%s'", utils.ErrInvalidInput, key)
-		}
+// placeResource points the resource at an existing location, given by location_id or by its path,
+// and stores that location's path with it.
+func (s *ResourceService) placeResource(res *Resource) error {
+	if s.Locations == nil {
+		return nil
 	}
+	id, path, err := s.Locations.ResolveLocation(res.LocationID, res.Location)
+	if err != nil {
+		return err
+	}
+	res.LocationID, res.Location = id, path
+	return nil
+}
+
+// validateSlotOverrides checks the slot rules the resource ends up with once its overrides are
+// applied on top of its type's.
+func validateSlotOverrides(res *Resource, resType *ResourceType) error {
+	effective := *res
+	effective.Type = resType
+	return effective.SlotRules().Validate()
+}
 
-	// Check for extra fields
-	for key := range props {
-		if _, exists := schema[key]; !exists {
-			return fmt.Errorf("%w: unknown property '%s'", utils.ErrInvalidInput, key)
+// validateBookingWindows checks the role overrides of a type's booking window; the type's own
+// window is covered by binding.
+func validateBookingWindows(resType *ResourceType) error {
+	for role, w := range resType.RoleBookingWindows {
+		if role != string(user.RoleAdmin) && role != string(user.RoleEmployee) {
+			return fmt.Errorf("%w: unknown role '%s' in role_booking_windows", utils.ErrInvalidInput, role)
+		}
+		if w.MinLeadMinutes < 0 || w.MaxHorizonDays < 0 {
+			return fmt.Errorf("%w: booking window of role '%s' must not be negative", utils.ErrInvalidInput, role)
 		}
 	}
 	return nil
@@ -2,7 +2,10 @@ package routes
 
 import (
 	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/calendar"
+	"ResourceAllocator/internal/api/location"
 	"ResourceAllocator/internal/api/middleware"
+	"ResourceAllocator/internal/api/quota"
 	"ResourceAllocator/internal/api/resource"
 	"ResourceAllocator/internal/api/user"
 	"time"
@@ -17,14 +20,20 @@ type Handlers struct {
 	UserHandler     *user.UserHandler
 	ResourceHandler *resource.ResourceHandler
 	BookingHandler  *booking.BookingHandler
+	QuotaHandler    *quota.QuotaHandler
+	CalendarHandler *calendar.CalendarHandler
+	LocationHandler *location.LocationHandler
 }
 
 // NewHandlers builds the Handlers container (called from main.go).
-func NewHandlers(userHandler *user.UserHandler, resourceHandler *resource.ResourceHandler, bookingHandler *booking.BookingHandler) *Handlers {
+func NewHandlers(userHandler *user.UserHandler, resourceHandler *resource.ResourceHandler, bookingHandler *booking.BookingHandler, quotaHandler *quota.QuotaHandler, calendarHandler *calendar.CalendarHandler, locationHandler *location.LocationHandler) *Handlers {
 	return &Handlers{
 		UserHandler:     userHandler,
 		ResourceHandler: resourceHandler,
 		BookingHandler:  bookingHandler,
+		QuotaHandler:    quotaHandler,
+		CalendarHandler: calendarHandler,
+		LocationHandler: locationHandler,
 	}
 }
 
@@ -52,6 +61,9 @@ func SetupRoutes(h *Handlers) *gin.Engine {
 			// Admin login - NOT protected
 			auth.POST("/login", h.UserHandler.Login) // For Login
 		}
+
+		// Check-in at the resource with the signed code shown there (no token needed)
+		api.POST("/checkin", h.BookingHandler.CheckInWithCode)
 	}
 
 	// PROTECTED ROUTES
@@ -64,6 +76,13 @@ func SetupRoutes(h *Handlers) *gin.Engine {
 		protected.GET("/resource_types", h.ResourceHandler.ListResourceTypes)   // For users/ Admins to see all resource types
 		protected.GET("/resource_types/:id", h.ResourceHandler.GetResourceType) // For users/ Admins to see a specific resource type
 
+		// Location tree, for picking where to look
+		protected.GET("/locations/tree", h.LocationHandler.GetLocationTree)
+
+		// Free/busy timelines, of one resource or of every resource matching the list filters
+		protected.GET("/resources/availability", h.BookingHandler.GetAvailabilityGrid)
+		protected.GET("/resources/:id/availability", h.BookingHandler.GetResourceAvailability)
+
 		// User Management
 		protected.GET("/user", h.UserHandler.GetUser)
 		protected.PATCH("/user/password", h.UserHandler.UpdatePassword) // [NEW] Change Password
@@ -71,7 +90,22 @@ func SetupRoutes(h *Handlers) *gin.Engine {
 		// [NEW] Bookings (User)
 		protected.POST("/bookings", h.BookingHandler.CreateBooking)
 		protected.GET("/bookings", h.BookingHandler.ListMyBookings)
+		protected.PATCH("/bookings/:id", h.BookingHandler.RescheduleBooking) // Change window, resource or purpose in place
 		protected.PATCH("/bookings/:id/cancel", h.BookingHandler.CancelBooking)
+		protected.PATCH("/bookings/:id/checkin", h.BookingHandler.SelfCheckIn)        // Owner checks in with their own token
+		protected.PATCH("/bookings/:id/extend", h.BookingHandler.ExtendBooking)       // Meeting running over
+		protected.PATCH("/bookings/:id/checkout", h.BookingHandler.CheckOut)          // Meeting ended early, give the rest back
+		protected.PATCH("/bookings/:id/series", h.BookingHandler.UpdateSeries)        // Edit this / following / all occurrences
+		protected.PATCH("/bookings/:id/series/cancel", h.BookingHandler.CancelSeries) // Cancel this / following / all occurrences
+		protected.POST("/bookings/bundles", h.BookingHandler.CreateBundle)            // Several resources, one window, all-or-nothing
+		protected.PATCH("/bookings/bundles/:id/cancel", h.BookingHandler.CancelBundle)
+		protected.POST("/bookings/find-time", h.BookingHandler.FindTime)           // Windows where a room is free and every attendee can make it
+		protected.GET("/bookings/:id/history", h.BookingHandler.GetBookingHistory) // Status changes, for the owner and admins
+
+		// Waitlist (User)
+		protected.POST("/waitlist", h.BookingHandler.JoinWaitlist)
+		protected.GET("/waitlist", h.BookingHandler.ListMyWaitlist)
+		protected.DELETE("/waitlist/:id", h.BookingHandler.LeaveWaitlist)
 	}
 
 	// ADMIN ROUTES
@@ -84,21 +118,53 @@ func SetupRoutes(h *Handlers) *gin.Engine {
 		admin.GET("/user", h.UserHandler.ListUsers)
 		admin.DELETE("/user/:uuid", h.UserHandler.DeleteUser)
 		admin.PUT("/user/:uuid", h.UserHandler.UpdateUser)
+		admin.GET("/user/:uuid/quota", h.QuotaHandler.GetUserUsage) // Usage against every quota rule that applies
 
 		// Resource Management
 		admin.POST("/resources", h.ResourceHandler.CreateResource)    // For Admins to create a new resource
 		admin.PUT("/resources/:id", h.ResourceHandler.UpdateResource) // For Admins to update a resource
 		// admin.PUT("/resource_types/:id", h.ResourceHandler.UpdateResourceType)     // For Admins to update a resource
 		admin.DELETE("/resources/:id", h.ResourceHandler.DeleteResource)          // For Admins to delete a resource
+		admin.GET("/resources/:id/checkin-code", h.BookingHandler.GetCheckInCode) // Code / QR payload to display at the resource
 		admin.DELETE("/resource_types/:id", h.ResourceHandler.DeleteResourceType) // For Admins to delete a resource
 		admin.POST("/resource_types", h.ResourceHandler.CreateResourceType)       // For Admins to create a new resource type
 
 		// [NEW] Bookings (Admin)
 		admin.GET("/bookings", h.BookingHandler.ListAllBookings)
 		admin.PATCH("/bookings/:id/status", h.BookingHandler.UpdateBookingStatus)
+		admin.GET("/bookings/bundles/:id", h.BookingHandler.GetBundle)
+		admin.PATCH("/bookings/bundles/:id/status", h.BookingHandler.UpdateBundleStatus) // Approve / reject every member together
 
 		admin.PATCH("/bookings/:id/checkin", h.BookingHandler.CheckIn)
 
+		// Batch allocation of competing pending requests (Admin)
+		admin.POST("/allocations/preview", h.BookingHandler.PreviewAllocation)
+		admin.POST("/allocations/commit", h.BookingHandler.CommitAllocation)
+
+		// Quota Rules (Admin)
+		admin.POST("/quotas", h.QuotaHandler.CreateRule)
+		admin.GET("/quotas", h.QuotaHandler.ListRules)
+		admin.GET("/quotas/:id", h.QuotaHandler.GetRule)
+		admin.PUT("/quotas/:id", h.QuotaHandler.UpdateRule)
+		admin.DELETE("/quotas/:id", h.QuotaHandler.DeleteRule)
+
+		// Holiday Calendars (Admin)
+		admin.POST("/calendars", h.CalendarHandler.CreateCalendar)
+		admin.GET("/calendars", h.CalendarHandler.ListCalendars)
+		admin.GET("/calendars/:id", h.CalendarHandler.GetCalendar) // With its holidays
+		admin.PUT("/calendars/:id", h.CalendarHandler.UpdateCalendar)
+		admin.DELETE("/calendars/:id", h.CalendarHandler.DeleteCalendar)
+		admin.POST("/calendars/:id/holidays", h.CalendarHandler.AddHoliday)
+		admin.DELETE("/calendars/:id/holidays/:holiday_id", h.CalendarHandler.DeleteHoliday)
+		admin.POST("/calendars/:id/import", h.CalendarHandler.ImportICS) // Bulk add from an iCalendar (.ics) file
+
+		// Locations (Admin): the campus > building > floor > zone tree, with time zones and opening hours
+		admin.POST("/locations", h.LocationHandler.CreateLocation)
+		admin.GET("/locations", h.LocationHandler.ListLocations)
+		admin.GET("/locations/:id", h.LocationHandler.GetLocation)
+		admin.PUT("/locations/:id", h.LocationHandler.UpdateLocation) // Rename or move (parent_id); resources inside follow
+		admin.DELETE("/locations/:id", h.LocationHandler.DeleteLocation)
+
 		// [NEW] Dashboard Stats (Admin)
 		admin.GET("/dashboard/resources", h.BookingHandler.GetDashboardResourceStats)
 		admin.GET("/dashboard/users", h.BookingHandler.GetDashboardUserStats)
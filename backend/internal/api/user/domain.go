@@ -21,6 +21,7 @@ type User struct {
 	EmployeeID string         `json:"employee_id" binding:"required" gorm:"unique"`
 	Role       Role           `json:"role" binding:"required,oneof=ADMIN EMPLOYEE"`
 	Email      string         `json:"email" binding:"required,email" gorm:"unique;not null"`
+	Group      string         `json:"group" gorm:"index"` // Team / department, used to target quota rules
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -56,11 +57,13 @@ type UserSummary struct {
 	Name       string `json:"name"`
 	EmployeeID string `json:"employee_id"`
 	Role       Role   `json:"role"`
+	Group      string `json:"group"`
 }
 
 func (u *CreateUser) Sanitize() {
 	u.Name = strings.TrimSpace(u.Name)
 	u.Email = strings.TrimSpace(u.Email)
+	u.Group = strings.TrimSpace(u.Group)
 }
 func (l *LoginRequest) Sanitize() {
 	l.Email = strings.TrimSpace(l.Email)
@@ -68,4 +71,5 @@ func (l *LoginRequest) Sanitize() {
 func (u *User) Sanitize() {
 	u.Name = strings.TrimSpace(u.Name)
 	u.Email = strings.TrimSpace(u.Email)
+	u.Group = strings.TrimSpace(u.Group)
 }
@@ -29,13 +29,13 @@ func NewUserHandler(iuserService IUserService) *UserHandler {
 func (uh *UserHandler) Login(c *gin.Context) {
 	var loginReq LoginRequest
 	if err := c.ShouldBindJSON(&loginReq); err != nil {
-		utils.Error(c, http.StatusBadRequest, "Invalid login request")
+		utils.BindingError(c, err, "Invalid login request")
 		return
 	}
 	loginReq.Sanitize()
 	loginRes, err := uh.iuserService.Login(loginReq.Email, loginReq.Password)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, loginRes)
@@ -44,12 +44,12 @@ func (uh *UserHandler) Login(c *gin.Context) {
 func (uh *UserHandler) CreateNewUser(c *gin.Context) {
 	var userReq CreateUser
 	if err := c.ShouldBindJSON(&userReq); err != nil {
-		utils.Error(c, http.StatusBadRequest, "Invalid user: "+err.Error())
+		utils.BindingError(c, err, "Invalid user")
 		return
 	}
 	userReq.Sanitize()
 	if err := uh.iuserService.CreateNewUser(&userReq); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, userReq.User)
@@ -64,12 +64,12 @@ func (uh *UserHandler) UpdatePassword(c *gin.Context) {
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.Error(c, http.StatusBadRequest, "Invalid request")
+		utils.BindingError(c, err, "Invalid request")
 		return
 	}
 
 	if err := uh.iuserService.ChangePassword(userID.(string), req); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 
@@ -80,14 +80,14 @@ func (uh *UserHandler) UpdateUser(c *gin.Context) {
 	targetUUID := c.Param("uuid")
 	var user User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		utils.Error(c, http.StatusBadRequest, "Invalid user data")
+		utils.BindingError(c, err, "Invalid user data")
 		return
 	}
 	user.Sanitize()
 	user.UUID = targetUUID
 	updatedUser, err := uh.iuserService.UpdateUser(&user)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, updatedUser)
@@ -101,7 +101,7 @@ func (uh *UserHandler) GetUser(c *gin.Context) {
 	}
 	user, err := uh.iuserService.GetUserByUUID(uuid.(string))
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
@@ -111,7 +111,7 @@ func (uh *UserHandler) ListUsers(c *gin.Context) {
 	pagination := utils.GetPaginationParams(c)
 	users, total, err := uh.iuserService.ListUsers(pagination)
 	if err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, utils.GetPaginatedResponse(users, pagination.Page, pagination.Limit, total))
@@ -120,7 +120,7 @@ func (uh *UserHandler) ListUsers(c *gin.Context) {
 func (uh *UserHandler) DeleteUser(c *gin.Context) {
 	uuidStr := c.Param("uuid")
 	if err := uh.iuserService.DeleteUser(uuidStr); err != nil {
-		utils.Error(c, utils.StatusCodeFromError(err), err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User Deleted Successfully"})
@@ -1,38 +1,207 @@
 package utils
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
+// Machine-readable error codes, sent as "code" with every error response
+type ErrorCode string
+
+const (
+	CodeInvalidInput       ErrorCode = "invalid_input"
+	CodeValidationFailed   ErrorCode = "validation_failed" // The request body failed binding or a ValidationError; see "fields"
+	CodeUnauthenticated    ErrorCode = "unauthenticated"
+	CodeInvalidCredentials ErrorCode = "invalid_credentials"
+	CodeForbidden          ErrorCode = "forbidden"
+	CodeNotFound           ErrorCode = "not_found"
+	CodeConflict           ErrorCode = "conflict"
+	CodeInternal           ErrorCode = "internal_error"
+)
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Error   string       `json:"error"`
+	Code    ErrorCode    `json:"code"`
+	Message string       `json:"message,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`  // Which request fields failed validation
+	Details interface{}  `json:"details,omitempty"` // Typed payload of a DetailedError
+}
+
+// FieldError is one field of a request body that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError is invalid input whose problems are tied to fields of the request body, e.g. the
+// properties of a resource checked against its type's schema. It wraps ErrInvalidInput.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		problems = append(problems, f.Field+" "+f.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidInput, strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// DetailedError is an error that carries a typed payload for clients, e.g. the suggestions that come
+// with a slot conflict. RespondError sends it as "details".
+type DetailedError interface {
+	error
+	ErrorDetails() interface{}
+}
+
+// How each sentinel error is reported
+var errorMappings = []struct {
+	err    error
+	status int
+	code   ErrorCode
+}{
+	{ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	{ErrConflict, http.StatusConflict, CodeConflict},
+	{ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials},
+	{ErrUnauthorized, http.StatusForbidden, CodeForbidden},
+}
+
+func init() {
+	// Report validation failures by the JSON names clients send, not the Go field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" {
+				return f.Name
+			}
+			return name
+		})
+	}
+}
+
 // Standard error response
 func Error(c *gin.Context, status int, err string, details ...string) {
-	resp := gin.H{"error": err}
+	resp := ErrorResponse{Error: err, Code: CodeFromStatus(status)}
 
 	// If it's a 500 or detailed error, add the message
 	if len(details) > 0 {
-		resp["message"] = details[0]
+		resp.Message = details[0]
 	}
 	c.JSON(status, resp)
 }
 
+// RespondError writes the response for an error returned by a service: status and code follow the
+// sentinel it wraps, a ValidationError lists its fields and a DetailedError adds its payload.
+func RespondError(c *gin.Context, err error) {
+	resp := ErrorResponse{Error: err.Error(), Code: CodeFromError(err)}
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		resp.Code = CodeValidationFailed
+		resp.Fields = invalid.Fields
+	}
+	var detailed DetailedError
+	if errors.As(err, &detailed) {
+		resp.Details = detailed.ErrorDetails()
+	}
+	c.JSON(StatusCodeFromError(err), resp)
+}
+
+// BindingError writes the 400 for a request that failed ShouldBind, listing the offending fields.
+func BindingError(c *gin.Context, err error, msg string) {
+	resp := ErrorResponse{Error: msg, Code: CodeValidationFailed, Fields: fieldErrors(err)}
+	if len(resp.Fields) == 0 {
+		// Malformed JSON or a value that can't be parsed, e.g. a bad timestamp
+		resp.Message = err.Error()
+	}
+	c.JSON(http.StatusBadRequest, resp)
+}
+
 func StatusCodeFromError(err error) int {
-	if errors.Is(err, ErrNotFound) {
-		return http.StatusNotFound
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.status
+		}
 	}
-	if errors.Is(err, ErrInvalidInput) {
-		return http.StatusBadRequest
+	return http.StatusInternalServerError
+}
+
+func CodeFromError(err error) ErrorCode {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.code
+		}
 	}
-	if errors.Is(err, ErrConflict) {
-		return http.StatusConflict
+	return CodeInternal
+}
+
+func CodeFromStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidInput
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
 	}
-	if errors.Is(err, ErrInvalidCredentials) {
-		return http.StatusUnauthorized
+	return CodeInternal
+}
+
+func fieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			// Drop the struct name: "BundleCreate.resource_ids[1]" -> "resource_ids[1]"
+			field := fe.Namespace()
+			if i := strings.Index(field, "."); i >= 0 {
+				field = field[i+1:]
+			}
+			fields = append(fields, FieldError{Field: field, Rule: fe.Tag(), Message: ruleMessage(fe)})
+		}
+		return fields
 	}
-	if errors.Is(err, ErrUnauthorized) {
-		return http.StatusForbidden
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{Field: typeErr.Field, Rule: "type", Message: fmt.Sprintf("must be a %s", typeErr.Type)}}
 	}
-	return http.StatusInternalServerError
+	return nil
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "email":
+		return "must be a valid email address"
+	}
+	return fmt.Sprintf("failed the '%s' rule", fe.Tag())
 }
@@ -2,18 +2,78 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
-var publicHolidays = map[string]string{
-	"2026-01-26": "Republic Day",
-	"2026-08-15": "Independence Day",
-	"2026-10-02": "Gandhi Jayanti",
-	"2026-12-25": "Christmas",
+// DefaultTimeZone is the zone of resources whose location doesn't set one.
+const DefaultTimeZone = "Asia/Kolkata"
+
+// Holidays maps the public holidays of a calendar, by date ("2006-01-02"), to their names.
+// A nil Holidays has none, leaving only the weekend closed.
+type Holidays map[string]string
+
+// DayHours are the opening hours of one day as local "15:04" times; Close may be "24:00".
+type DayHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// WeeklyHours are opening hours by lower case weekday ("monday"). Days left out are closed,
+// which makes them the weekend; a half-day just closes early.
+type WeeklyHours map[string]DayHours
+
+// DefaultWeeklyHours is Monday to Friday, 9 AM - 5 PM.
+func DefaultWeeklyHours() WeeklyHours {
+	day := DayHours{Open: "09:00", Close: "17:00"}
+	return WeeklyHours{"monday": day, "tuesday": day, "wednesday": day, "thursday": day, "friday": day}
+}
+
+// Validate returns an ErrInvalidInput unless every day is a weekday name with an opening time
+// before its closing time.
+func (w WeeklyHours) Validate() error {
+	for day, hours := range w {
+		if _, ok := weekdayNames[day]; !ok {
+			return fmt.Errorf("%w: '%s' is not a day of the week (use monday ... sunday)", ErrInvalidInput, day)
+		}
+		open, errOpen := parseClock(hours.Open)
+		close, errClose := parseClock(hours.Close)
+		if errOpen != nil || errClose != nil {
+			return fmt.Errorf("%w: opening hours of %s must be HH:MM times", ErrInvalidInput, day)
+		}
+		if open >= close {
+			return fmt.Errorf("%w: %s must open before it closes", ErrInvalidInput, day)
+		}
+	}
+	return nil
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseClock reads "15:04" (or "24:00") as a duration after midnight.
+func parseClock(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IST is the zone of the default schedule.
+func IST() *time.Location {
+	return LoadZone(DefaultTimeZone)
 }
 
-func getIST() *time.Location {
-	loc, err := time.LoadLocation("Asia/Kolkata")
+// LoadZone returns the named IANA time zone, falling back to UTC if it can't be loaded.
+func LoadZone(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
 	if err != nil {
 		// Fallback to UTC if timezone db missing, though unlikely on standard linux
 		return time.UTC
@@ -21,35 +81,83 @@ func getIST() *time.Location {
 	return loc
 }
 
-func IsHoliday(t time.Time) error {
-	t = t.In(getIST())
+// Schedule is when a resource can be booked: the opening hours and holidays of its location,
+// in the location's time zone. Instants are compared as such; only the rules are local.
+type Schedule struct {
+	Zone     *time.Location
+	Hours    WeeklyHours
+	Holidays Holidays
+}
+
+// DefaultSchedule applies to resources without a location of their own: IST, Monday to Friday 9 AM - 5 PM.
+func DefaultSchedule() Schedule {
+	return Schedule{Zone: IST(), Hours: DefaultWeeklyHours()}
+}
+
+// Local returns t in the schedule's zone.
+func (s Schedule) Local(t time.Time) time.Time {
+	if s.Zone == nil {
+		return t.In(IST())
+	}
+	return t.In(s.Zone)
+}
+
+// Date is the local day containing t, as "2006-01-02"; holidays are keyed by it.
+func (s Schedule) Date(t time.Time) string {
+	return s.Local(t).Format("2006-01-02")
+}
+
+// IsHoliday returns why the local day containing t is closed: a weekend day or a public holiday.
+func (s Schedule) IsHoliday(t time.Time) error {
+	t = s.Local(t)
 	// 1. Weekend Check
-	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+	if _, open := s.Hours[strings.ToLower(t.Weekday().String())]; !open {
 		return errors.New("bookings are not allowed on weekends")
 	}
 	// 2. Public Holiday Check
-	dateStr := t.Format("2006-01-02")
-	if _, exists := publicHolidays[dateStr]; exists {
-		return errors.New("bookings are not allowed on public holidays")
+	if name, exists := s.Holidays[s.Date(t)]; exists {
+		return fmt.Errorf("bookings are not allowed on public holidays (%s)", name)
 	}
 	return nil
 }
 
-func IsWorkingHours(start time.Time, end time.Time) error {
-	loc := getIST()
-	start = start.In(loc)
-	end = end.In(loc)
-
-	// 9AM - 5PM check
-	if start.Hour() < 9 || start.Hour() >= 17 {
-		// Start must be 9:00 <= t < 17:00
-		return errors.New("start time must be between 9 AM and 5 PM")
+// WorkingHoursOn returns the opening hours of the local day containing t, and false when that day
+// is closed (a weekend day or a holiday).
+func (s Schedule) WorkingHoursOn(t time.Time) (time.Time, time.Time, bool) {
+	t = s.Local(t)
+	if s.IsHoliday(t) != nil {
+		return time.Time{}, time.Time{}, false
 	}
+	hours := s.Hours[strings.ToLower(t.Weekday().String())]
+	open, errOpen := parseClock(hours.Open)
+	close, errClose := parseClock(hours.Close)
+	if errOpen != nil || errClose != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	// Built from wall clock times, so days on which the clocks change keep their local hours
+	return wallClock(t, open), wallClock(t, close), true
+}
 
-	if end.Hour() < 9 || (end.Hour() >= 17 && (end.Minute() > 0 || end.Second() > 0)) {
-		// End must be <= 17:00
-		return errors.New("end time must be between 9 AM and 5 PM")
+// wallClock is the time of day (as a duration after midnight) on the day of t, in t's zone.
+func wallClock(t time.Time, after time.Duration) time.Time {
+	if after == 24*time.Hour {
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
 	}
+	h, m := int(after/time.Hour), int(after%time.Hour/time.Minute)
+	return time.Date(t.Year(), t.Month(), t.Day(), h, m, 0, 0, t.Location())
+}
 
+// IsWorkingHours returns an error unless [start, end) lies within the opening hours of the day it starts on.
+func (s Schedule) IsWorkingHours(start time.Time, end time.Time) error {
+	open, close, ok := s.WorkingHoursOn(start)
+	if !ok {
+		return s.IsHoliday(start)
+	}
+	if start.Before(open) || !start.Before(close) {
+		return fmt.Errorf("start time must be between %s and %s (%s time)", open.Format("15:04"), close.Format("15:04"), open.Location())
+	}
+	if end.After(close) {
+		return fmt.Errorf("end time must be between %s and %s (%s time)", open.Format("15:04"), close.Format("15:04"), open.Location())
+	}
 	return nil
 }
@@ -1,12 +1,17 @@
 package database
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/calendar"
+	"ResourceAllocator/internal/api/location"
+	"ResourceAllocator/internal/api/quota"
 	"ResourceAllocator/internal/api/resource"
 	"ResourceAllocator/internal/api/user"
 
@@ -57,9 +62,18 @@ func NewDB() (*DB, error) {
 	log.Println("Database connection established successfully")
 
 	// Auto-migrate tables
-	if err := db.AutoMigrate(&user.CreateUser{}, &resource.Resource{}, &resource.ResourceType{}, &booking.Booking{}); err != nil {
+	if err := db.AutoMigrate(&user.CreateUser{}, &resource.Resource{}, &resource.ResourceType{}, &booking.Booking{}, &booking.BookingAttendee{}, &booking.BookingSeries{}, &booking.BookingBundle{}, &booking.WaitlistEntry{}, &booking.BookingChange{}, &booking.BookingEvent{}, &quota.QuotaRule{}, &calendar.HolidayCalendar{}, &calendar.Holiday{}, &location.Location{}); err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate: %w", err)
 	}
+	if err := seedHolidayCalendar(db); err != nil {
+		return nil, fmt.Errorf("failed to seed holiday calendar: %w", err)
+	}
+	if err := migrateResourceLocations(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate resource locations: %w", err)
+	}
+	if err := migrateResourceProperties(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate resource properties: %w", err)
+	}
 
 	return &DB{conn: db}, nil
 }
@@ -67,3 +81,81 @@ func NewDB() (*DB, error) {
 func (d *DB) GetConnection() *gorm.DB {
 	return d.conn
 }
+
+// seedHolidayCalendar gives a database without holiday calendars a default one, holding the public
+// holidays that used to be built in.
+func seedHolidayCalendar(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&calendar.HolidayCalendar{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	return db.Create(&calendar.HolidayCalendar{
+		Name:        "India",
+		Description: "National public holidays",
+		IsDefault:   true,
+		Holidays: []calendar.Holiday{
+			{Date: "2026-01-26", Name: "Republic Day"},
+			{Date: "2026-08-15", Name: "Independence Day"},
+			{Date: "2026-10-02", Name: "Gandhi Jayanti"},
+			{Date: "2026-12-25", Name: "Christmas"},
+		},
+	}).Error
+}
+
+// migrateResourceLocations moves resources from free-text locations onto the location tree. Locations
+// from before the tree become buildings at its root, and so does every free-text location without one.
+func migrateResourceLocations(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&location.Location{}).Where("path IS NULL OR path = ''").Update("path", gorm.Expr("name")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&location.Location{}).Where("kind IS NULL OR kind = ''").Update("kind", location.KindBuilding).Error; err != nil {
+			return err
+		}
+
+		var paths []string
+		if err := tx.Model(&resource.Resource{}).
+			Where("location_id IS NULL AND location <> ''").
+			Distinct().Pluck("location", &paths).Error; err != nil {
+			return err
+		}
+		for _, path := range paths {
+			l := location.Location{Kind: location.KindBuilding, Name: path, Path: path}
+			if err := tx.Where("path = ?", path).FirstOrCreate(&l).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&resource.Resource{}).
+				Where("location_id IS NULL AND location = ?", path).
+				Update("location_id", l.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateResourceProperties stores the properties of resources typed by their type's schema, so that
+// numbers saved as strings before schemas were typed compare as numbers.
+func migrateResourceProperties(db *gorm.DB) error {
+	var resources []resource.Resource
+	if err := db.Preload("Type").Select("id", "type_id", "properties").Find(&resources).Error; err != nil {
+		return err
+	}
+	for _, res := range resources {
+		if res.Type == nil || len(res.Properties) == 0 {
+			continue
+		}
+		typed := res.Type.TypeProperties(res.Properties)
+		// Compared as stored: 10 read back is a float64, typed it is an int64
+		before, _ := json.Marshal(res.Properties)
+		after, _ := json.Marshal(typed)
+		if bytes.Equal(before, after) {
+			continue
+		}
+		if err := db.Model(&resource.Resource{ID: res.ID}).Select("properties").
+			Updates(&resource.Resource{Properties: typed}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
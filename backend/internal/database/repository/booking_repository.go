@@ -2,11 +2,15 @@ package repository
 
 import (
 	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
 	"ResourceAllocator/internal/api/utils"
+	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookingRepository struct {
@@ -23,7 +27,8 @@ func (r *BookingRepository) CreateBooking(b *booking.Booking) error {
 
 func (r *BookingRepository) GetBookingByID(id int) (*booking.Booking, error) {
 	var b booking.Booking
-	if err := r.db.Preload("Resource").Preload("User").First(&b, id).Error; err != nil {
+	// The resource's type carries the slot rules used when the booking is changed
+	if err := r.db.Preload("Resource.Type").Preload("User").Preload("Attendees").First(&b, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("%w: booking not found", utils.ErrNotFound)
 		}
@@ -32,25 +37,133 @@ func (r *BookingRepository) GetBookingByID(id int) (*booking.Booking, error) {
 	return &b, nil
 }
 
-// CRITICAL: Find conflicting approved bookings (To prevent double-booking)
-func (r *BookingRepository) HasApprovedOverlap(resourceID int, start, end time.Time) (bool, error) {
-	var count int64
-	err := r.db.Model(&booking.Booking{}).
-		Where("resource_id = ? AND status = ?", resourceID, booking.StatusApproved).
-		Where("start_time < ? AND end_time > ?", end, start). // Overlap Formula
-		Count(&count).Error
-	return count > 0, err
+// CRITICAL: Check approved (or in use) bookings leave room for quantity more units (To prevent double-booking)
+// For an ordinary resource (quantity 1) any overlap is a conflict.
+func (r *BookingRepository) HasApprovedOverlap(resourceID int, start, end time.Time, quantity int) (bool, error) {
+	return r.HasApprovedOverlapExcluding(resourceID, start, end, quantity, 0)
+}
+
+// HasApprovedOverlapExcluding is HasApprovedOverlap ignoring one booking (used when moving an existing booking).
+func (r *BookingRepository) HasApprovedOverlapExcluding(resourceID int, start, end time.Time, quantity, excludeID int) (bool, error) {
+	return hasApprovedOverlap(r.db, resourceID, start, end, quantity, excludeID)
+}
+
+func hasApprovedOverlap(tx *gorm.DB, resourceID int, start, end time.Time, quantity, excludeID int) (bool, error) {
+	res, err := loadResource(tx, resourceID)
+	if err != nil {
+		return false, err
+	}
+	capacity := res.Quantity
+	if capacity < 1 {
+		capacity = 1
+	}
+	// Bookings closer than the turnover time to the window collide with it too
+	turnover := res.Buffers().Turnover()
+
+	var overlapping []booking.Booking
+	err = tx.Select("id", "start_time", "end_time", "quantity").
+		Where("resource_id = ? AND status IN ? AND id != ?", resourceID, booking.OccupyingStatuses, excludeID).
+		Where("start_time < ? AND end_time > ?", end.Add(turnover), start.Add(-turnover)). // Overlap Formula
+		Find(&overlapping).Error
+	if err != nil {
+		return false, err
+	}
+	return booking.PeakQuantity(booking.WithTurnover(overlapping, turnover), start, end.Add(turnover))+quantity > capacity, nil
+}
+
+// loadResource fetches a resource with its type, which holds the defaults for buffers and slot rules.
+func loadResource(tx *gorm.DB, resourceID int) (*resource.Resource, error) {
+	var res resource.Resource
+	if err := tx.Preload("Type").First(&res, resourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: resource not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// lockResource takes a row lock on the resource so concurrent approvals for it are serialized.
+// Must run inside a transaction; callers locking several resources should lock them in id order.
+func lockResource(tx *gorm.DB, resourceID int) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&resource.Resource{}, resourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: resource not found", utils.ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *BookingRepository) GetResourceByID(id int) (*resource.Resource, error) {
+	return loadResource(r.db, id)
+}
+
+// GetResourcesByIDs returns the resources with their type's rules loaded, in id order.
+func (r *BookingRepository) GetResourcesByIDs(ids []int) ([]resource.Resource, error) {
+	var resources []resource.Resource
+	err := r.db.Preload("Type").Where("id IN ?", ids).Order("id asc").Find(&resources).Error
+	return resources, err
+}
+
+// GetBookingsInRange returns the bookings of the resources in the given statuses that overlap
+// [from, to), in start order.
+func (r *BookingRepository) GetBookingsInRange(resourceIDs []int, statuses []booking.BookingStatus, from, to time.Time) ([]booking.Booking, error) {
+	var bookings []booking.Booking
+	err := r.db.Where("resource_id IN ? AND status IN ?", resourceIDs, statuses).
+		Where("start_time < ? AND end_time > ?", to, from).
+		Order("start_time asc").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// GetActiveResourcesByType returns the active resources of a type with their type's rules loaded.
+func (r *BookingRepository) GetActiveResourcesByType(typeID int) ([]resource.Resource, error) {
+	var resources []resource.Resource
+	err := r.db.Preload("Type").
+		Where("type_id = ? AND is_active = ?", typeID, true).
+		Order("id asc").
+		Find(&resources).Error
+	return resources, err
 }
 
 // CRITICAL: Find conflicting PENDING bookings (For Auto-Rejection)
+// The resource's turnover time counts as part of the window.
 func (r *BookingRepository) GetPendingOverlaps(resourceID int, start, end time.Time) ([]booking.Booking, error) {
+	res, err := loadResource(r.db, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	turnover := res.Buffers().Turnover()
+
 	var bookings []booking.Booking
-	err := r.db.Where("resource_id = ? AND status = ?", resourceID, booking.StatusPending).
-		Where("start_time < ? AND end_time > ?", end, start).
+	err = r.db.Where("resource_id = ? AND status = ?", resourceID, booking.StatusPending).
+		Where("start_time < ? AND end_time > ?", end.Add(turnover), start.Add(-turnover)).
 		Find(&bookings).Error
 	return bookings, err
 }
 
+// UpdateBookingStatus writes a booking whose status changed together with the event recording it.
+func (r *BookingRepository) UpdateBookingStatus(b *booking.Booking, event *booking.BookingEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&booking.Booking{}).Where("id = ? AND status = ?", b.ID, event.FromStatus).Updates(b)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: booking is no longer %s", utils.ErrConflict, event.FromStatus)
+		}
+		return recordEvents(tx, *event)
+	})
+}
+
+// GetBookingEvents returns the status changes of a booking, oldest first.
+func (r *BookingRepository) GetBookingEvents(bookingID int) ([]booking.BookingEvent, error) {
+	var events []booking.BookingEvent
+	err := r.db.Where("booking_id = ?", bookingID).Order("created_at asc, id asc").Find(&events).Error
+	return events, err
+}
+
 func (r *BookingRepository) UpdateBooking(b *booking.Booking) error {
 	// This will only update fields that are non-zero in the struct
 	if err := r.db.First(&booking.Booking{}, b.ID).Error; err != nil {
@@ -59,65 +172,229 @@ func (r *BookingRepository) UpdateBooking(b *booking.Booking) error {
 	return r.db.Model(&booking.Booking{}).Where("id = ?", b.ID).Updates(b).Error
 }
 
+// Reasons stored on bookings rejected, released or cancelled automatically
+const (
+	reasonSlotAllocated  = "Slot allocated to another request"
+	reasonBundleConflict = "Another resource in this bundle was allocated to a different request"
+	reasonNoCheckIn      = "Auto-released due to no check-in"
+	reasonNotReviewed    = "Not seen by admin"
+)
+
+// recordEvents writes status changes of bookings, in the transaction that made them.
+func recordEvents(tx *gorm.DB, events ...booking.BookingEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
+
+// approver is the actor of an approval: the approving admin, or the system when nobody is recorded.
+func approver(approvedBy *string) string {
+	if approvedBy == nil || *approvedBy == "" {
+		return booking.ActorSystem
+	}
+	return *approvedBy
+}
+
 func (r *BookingRepository) ApproveBookingAndRejectConflicts(targetBooking *booking.Booking) ([]booking.Booking, error) {
 	var rejectedBookings []booking.Booking
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 
-		// Check if target booking exists
-		if err := tx.First(&booking.Booking{}, targetBooking.ID).Error; err != nil {
-			return fmt.Errorf("%w: booking not found", utils.ErrNotFound)
+		if err := lockResource(tx, targetBooking.ResourceID); err != nil {
+			return err
 		}
-
-		// 1. Approve Target
-		// Force update status (even if it was pending)
-		if err := tx.Model(&booking.Booking{}).
-			Where("id = ?", targetBooking.ID).
-			Updates(map[string]interface{}{
-				"status":           booking.StatusApproved,
-				"approved_by":      targetBooking.ApprovedBy,
-				"approved_at":      targetBooking.ApprovedAt,
-				"rejection_reason": nil, // Clear rejection reason if any
-			}).Error; err != nil {
+		// Re-read the booking under the lock: it may have been decided or cancelled meanwhile
+		var current booking.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, targetBooking.ID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("%w: booking not found", utils.ErrNotFound)
+			}
+			return err
+		}
+		if current.Status != booking.StatusPending {
+			return fmt.Errorf("%w: booking is %s, not pending", utils.ErrConflict, current.Status)
+		}
+		busy, err := hasApprovedOverlap(tx, targetBooking.ResourceID, targetBooking.StartTime, targetBooking.EndTime, targetBooking.Units(), targetBooking.ID)
+		if err != nil {
+			return err
+		}
+		if busy {
+			return fmt.Errorf("%w: slot is no longer available", utils.ErrConflict)
+		}
+		if err := recordEvents(tx, booking.BookingEvent{
+			BookingID:  targetBooking.ID,
+			FromStatus: current.Status,
+			ToStatus:   booking.StatusApproved,
+			Actor:      approver(targetBooking.ApprovedBy),
+		}); err != nil {
 			return err
 		}
 
-		// 2. Find Conflicts (Fetch Booking + User for Email)
-		// We explicitly fetch them first to get the User data
-		if err := tx.Preload("User").Preload("Resource").
-			Where("resource_id = ? AND status = ? AND id != ?", targetBooking.ResourceID, booking.StatusPending, targetBooking.ID).
-			Where("start_time < ? AND end_time > ?", targetBooking.EndTime, targetBooking.StartTime).
-			Find(&rejectedBookings).Error; err != nil {
+		rejected, err := approveAndRejectConflicts(tx, targetBooking)
+		rejectedBookings = rejected
+		return err
+	})
+
+	return rejectedBookings, err
+}
+
+// CreateApprovedBooking inserts a booking that is confirmed on creation (resources that don't
+// require approval). The resource row is locked so two instant bookings can't both take the slot.
+func (r *BookingRepository) CreateApprovedBooking(b *booking.Booking) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockResource(tx, b.ResourceID); err != nil {
+			return err
+		}
+		busy, err := hasApprovedOverlap(tx, b.ResourceID, b.StartTime, b.EndTime, b.Units(), 0)
+		if err != nil {
+			return err
+		}
+		if busy {
+			return fmt.Errorf("%w: slot unavailable", utils.ErrConflict)
+		}
+		if err := tx.Create(b).Error; err != nil {
 			return err
 		}
 
-		// 3. Reject Conflicts
-		if len(rejectedBookings) > 0 {
-			var ids []int
-			for _, b := range rejectedBookings {
-				ids = append(ids, b.ID)
-			}
+		rejected, err := approveAndRejectConflicts(tx, b)
+		rejectedBookings = rejected
+		return err
+	})
+	return rejectedBookings, err
+}
+
+// approveAndRejectConflicts approves the target booking and rejects every pending booking that
+// overlaps it on the same resource. Must run inside a transaction.
+// A rejected booking that belongs to a bundle takes the rest of its bundle down with it,
+// since bundle members are only ever approved together.
+func approveAndRejectConflicts(tx *gorm.DB, targetBooking *booking.Booking) ([]booking.Booking, error) {
+	// 1. Approve Target
+	// Force update status (even if it was pending)
+	if err := tx.Model(&booking.Booking{}).
+		Where("id = ?", targetBooking.ID).
+		Updates(map[string]interface{}{
+			"status":           booking.StatusApproved,
+			"approved_by":      targetBooking.ApprovedBy,
+			"approved_at":      targetBooking.ApprovedAt,
+			"rejection_reason": nil, // Clear rejection reason if any
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return rejectPendingOverlaps(tx, targetBooking)
+}
 
+// rejectPendingOverlaps rejects every pending booking that overlaps the target's window on the same
+// resource, along with the rest of any bundle that loses a member. Must run inside a transaction.
+func rejectPendingOverlaps(tx *gorm.DB, targetBooking *booking.Booking) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+
+	// 2. Find Conflicts (Fetch Booking + User for Email), counting the turnover buffers as occupied
+	// We explicitly fetch them first to get the User data
+	res, err := loadResource(tx, targetBooking.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	turnover := res.Buffers().Turnover()
+	var overlapping []booking.Booking
+	if err := tx.Preload("User").Preload("Resource").
+		Where("resource_id = ? AND status = ? AND id != ?", targetBooking.ResourceID, booking.StatusPending, targetBooking.ID).
+		Where("start_time < ? AND end_time > ?", targetBooking.EndTime.Add(turnover), targetBooking.StartTime.Add(-turnover)).
+		Find(&overlapping).Error; err != nil {
+		return nil, err
+	}
+	// On a pooled resource a pending request only conflicts once there aren't enough units left for it
+	for _, p := range overlapping {
+		busy, err := hasApprovedOverlap(tx, p.ResourceID, p.StartTime, p.EndTime, p.Units(), p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if busy {
+			rejectedBookings = append(rejectedBookings, p)
+		}
+	}
+	if len(rejectedBookings) == 0 {
+		return nil, nil
+	}
+
+	// 3. Reject Conflicts
+	var ids []int
+	var bundleIDs []int
+	for i := range rejectedBookings {
+		rejectedBookings[i].Status = booking.StatusRejected
+		rejectedBookings[i].RejectionReason = reasonSlotAllocated
+		ids = append(ids, rejectedBookings[i].ID)
+		if bid := rejectedBookings[i].BundleID; bid != nil && (targetBooking.BundleID == nil || *bid != *targetBooking.BundleID) {
+			bundleIDs = append(bundleIDs, *bid)
+		}
+	}
+
+	if err := tx.Model(&booking.Booking{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":           booking.StatusRejected,
+			"rejection_reason": reasonSlotAllocated,
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	// 4. Reject the remaining members of any bundle that just lost a member
+	if len(bundleIDs) > 0 {
+		var siblings []booking.Booking
+		if err := tx.Preload("User").Preload("Resource").
+			Where("bundle_id IN ? AND status = ? AND id NOT IN ?", bundleIDs, booking.StatusPending, ids).
+			Find(&siblings).Error; err != nil {
+			return nil, err
+		}
+		if len(siblings) > 0 {
+			var siblingIDs []int
+			for i := range siblings {
+				siblings[i].Status = booking.StatusRejected
+				siblings[i].RejectionReason = reasonBundleConflict
+				siblingIDs = append(siblingIDs, siblings[i].ID)
+			}
 			if err := tx.Model(&booking.Booking{}).
-				Where("id IN ?", ids).
+				Where("id IN ?", siblingIDs).
 				Updates(map[string]interface{}{
 					"status":           booking.StatusRejected,
-					"rejection_reason": "Slot allocated to another request",
+					"rejection_reason": reasonBundleConflict,
 				}).Error; err != nil {
-				return err
+				return nil, err
 			}
+			rejectedBookings = append(rejectedBookings, siblings...)
 		}
+		if err := tx.Model(&booking.BookingBundle{}).
+			Where("id IN ? AND status = ?", bundleIDs, booking.StatusPending).
+			Updates(map[string]interface{}{
+				"status":           booking.StatusRejected,
+				"rejection_reason": reasonBundleConflict,
+			}).Error; err != nil {
+			return nil, err
+		}
+	}
 
-		return nil
-	})
-
-	return rejectedBookings, err
+	events := make([]booking.BookingEvent, len(rejectedBookings))
+	for i, rb := range rejectedBookings {
+		events[i] = booking.BookingEvent{
+			BookingID:  rb.ID,
+			FromStatus: booking.StatusPending,
+			ToStatus:   booking.StatusRejected,
+			Actor:      booking.ActorSystem,
+			Reason:     fmt.Sprintf("%s (booking %d)", rb.RejectionReason, targetBooking.ID),
+		}
+	}
+	if err := recordEvents(tx, events...); err != nil {
+		return nil, err
+	}
+	return rejectedBookings, nil
 }
 
 func (r *BookingRepository) GetBookingsByUserID(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]booking.Booking, int64, error) {
 	var bookings []booking.Booking
 	var total int64
 
-	query := r.db.Model(&booking.Booking{}).Preload("Resource").Preload("User").Where("user_id = ?", userID)
+	query := r.db.Model(&booking.Booking{}).Preload("Resource").Preload("User").Preload("Attendees").Where("user_id = ?", userID)
 
 	// Apply Filters (Status, ResourceID)
 	if val, ok := filters["status"]; ok && val != "" {
@@ -146,7 +423,7 @@ func (r *BookingRepository) GetAllBookings(filters map[string]interface{}, pagin
 	var bookings []booking.Booking
 	var total int64
 
-	query := r.db.Model(&booking.Booking{}).Preload("Resource").Preload("User")
+	query := r.db.Model(&booking.Booking{}).Preload("Resource").Preload("User").Preload("Attendees")
 
 	// Apply Filters
 	for key, value := range filters {
@@ -170,57 +447,241 @@ func (r *BookingRepository) GetAllBookings(filters map[string]interface{}, pagin
 	return bookings, total, err
 }
 
+// GetBookingsByAttendee lists the bookings the user attends, newest first, with the same filters as
+// GetBookingsByUserID.
+func (r *BookingRepository) GetBookingsByAttendee(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]booking.Booking, int64, error) {
+	var bookings []booking.Booking
+	var total int64
+
+	attending := r.db.Model(&booking.BookingAttendee{}).Select("booking_id").Where("user_id = ?", userID)
+	query := r.db.Model(&booking.Booking{}).Preload("Resource").Preload("User").Preload("Attendees").Where("id IN (?)", attending)
+	if val, ok := filters["status"]; ok && val != "" {
+		query = query.Where("status = ?", val)
+	}
+	if val, ok := filters["resource_id"]; ok && val != "" {
+		query = query.Where("resource_id = ?", val)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("start_time desc").
+		Limit(pagination.Limit).
+		Offset(offset).
+		Find(&bookings).Error
+
+	return bookings, total, err
+}
+
+// GetUsersByUUIDs returns the users with the given UUIDs that exist.
+func (r *BookingRepository) GetUsersByUUIDs(uuids []string) ([]user.User, error) {
+	var users []user.User
+	err := r.db.Where("uuid IN ?", uuids).Find(&users).Error
+	return users, err
+}
+
 func (r *BookingRepository) GetFutureApprovedBookings(resourceID int, startTime time.Time) ([]booking.Booking, error) {
 	var bookings []booking.Booking
-	err := r.db.Preload("Resource").Preload("User").Where("resource_id = ? AND status = ? AND end_time > ?", resourceID, booking.StatusApproved, startTime).
+	err := r.db.Preload("Resource").Preload("User").Where("resource_id = ? AND status IN ? AND end_time > ?", resourceID, booking.OccupyingStatuses, startTime).
+		Order("start_time asc").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// GetFutureApprovedBookingsByUsers returns the approved (or in use) bookings the users made or
+// attend, on any resource, that end after startTime.
+func (r *BookingRepository) GetFutureApprovedBookingsByUsers(userIDs []string, startTime time.Time) ([]booking.Booking, error) {
+	var bookings []booking.Booking
+	attending := r.db.Model(&booking.BookingAttendee{}).Select("booking_id").Where("user_id IN ?", userIDs)
+	err := r.db.Where("(user_id IN ? OR id IN (?)) AND status IN ? AND end_time > ?", userIDs, attending, booking.OccupyingStatuses, startTime).
 		Order("start_time asc").
 		Find(&bookings).Error
 	return bookings, err
 }
 
-func (r *BookingRepository) CheckInBooking(bookingId int) error {
-	err := r.db.Model(&booking.Booking{}).Where("id = ?", bookingId).Updates(map[string]interface{}{
-		"status": booking.StatusUtilized,
-	}).Error
+func (r *BookingRepository) CheckInBooking(bookingId int, actor string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&booking.Booking{}).Where("id = ? AND status = ?", bookingId, booking.StatusApproved).Updates(map[string]interface{}{
+			"status":        booking.StatusUtilized,
+			"checked_in_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: booking is no longer approved", utils.ErrInvalidInput)
+		}
+		return recordEvents(tx, booking.BookingEvent{
+			BookingID:  bookingId,
+			FromStatus: booking.StatusApproved,
+			ToStatus:   booking.StatusUtilized,
+			Actor:      actor,
+		})
+	})
+}
 
-	return err
+// GetCheckInCandidate finds the approved booking on the resource whose check-in window
+// (start time to start time + window) contains now.
+func (r *BookingRepository) GetCheckInCandidate(resourceID int, now time.Time, window time.Duration) (*booking.Booking, error) {
+	var b booking.Booking
+	err := r.db.Preload("Resource").Preload("User").
+		Where("resource_id = ? AND status = ?", resourceID, booking.StatusApproved).
+		Where("start_time <= ? AND start_time >= ?", now, now.Add(-window)).
+		Order("start_time desc").
+		First(&b).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: no booking to check in on this resource right now", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &b, nil
 }
 
 // ReleaseUncheckedBookings: Updates bookings to RELEASED if they are APPROVED and start_time < cutoffTime.
-func (r *BookingRepository) ReleaseUncheckedBookings(cutoffTime time.Time) error {
-	// Find and Update in one query
+// Returns the released bookings so their remaining time can be offered to the waitlist. The rows are
+// locked, so a check-in racing the job either lands first (and the booking is skipped) or waits.
+func (r *BookingRepository) ReleaseUncheckedBookings(cutoffTime time.Time) ([]booking.Booking, error) {
+	var released []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status = ? AND start_time < ?", booking.StatusApproved, cutoffTime).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&released).Error; err != nil {
+			return err
+		}
+		if len(released) == 0 {
+			return nil
+		}
+		var ids []int
+		var events []booking.BookingEvent
+		for _, b := range released {
+			ids = append(ids, b.ID)
+			events = append(events, booking.BookingEvent{
+				BookingID:  b.ID,
+				FromStatus: booking.StatusApproved,
+				ToStatus:   booking.StatusReleased,
+				Actor:      booking.ActorAutoReleaseJob,
+				Reason:     reasonNoCheckIn,
+			})
+		}
+		if err := tx.Model(&booking.Booking{}).
+			Where("id IN ? AND status = ?", ids, booking.StatusApproved).
+			Updates(map[string]interface{}{
+				"status":           booking.StatusReleased,
+				"rejection_reason": reasonNoCheckIn,
+			}).Error; err != nil {
+			return err
+		}
+		return recordEvents(tx, events...)
+	})
+	return released, err
+}
+
+// ExtendBooking moves the end of a utilized booking to newEnd. The added window is re-checked under
+// a resource lock, and pending requests overlapping it are rejected and returned.
+func (r *BookingRepository) ExtendBooking(b *booking.Booking, newEnd time.Time) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockResource(tx, b.ResourceID); err != nil {
+			return err
+		}
+		busy, err := hasApprovedOverlap(tx, b.ResourceID, b.EndTime, newEnd, b.Units(), b.ID)
+		if err != nil {
+			return err
+		}
+		if busy {
+			return fmt.Errorf("%w: the following slot is already booked", utils.ErrConflict)
+		}
+
+		result := tx.Model(&booking.Booking{}).
+			Where("id = ? AND status = ? AND end_time = ? AND checked_out_at IS NULL", b.ID, booking.StatusUtilized, b.EndTime).
+			Update("end_time", newEnd)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: booking changed in the meantime, please retry", utils.ErrConflict)
+		}
+
+		added := *b
+		added.StartTime = b.EndTime
+		added.EndTime = newEnd
+		rejected, err := rejectPendingOverlaps(tx, &added)
+		rejectedBookings = rejected
+		return err
+	})
+	return rejectedBookings, err
+}
+
+// CheckOutBooking records the actual end of a utilized booking and shortens it to that time.
+func (r *BookingRepository) CheckOutBooking(bookingID int, checkedOutAt time.Time) error {
 	result := r.db.Model(&booking.Booking{}).
-		Where("status = ? AND start_time < ?", booking.StatusApproved, cutoffTime).
+		Where("id = ? AND status = ? AND checked_out_at IS NULL", bookingID, booking.StatusUtilized).
 		Updates(map[string]interface{}{
-			"status":           booking.StatusReleased,
-			"rejection_reason": "Auto-released due to no check-in",
+			"end_time":       checkedOutAt,
+			"checked_out_at": checkedOutAt,
 		})
-
 	if result.Error != nil {
 		return result.Error
 	}
-	// Ideally we log how many were released: result.RowsAffected
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: booking is not in use", utils.ErrInvalidInput)
+	}
 	return nil
 }
 
-// GetApprovedBookingsStartingAt finds bookings that started at a specific time and are still only 'APPROVED' (not Utilized)
-func (r *BookingRepository) GetApprovedBookingsStartingAt(startTime time.Time) ([]booking.Booking, error) {
+// GetApprovedBookingsStartingBetween finds bookings that started after from and no later than to and
+// are still only 'APPROVED' (not Utilized)
+func (r *BookingRepository) GetApprovedBookingsStartingBetween(from, to time.Time) ([]booking.Booking, error) {
 	var bookings []booking.Booking
 	// We need User data for the email address and Resource data for the name
-	err := r.db.Preload("User").Preload("Resource").
-		Where("status = ? AND start_time = ?", booking.StatusApproved, startTime).
+	err := r.db.Preload("User").Preload("Resource").Preload("Attendees").
+		Where("status = ? AND start_time > ? AND start_time <= ?", booking.StatusApproved, from, to).
 		Find(&bookings).Error
 	return bookings, err
 }
 
-func (r *BookingRepository) CancelExpiredPendingBookings(cutoffTime time.Time) error {
-	// Update pending bookings to cancelled if their start time has passed
-	return r.db.Model(&booking.Booking{}).
-		Where("status = ? AND start_time < ?", booking.StatusPending, cutoffTime).
-		Updates(map[string]interface{}{
-			"status":           booking.StatusCancelled,
-			"rejection_reason": "Not seen by admin",
-		}).Error
+// CancelExpiredPendingBookings cancels the pending bookings whose start time has passed and returns
+// them (with their owner, resource and attendees for the emails).
+func (r *BookingRepository) CancelExpiredPendingBookings(cutoffTime time.Time) ([]booking.Booking, error) {
+	var cancelled []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("User").Preload("Resource").Preload("Attendees").
+			Where("status = ? AND start_time < ?", booking.StatusPending, cutoffTime).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&cancelled).Error; err != nil {
+			return err
+		}
+		if len(cancelled) == 0 {
+			return nil
+		}
+		ids := make([]int, len(cancelled))
+		for i := range cancelled {
+			ids[i] = cancelled[i].ID
+			cancelled[i].RejectionReason = reasonNotReviewed
+		}
+		if err := tx.Model(&booking.Booking{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":           booking.StatusCancelled,
+				"rejection_reason": reasonNotReviewed,
+			}).Error; err != nil {
+			return err
+		}
+		events := make([]booking.BookingEvent, len(ids))
+		for i, id := range ids {
+			events[i] = booking.BookingEvent{
+				BookingID:  id,
+				FromStatus: booking.StatusPending,
+				ToStatus:   booking.StatusCancelled,
+				Actor:      booking.ActorAutoCancelJob,
+				Reason:     reasonNotReviewed,
+			}
+		}
+		return recordEvents(tx, events...)
+	})
+	return cancelled, err
 }
 
 func (r *BookingRepository) GetTopBookedResources(limit int) ([]booking.DashboardResourceStat, error) {
@@ -253,3 +714,607 @@ func (r *BookingRepository) GetTopReleasingUsers(limit int) ([]booking.Dashboard
 		Scan(&stats).Error
 	return stats, err
 }
+
+// CreateBookingSeries inserts the series parent and all of its occurrences in one transaction.
+// Occurrences that arrive already approved (instant confirmation) are checked against approved
+// bookings under a resource lock and reject the pending requests they overlap; those are returned.
+func (r *BookingRepository) CreateBookingSeries(series *booking.BookingSeries, bookings []booking.Booking) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockResource(tx, series.ResourceID); err != nil {
+			return err
+		}
+		for _, b := range bookings {
+			if b.Status != booking.StatusApproved {
+				continue
+			}
+			busy, err := hasApprovedOverlap(tx, b.ResourceID, b.StartTime, b.EndTime, b.Units(), 0)
+			if err != nil {
+				return err
+			}
+			if busy {
+				return fmt.Errorf("%w: slot unavailable for occurrence at %s", utils.ErrConflict, b.StartTime.Format(time.RFC3339))
+			}
+		}
+
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		for i := range bookings {
+			bookings[i].SeriesID = &series.ID
+		}
+		if err := tx.Create(&bookings).Error; err != nil {
+			return err
+		}
+
+		for i := range bookings {
+			if bookings[i].Status != booking.StatusApproved {
+				continue
+			}
+			rejected, err := approveAndRejectConflicts(tx, &bookings[i])
+			if err != nil {
+				return err
+			}
+			rejectedBookings = append(rejectedBookings, rejected...)
+		}
+		return nil
+	})
+	return rejectedBookings, err
+}
+
+func (r *BookingRepository) GetSeriesByID(id int) (*booking.BookingSeries, error) {
+	var series booking.BookingSeries
+	if err := r.db.First(&series, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: booking series not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *BookingRepository) UpdateSeries(series *booking.BookingSeries) error {
+	return r.db.Save(series).Error
+}
+
+func (r *BookingRepository) GetBookingsBySeriesID(seriesID int) ([]booking.Booking, error) {
+	var bookings []booking.Booking
+	err := r.db.Preload("Resource").Preload("User").Preload("Attendees").
+		Where("series_id = ?", seriesID).
+		Order("start_time asc").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// UpdateBookingsStatus moves those of several bookings that are still in one of the from statuses
+// to the same status and records an event by the actor for each of them, in one transaction. The
+// rows are locked while they change; the moved bookings are returned as they were before.
+func (r *BookingRepository) UpdateBookingsStatus(ids []int, from []booking.BookingStatus, status booking.BookingStatus, reason, actor string) ([]booking.Booking, error) {
+	var bookings []booking.Booking
+	if len(ids) == 0 || len(from) == 0 {
+		return bookings, nil
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("User").Preload("Resource").Preload("Attendees").
+			Where("id IN ? AND status IN ?", ids, from).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&bookings).Error; err != nil {
+			return err
+		}
+		if len(bookings) == 0 {
+			return nil
+		}
+		moved := make([]int, len(bookings))
+		events := make([]booking.BookingEvent, len(bookings))
+		for i, b := range bookings {
+			moved[i] = b.ID
+			events[i] = booking.BookingEvent{BookingID: b.ID, FromStatus: b.Status, ToStatus: status, Actor: actor, Reason: reason}
+		}
+		if err := tx.Model(&booking.Booking{}).
+			Where("id IN ?", moved).
+			Updates(map[string]interface{}{
+				"status":           status,
+				"rejection_reason": reason,
+			}).Error; err != nil {
+			return err
+		}
+		return recordEvents(tx, events...)
+	})
+	return bookings, err
+}
+
+// UpdateBookingSchedules writes the window, purpose and approval fields of several bookings,
+// including zero values (e.g. clearing approved_by when a booking goes back to pending), together
+// with the events of the ones whose status changed. Either all of them are written or none is.
+// Bookings that are approved afterwards are re-checked under their resource's lock, and the pending
+// requests overlapping them are rejected and returned.
+func (r *BookingRepository) UpdateBookingSchedules(bookings []booking.Booking, events []booking.BookingEvent) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		locked := make(map[int]bool)
+		for i := range bookings {
+			b := &bookings[i]
+			if b.Status == booking.StatusApproved && !locked[b.ResourceID] {
+				if err := lockResource(tx, b.ResourceID); err != nil {
+					return err
+				}
+				locked[b.ResourceID] = true
+			}
+		}
+		for i := range bookings {
+			b := &bookings[i]
+			result := tx.Model(&booking.Booking{}).
+				Where("id = ?", b.ID).
+				Select("resource_id", "start_time", "end_time", "purpose", "status", "approved_by", "approved_at").
+				Updates(b)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("%w: booking %d not found", utils.ErrNotFound, b.ID)
+			}
+		}
+		for i := range bookings {
+			b := &bookings[i]
+			if b.Status != booking.StatusApproved {
+				continue
+			}
+			busy, err := hasApprovedOverlap(tx, b.ResourceID, b.StartTime, b.EndTime, b.Units(), b.ID)
+			if err != nil {
+				return err
+			}
+			if busy {
+				return fmt.Errorf("%w: slot for booking %d is no longer available", utils.ErrConflict, b.ID)
+			}
+			rejected, err := rejectPendingOverlaps(tx, b)
+			if err != nil {
+				return err
+			}
+			rejectedBookings = append(rejectedBookings, rejected...)
+		}
+		return recordEvents(tx, events...)
+	})
+	return rejectedBookings, err
+}
+
+// RescheduleBooking writes the new resource/window/purpose/status of a booking and its change record
+// in one transaction, along with event if the status changed. The target resource is locked and
+// re-checked; if the booking stays approved, pending requests overlapping its new window are
+// rejected and returned.
+func (r *BookingRepository) RescheduleBooking(b *booking.Booking, change *booking.BookingChange, event *booking.BookingEvent) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockResource(tx, b.ResourceID); err != nil {
+			return err
+		}
+		busy, err := hasApprovedOverlap(tx, b.ResourceID, b.StartTime, b.EndTime, b.Units(), b.ID)
+		if err != nil {
+			return err
+		}
+		if busy {
+			return fmt.Errorf("%w: slot unavailable", utils.ErrConflict)
+		}
+
+		result := tx.Model(&booking.Booking{}).
+			Where("id = ? AND status IN ?", b.ID, []booking.BookingStatus{booking.StatusPending, booking.StatusApproved}).
+			Select("resource_id", "start_time", "end_time", "purpose", "status", "approved_by", "approved_at").
+			Updates(b)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: booking is no longer pending or approved", utils.ErrInvalidInput)
+		}
+		if err := tx.Create(change).Error; err != nil {
+			return err
+		}
+		if event != nil {
+			if err := recordEvents(tx, *event); err != nil {
+				return err
+			}
+		}
+
+		if b.Status != booking.StatusApproved {
+			return nil
+		}
+		rejected, err := approveAndRejectConflicts(tx, b)
+		rejectedBookings = rejected
+		return err
+	})
+	return rejectedBookings, err
+}
+
+// CreateBundle inserts the bundle and its member bookings in one transaction.
+// Availability is re-checked under resource locks so two racing bundles can't both be created
+// on top of an approved booking. An instantly confirmed bundle also rejects the pending requests
+// its members overlap; those are returned.
+func (r *BookingRepository) CreateBundle(bundle *booking.BookingBundle, bookings []booking.Booking) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Members arrive sorted by resource id, which keeps the lock order consistent
+		for _, b := range bookings {
+			if err := lockResource(tx, b.ResourceID); err != nil {
+				return err
+			}
+			busy, err := hasApprovedOverlap(tx, b.ResourceID, b.StartTime, b.EndTime, b.Units(), 0)
+			if err != nil {
+				return err
+			}
+			if busy {
+				return fmt.Errorf("%w: slot unavailable for resource %d", utils.ErrConflict, b.ResourceID)
+			}
+		}
+		if err := tx.Create(bundle).Error; err != nil {
+			return err
+		}
+		for i := range bookings {
+			bookings[i].BundleID = &bundle.ID
+		}
+		if err := tx.Create(&bookings).Error; err != nil {
+			return err
+		}
+
+		if bundle.Status != booking.StatusApproved {
+			return nil
+		}
+		for i := range bookings {
+			rejected, err := approveAndRejectConflicts(tx, &bookings[i])
+			if err != nil {
+				return err
+			}
+			rejectedBookings = append(rejectedBookings, rejected...)
+		}
+		return nil
+	})
+	return rejectedBookings, err
+}
+
+func (r *BookingRepository) GetBundleByID(id int) (*booking.BookingBundle, error) {
+	var bundle booking.BookingBundle
+	if err := r.db.First(&bundle, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: booking bundle not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (r *BookingRepository) GetBookingsByBundleID(bundleID int) ([]booking.Booking, error) {
+	var bookings []booking.Booking
+	err := r.db.Preload("Resource").Preload("User").
+		Where("bundle_id = ?", bundleID).
+		Order("resource_id asc").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// ApproveBundleAndRejectConflicts approves every member of the bundle in a single transaction,
+// running the same conflict rejection as a single approval for each member.
+// If any member's slot has been approved for someone else in the meantime, nothing is approved.
+func (r *BookingRepository) ApproveBundleAndRejectConflicts(bundle *booking.BookingBundle) ([]booking.Booking, error) {
+	var rejectedBookings []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var members []booking.Booking
+		if err := tx.Where("bundle_id = ?", bundle.ID).Order("resource_id asc").Find(&members).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return fmt.Errorf("%w: booking bundle has no members", utils.ErrNotFound)
+		}
+
+		for i := range members {
+			m := members[i]
+			if m.Status != booking.StatusPending {
+				return fmt.Errorf("%w: bundle member %d is %s", utils.ErrInvalidInput, m.ID, m.Status)
+			}
+			if err := lockResource(tx, m.ResourceID); err != nil {
+				return err
+			}
+			busy, err := hasApprovedOverlap(tx, m.ResourceID, m.StartTime, m.EndTime, m.Units(), m.ID)
+			if err != nil {
+				return err
+			}
+			if busy {
+				return fmt.Errorf("%w: slot for resource %d is no longer available", utils.ErrConflict, m.ResourceID)
+			}
+
+			m.ApprovedBy = bundle.ApprovedBy
+			m.ApprovedAt = bundle.ApprovedAt
+			if err := recordEvents(tx, booking.BookingEvent{
+				BookingID:  m.ID,
+				FromStatus: booking.StatusPending,
+				ToStatus:   booking.StatusApproved,
+				Actor:      approver(bundle.ApprovedBy),
+				Reason:     fmt.Sprintf("Bundle %d approved", bundle.ID),
+			}); err != nil {
+				return err
+			}
+			rejected, err := approveAndRejectConflicts(tx, &m)
+			if err != nil {
+				return err
+			}
+			rejectedBookings = append(rejectedBookings, rejected...)
+		}
+
+		return tx.Model(&booking.BookingBundle{}).
+			Where("id = ?", bundle.ID).
+			Updates(map[string]interface{}{
+				"status":      booking.StatusApproved,
+				"approved_by": bundle.ApprovedBy,
+				"approved_at": bundle.ApprovedAt,
+			}).Error
+	})
+	return rejectedBookings, err
+}
+
+// UpdateBundleStatus writes the bundle's status and applies it to every member that is still active,
+// recording an event by the actor for each member it changes.
+func (r *BookingRepository) UpdateBundleStatus(bundle *booking.BookingBundle, actor, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&booking.BookingBundle{}).
+			Where("id = ?", bundle.ID).
+			Updates(map[string]interface{}{
+				"status":           bundle.Status,
+				"approved_by":      bundle.ApprovedBy,
+				"approved_at":      bundle.ApprovedAt,
+				"rejection_reason": bundle.RejectionReason,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: booking bundle not found", utils.ErrNotFound)
+		}
+		var members []booking.Booking
+		if err := tx.Where("bundle_id = ? AND status IN ?", bundle.ID, []booking.BookingStatus{booking.StatusPending, booking.StatusApproved}).
+			Find(&members).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		events := make([]booking.BookingEvent, len(members))
+		ids := make([]int, len(members))
+		for i, m := range members {
+			ids[i] = m.ID
+			events[i] = booking.BookingEvent{BookingID: m.ID, FromStatus: m.Status, ToStatus: bundle.Status, Actor: actor, Reason: reason}
+		}
+		if err := tx.Model(&booking.Booking{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":           bundle.Status,
+				"rejection_reason": bundle.RejectionReason,
+			}).Error; err != nil {
+			return err
+		}
+		return recordEvents(tx, events...)
+	})
+}
+
+func (r *BookingRepository) CreateWaitlistEntry(entry *booking.WaitlistEntry) error {
+	var count int64
+	if err := r.db.Model(&booking.WaitlistEntry{}).
+		Where("user_id = ? AND resource_id = ? AND status = ?", entry.UserID, entry.ResourceID, booking.WaitlistWaiting).
+		Where("start_time = ? AND end_time = ?", entry.StartTime, entry.EndTime).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: you are already on the waitlist for this slot", utils.ErrConflict)
+	}
+	return r.db.Create(entry).Error
+}
+
+func (r *BookingRepository) GetWaitlistEntryByID(id int) (*booking.WaitlistEntry, error) {
+	var entry booking.WaitlistEntry
+	if err := r.db.Preload("Resource").Preload("User").First(&entry, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: waitlist entry not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *BookingRepository) GetWaitlistEntriesByUserID(userID string, pagination utils.PaginationQuery) ([]booking.WaitlistEntry, int64, error) {
+	var entries []booking.WaitlistEntry
+	var total int64
+
+	query := r.db.Model(&booking.WaitlistEntry{}).Preload("Resource").Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("created_at desc").
+		Limit(pagination.Limit).
+		Offset(offset).
+		Find(&entries).Error
+
+	return entries, total, err
+}
+
+func (r *BookingRepository) UpdateWaitlistEntry(entry *booking.WaitlistEntry) error {
+	return r.db.Model(&booking.WaitlistEntry{}).
+		Where("id = ?", entry.ID).
+		Updates(map[string]interface{}{
+			"status":     entry.Status,
+			"booking_id": entry.BookingID,
+		}).Error
+}
+
+// GetWaitingEntries returns live entries on the resource whose window overlaps [start, end) and
+// hasn't ended yet, oldest first (FIFO). The resource comes with its type, for the turnover time.
+func (r *BookingRepository) GetWaitingEntries(resourceID int, start, end, now time.Time) ([]booking.WaitlistEntry, error) {
+	var entries []booking.WaitlistEntry
+	err := r.db.Preload("Resource.Type").Preload("User").
+		Where("resource_id = ? AND status = ?", resourceID, booking.WaitlistWaiting).
+		Where("start_time < ? AND end_time > ?", end, start).
+		Where("expires_at > ? AND end_time > ?", now, now).
+		Order("created_at asc, id asc").
+		Find(&entries).Error
+	return entries, err
+}
+
+// PromoteWaitlistEntry creates the booking for a waitlist entry and marks the entry promoted in one transaction.
+// The entry is only promoted if it is still waiting, so two concurrent promotions can't both win.
+func (r *BookingRepository) PromoteWaitlistEntry(entry *booking.WaitlistEntry, b *booking.Booking) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(b).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&booking.WaitlistEntry{}).
+			Where("id = ? AND status = ?", entry.ID, booking.WaitlistWaiting).
+			Updates(map[string]interface{}{
+				"status":     booking.WaitlistPromoted,
+				"booking_id": b.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: waitlist entry is no longer waiting", utils.ErrConflict)
+		}
+		entry.Status = booking.WaitlistPromoted
+		entry.BookingID = &b.ID
+		return nil
+	})
+}
+
+func (r *BookingRepository) ExpireWaitlistEntries(now time.Time) error {
+	return r.db.Model(&booking.WaitlistEntry{}).
+		Where("status = ? AND expires_at <= ?", booking.WaitlistWaiting, now).
+		Update("status", booking.WaitlistExpired).Error
+}
+
+// GetPendingForAllocation returns the future pending requests overlapping [from, to) on one resource,
+// or on every resource of a type, grouped by resource in start order.
+func (r *BookingRepository) GetPendingForAllocation(resourceID, resourceTypeID *int, from, to time.Time) ([]booking.Booking, error) {
+	query := r.db.Preload("Resource.Type").Preload("User").
+		Where("bookings.status = ? AND bookings.start_time > ?", booking.StatusPending, time.Now()).
+		Where("bookings.start_time < ? AND bookings.end_time > ?", to, from)
+	if resourceID != nil {
+		query = query.Where("bookings.resource_id = ?", *resourceID)
+	}
+	if resourceTypeID != nil {
+		query = query.Joins("JOIN resources ON resources.id = bookings.resource_id").
+			Where("resources.type_id = ?", *resourceTypeID)
+	}
+
+	var bookings []booking.Booking
+	err := query.Order("bookings.resource_id asc, bookings.start_time asc").Find(&bookings).Error
+	return bookings, err
+}
+
+// CommitAllocation applies a batch allocation plan in one transaction. Every request in the plan must
+// still be pending; each approval is re-checked under the resource lock and rejects whatever pending
+// requests it conflicts with, as a single approval does. The rest of the plan's rejections follow.
+func (r *BookingRepository) CommitAllocation(approveIDs, rejectIDs []int, approverID string, now time.Time) ([]booking.Booking, []booking.Booking, error) {
+	var approved, rejected []booking.Booking
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids := append(append([]int{}, approveIDs...), rejectIDs...)
+
+		var resourceIDs []int
+		if err := tx.Model(&booking.Booking{}).Where("id IN ?", ids).
+			Distinct().Order("resource_id asc").Pluck("resource_id", &resourceIDs).Error; err != nil {
+			return err
+		}
+		for _, id := range resourceIDs {
+			if err := lockResource(tx, id); err != nil {
+				return err
+			}
+		}
+
+		var bookings []booking.Booking
+		if err := tx.Preload("User").Preload("Resource").Preload("Attendees").Where("id IN ?", ids).Find(&bookings).Error; err != nil {
+			return err
+		}
+		if len(bookings) != len(ids) {
+			return fmt.Errorf("%w: some bookings in the plan no longer exist", utils.ErrNotFound)
+		}
+		byID := make(map[int]*booking.Booking, len(bookings))
+		for i := range bookings {
+			b := &bookings[i]
+			if b.Status != booking.StatusPending || b.BundleID != nil {
+				return fmt.Errorf("%w: booking %d is no longer pending, preview the allocation again", utils.ErrConflict, b.ID)
+			}
+			byID[b.ID] = b
+		}
+
+		decided := make(map[int]bool)
+		for _, id := range approveIDs {
+			b := byID[id]
+			if decided[id] {
+				return fmt.Errorf("%w: booking %d conflicts with another approval in the plan", utils.ErrConflict, id)
+			}
+			busy, err := hasApprovedOverlap(tx, b.ResourceID, b.StartTime, b.EndTime, b.Units(), b.ID)
+			if err != nil {
+				return err
+			}
+			if busy {
+				return fmt.Errorf("%w: slot for booking %d is no longer available, preview the allocation again", utils.ErrConflict, id)
+			}
+
+			b.Status = booking.StatusApproved
+			b.ApprovedBy = &approverID
+			b.ApprovedAt = &now
+			if err := recordEvents(tx, booking.BookingEvent{
+				BookingID:  b.ID,
+				FromStatus: booking.StatusPending,
+				ToStatus:   booking.StatusApproved,
+				Actor:      approverID,
+				Reason:     "Batch allocation",
+			}); err != nil {
+				return err
+			}
+			lost, err := approveAndRejectConflicts(tx, b)
+			if err != nil {
+				return err
+			}
+			for _, l := range lost {
+				decided[l.ID] = true
+			}
+			decided[id] = true
+			approved = append(approved, *b)
+			rejected = append(rejected, lost...)
+		}
+
+		var remaining []int
+		var events []booking.BookingEvent
+		for _, id := range rejectIDs {
+			if decided[id] {
+				continue
+			}
+			b := byID[id]
+			b.Status = booking.StatusRejected
+			b.RejectionReason = reasonSlotAllocated
+			b.ApprovedBy = &approverID
+			b.ApprovedAt = &now
+			remaining = append(remaining, id)
+			rejected = append(rejected, *b)
+			events = append(events, booking.BookingEvent{
+				BookingID:  id,
+				FromStatus: booking.StatusPending,
+				ToStatus:   booking.StatusRejected,
+				Actor:      approverID,
+				Reason:     reasonSlotAllocated,
+			})
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		if err := tx.Model(&booking.Booking{}).
+			Where("id IN ?", remaining).
+			Updates(map[string]interface{}{
+				"status":           booking.StatusRejected,
+				"rejection_reason": reasonSlotAllocated,
+				"approved_by":      approverID,
+				"approved_at":      now,
+			}).Error; err != nil {
+			return err
+		}
+		return recordEvents(tx, events...)
+	})
+	return approved, rejected, err
+}
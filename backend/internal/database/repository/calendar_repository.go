@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"ResourceAllocator/internal/api/calendar"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// clearOtherDefaults keeps cal the only default calendar.
+func clearOtherDefaults(tx *gorm.DB, cal *calendar.HolidayCalendar) error {
+	if !cal.IsDefault {
+		return nil
+	}
+	return tx.Model(&calendar.HolidayCalendar{}).
+		Where("id <> ? AND is_default", cal.ID).
+		Update("is_default", false).Error
+}
+
+func (r *CalendarRepository) CreateCalendar(cal *calendar.HolidayCalendar) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cal).Error; err != nil {
+			return err
+		}
+		return clearOtherDefaults(tx, cal)
+	})
+	if utils.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: holiday calendar already exists", utils.ErrConflict)
+	}
+	return err
+}
+
+// GetCalendarByID returns the calendar with its holidays in date order.
+func (r *CalendarRepository) GetCalendarByID(id int) (*calendar.HolidayCalendar, error) {
+	var cal calendar.HolidayCalendar
+	err := r.db.Preload("Holidays", func(db *gorm.DB) *gorm.DB { return db.Order("date asc") }).
+		First(&cal, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: holiday calendar not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &cal, nil
+}
+
+func (r *CalendarRepository) GetAllCalendars(pagination utils.PaginationQuery) ([]calendar.HolidayCalendar, int64, error) {
+	var calendars []calendar.HolidayCalendar
+	var total int64
+
+	query := r.db.Model(&calendar.HolidayCalendar{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("id asc").
+		Limit(pagination.Limit).
+		Offset(offset).
+		Find(&calendars).Error
+
+	return calendars, total, err
+}
+
+// GetCalendars returns every calendar, without holidays, for checking which locations are taken.
+func (r *CalendarRepository) GetCalendars() ([]calendar.HolidayCalendar, error) {
+	var calendars []calendar.HolidayCalendar
+	err := r.db.Order("id asc").Find(&calendars).Error
+	return calendars, err
+}
+
+func (r *CalendarRepository) UpdateCalendar(cal *calendar.HolidayCalendar) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Holidays", "CreatedAt").Save(cal).Error; err != nil {
+			return err
+		}
+		return clearOtherDefaults(tx, cal)
+	})
+	if utils.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: holiday calendar already exists", utils.ErrConflict)
+	}
+	return err
+}
+
+// DeleteCalendar removes the calendar and its holidays; its locations fall back to the default.
+func (r *CalendarRepository) DeleteCalendar(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("calendar_id = ?", id).Delete(&calendar.Holiday{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&calendar.HolidayCalendar{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: holiday calendar not found", utils.ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (r *CalendarRepository) AddHoliday(holiday *calendar.Holiday) error {
+	if err := r.db.Create(holiday).Error; err != nil {
+		if utils.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: the calendar already has a holiday on %s", utils.ErrConflict, holiday.Date)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *CalendarRepository) DeleteHoliday(calendarID, holidayID int) error {
+	result := r.db.Where("id = ? AND calendar_id = ?", holidayID, calendarID).Delete(&calendar.Holiday{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: holiday not found", utils.ErrNotFound)
+	}
+	return nil
+}
+
+// UpsertHolidays adds the holidays whose dates the calendar doesn't have yet and renames the ones
+// it has under another name, in one transaction.
+func (r *CalendarRepository) UpsertHolidays(calendarID int, holidays []calendar.Holiday) (int, int, error) {
+	added, updated := 0, 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		dates := make([]string, len(holidays))
+		for i, h := range holidays {
+			dates[i] = h.Date
+		}
+		var existing []calendar.Holiday
+		if err := tx.Where("calendar_id = ? AND date IN ?", calendarID, dates).Find(&existing).Error; err != nil {
+			return err
+		}
+		byDate := make(map[string]calendar.Holiday, len(existing))
+		for _, h := range existing {
+			byDate[h.Date] = h
+		}
+
+		var fresh []calendar.Holiday
+		for _, h := range holidays {
+			current, ok := byDate[h.Date]
+			if !ok {
+				fresh = append(fresh, h)
+				continue
+			}
+			if current.Name != h.Name {
+				if err := tx.Model(&current).Update("name", h.Name).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+		}
+		if len(fresh) > 0 {
+			if err := tx.Create(&fresh).Error; err != nil {
+				return err
+			}
+		}
+		added = len(fresh)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return added, updated, nil
+}
+
+// GetCalendarForLocation returns the calendar listing the location (a path) or the nearest location
+// above it, else the default calendar, else nil.
+func (r *CalendarRepository) GetCalendarForLocation(location string) (*calendar.HolidayCalendar, error) {
+	var calendars []calendar.HolidayCalendar
+	query := r.db.Where("is_default").Order("id asc")
+	if location != "" {
+		// The longest listed path the location is at or inside is the nearest; it beats the default
+		query = r.db.Select("holiday_calendars.*").
+			Joins(`CROSS JOIN LATERAL (
+				SELECT max(length(v)) AS depth FROM jsonb_array_elements_text(holiday_calendars.locations) AS v
+				WHERE v = ? OR left(CAST(? AS text), length(v) + 3) = v || ' / '
+			) m`, location, location).
+			Where("m.depth IS NOT NULL OR is_default").
+			Order("m.depth DESC NULLS LAST, id asc")
+	}
+	if err := query.Limit(1).Find(&calendars).Error; err != nil {
+		return nil, err
+	}
+	if len(calendars) == 0 {
+		return nil, nil
+	}
+	return &calendars[0], nil
+}
+
+// GetHolidays returns the calendar's holidays from fromDate to toDate, both included.
+func (r *CalendarRepository) GetHolidays(calendarID int, fromDate, toDate string) ([]calendar.Holiday, error) {
+	var holidays []calendar.Holiday
+	err := r.db.Where("calendar_id = ? AND date >= ? AND date <= ?", calendarID, fromDate, toDate).
+		Order("date asc").
+		Find(&holidays).Error
+	return holidays, err
+}
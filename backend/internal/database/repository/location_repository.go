@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"ResourceAllocator/internal/api/calendar"
+	"ResourceAllocator/internal/api/location"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type LocationRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationRepository(db *gorm.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// underPathSQL matches a location path that is a given one or lies below it. Args: path x3.
+const underPathSQL = `(%[1]s = ? OR left(%[1]s, length(CAST(? AS text)) + 3) = CAST(? AS text) || ' / ')`
+
+// repathSQL swaps the leading old path of a location path for the new one. Args: new path, old path.
+const repathSQL = `CAST(? AS text) || substr(%s, length(CAST(? AS text)) + 1)`
+
+func (r *LocationRepository) CreateLocation(l *location.Location) error {
+	if err := r.db.Create(l).Error; err != nil {
+		if utils.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: location '%s' already exists", utils.ErrConflict, l.Path)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *LocationRepository) GetLocationByID(id int) (*location.Location, error) {
+	var l location.Location
+	if err := r.db.First(&l, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: location not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LocationRepository) GetLocationByPath(path string) (*location.Location, error) {
+	var l location.Location
+	if err := r.db.Where("path = ?", path).First(&l).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: location not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LocationRepository) GetAllLocations(pagination utils.PaginationQuery) ([]location.Location, int64, error) {
+	var locations []location.Location
+	var total int64
+
+	query := r.db.Model(&location.Location{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("path asc").
+		Limit(pagination.Limit).
+		Offset(offset).
+		Find(&locations).Error
+
+	return locations, total, err
+}
+
+// GetLocations returns every location in path order, for building the tree.
+func (r *LocationRepository) GetLocations() ([]location.Location, error) {
+	var locations []location.Location
+	err := r.db.Order("path asc").Find(&locations).Error
+	return locations, err
+}
+
+// GetLocationChain returns the location followed by its ancestors up to the root.
+func (r *LocationRepository) GetLocationChain(id int) ([]location.Location, error) {
+	var chain []location.Location
+	err := r.db.Raw(`
+		WITH RECURSIVE chain AS (
+			SELECT locations.*, 0 AS depth FROM locations WHERE id = ?
+			UNION ALL
+			SELECT l.*, chain.depth + 1 FROM locations l JOIN chain ON l.id = chain.parent_id
+		)
+		SELECT * FROM chain ORDER BY depth`, id).Scan(&chain).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: location not found", utils.ErrNotFound)
+	}
+	return chain, nil
+}
+
+func (r *LocationRepository) GetChildren(id int) ([]location.Location, error) {
+	var children []location.Location
+	err := r.db.Where("parent_id = ?", id).Order("path asc").Find(&children).Error
+	return children, err
+}
+
+// UpdateLocation saves the location. When its path changed, the paths below it, the location of its
+// resources and the holiday calendars listing it or anything below it follow, in the same transaction.
+func (r *LocationRepository) UpdateLocation(l *location.Location, oldPath string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("CreatedAt").Save(l).Error; err != nil {
+			return err
+		}
+		if l.Path == oldPath {
+			return nil
+		}
+		if err := tx.Model(&location.Location{}).
+			Where(fmt.Sprintf(underPathSQL, "path")+" AND id <> ?", oldPath, oldPath, oldPath, l.ID).
+			Update("path", gorm.Expr(fmt.Sprintf(repathSQL, "path"), l.Path, oldPath)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&resource.Resource{}).
+			Where(fmt.Sprintf(underPathSQL, "location"), oldPath, oldPath, oldPath).
+			Update("location", gorm.Expr(fmt.Sprintf(repathSQL, "location"), l.Path, oldPath)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&calendar.HolidayCalendar{}).
+			Where(`EXISTS (SELECT 1 FROM jsonb_array_elements_text(locations) AS v WHERE `+fmt.Sprintf(underPathSQL, "v")+`)`, oldPath, oldPath, oldPath).
+			Update("locations", gorm.Expr(`(SELECT jsonb_agg(CASE WHEN `+fmt.Sprintf(underPathSQL, "v")+` THEN `+fmt.Sprintf(repathSQL, "v")+` ELSE v END)
+				FROM jsonb_array_elements_text(locations) AS v)`, oldPath, oldPath, oldPath, l.Path, oldPath)).Error
+	})
+	if utils.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: location '%s' already exists", utils.ErrConflict, l.Path)
+	}
+	return err
+}
+
+func (r *LocationRepository) DeleteLocation(id int) error {
+	result := r.db.Delete(&location.Location{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: location not found", utils.ErrNotFound)
+	}
+	return nil
+}
+
+// CountResourcesAt counts the resources placed directly at the location.
+func (r *LocationRepository) CountResourcesAt(id int) (int64, error) {
+	var count int64
+	err := r.db.Model(&resource.Resource{}).Where("location_id = ?", id).Count(&count).Error
+	return count, err
+}
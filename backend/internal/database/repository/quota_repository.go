@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type QuotaRepository struct {
+	db *gorm.DB
+}
+
+func NewQuotaRepository(db *gorm.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+func (r *QuotaRepository) CreateRule(rule *quota.QuotaRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *QuotaRepository) GetRuleByID(id int) (*quota.QuotaRule, error) {
+	var rule quota.QuotaRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: quota rule not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *QuotaRepository) GetAllRules(pagination utils.PaginationQuery) ([]quota.QuotaRule, int64, error) {
+	var rules []quota.QuotaRule
+	var total int64
+
+	query := r.db.Model(&quota.QuotaRule{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+	err := query.Order("id asc").
+		Limit(pagination.Limit).
+		Offset(offset).
+		Find(&rules).Error
+
+	return rules, total, err
+}
+
+// GetRules returns every rule, for checking a booking against all of them.
+func (r *QuotaRepository) GetRules() ([]quota.QuotaRule, error) {
+	var rules []quota.QuotaRule
+	err := r.db.Order("id asc").Find(&rules).Error
+	return rules, err
+}
+
+func (r *QuotaRepository) UpdateRule(rule *quota.QuotaRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *QuotaRepository) DeleteRule(id int) error {
+	result := r.db.Delete(&quota.QuotaRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: quota rule not found", utils.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *QuotaRepository) GetUserByUUID(uuid string) (*user.User, error) {
+	var u user.User
+	if err := r.db.First(&u, "uuid = ?", uuid).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user not found", utils.ErrNotFound)
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// quotaBookings scopes a query to the bookings a quota rule counts for the user.
+func (r *QuotaRepository) quotaBookings(filter quota.BookingFilter, statuses []booking.BookingStatus) *gorm.DB {
+	query := r.db.Model(&booking.Booking{}).
+		Where("bookings.user_id = ? AND bookings.status IN ?", filter.UserID, statuses)
+	if len(filter.ExcludeIDs) > 0 {
+		query = query.Where("bookings.id NOT IN ?", filter.ExcludeIDs)
+	}
+	if filter.ResourceTypeID != nil {
+		query = query.Joins("JOIN resources ON resources.id = bookings.resource_id").
+			Where("resources.type_id = ?", *filter.ResourceTypeID)
+	}
+	return query
+}
+
+// CountActiveBookings counts the user's pending and approved bookings that haven't ended yet.
+func (r *QuotaRepository) CountActiveBookings(filter quota.BookingFilter, now time.Time) (int64, error) {
+	var count int64
+	err := r.quotaBookings(filter, []booking.BookingStatus{booking.StatusPending, booking.StatusApproved}).
+		Where("bookings.end_time > ?", now).
+		Count(&count).Error
+	return count, err
+}
+
+// SumBookedHours adds up the hours of the user's live bookings that fall within [from, to).
+func (r *QuotaRepository) SumBookedHours(filter quota.BookingFilter, from, to time.Time) (float64, error) {
+	var hours float64
+	statuses := append([]booking.BookingStatus{booking.StatusPending}, booking.OccupyingStatuses...)
+	err := r.quotaBookings(filter, statuses).
+		Where("bookings.start_time < ? AND bookings.end_time > ?", to, from).
+		Select("COALESCE(SUM(EXTRACT(EPOCH FROM (LEAST(bookings.end_time, ?) - GREATEST(bookings.start_time, ?)))), 0) / 3600", to, from).
+		Scan(&hours).Error
+	return hours, err
+}
+
+// CountBookingsStarting counts the user's live bookings that start within [from, to).
+func (r *QuotaRepository) CountBookingsStarting(filter quota.BookingFilter, from, to time.Time) (int64, error) {
+	var count int64
+	statuses := append([]booking.BookingStatus{booking.StatusPending}, booking.OccupyingStatuses...)
+	err := r.quotaBookings(filter, statuses).
+		Where("bookings.start_time >= ? AND bookings.start_time < ?", from, to).
+		Count(&count).Error
+	return count, err
+}
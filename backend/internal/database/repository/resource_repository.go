@@ -3,11 +3,15 @@ package repository
 import (
 	"ResourceAllocator/internal/api/resource"
 	"ResourceAllocator/internal/api/utils"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ResourceRepository struct {
@@ -39,7 +43,170 @@ func (r *ResourceRepository) GetResourceByID(id int) (*resource.Resource, error)
 	return &res, nil
 }
 
-func (r *ResourceRepository) GetAllResources(typeID *int, location string, props map[string]string, startTime, endTime *string, pagination utils.PaginationQuery) ([]resource.ResourceSummary, int64, error) {
+// peakUnitsSQL is the highest number of units of resources.id held by approved / in use bookings
+// at any moment of a window. The resource's turnover buffers (its own, else its type's) count as
+// held: each booking is stretched by the turnover and so is the window, which makes bookings closer
+// than the turnover to the window collide with it. Usage only rises when a booking starts, so it is
+// enough to look at the window start and at every booking start inside the stretched window.
+// Args: window start, window start, window end.
+const peakUnitsSQL = `
+	SELECT COALESCE(MAX(usage.units), 0) FROM (
+		SELECT (
+			SELECT COALESCE(SUM(b.quantity), 0) FROM bookings b
+			WHERE b.resource_id = resources.id
+			AND b.status IN ('approved', 'utilized')
+			AND b.start_time <= p.t AND b.end_time + buf.turnover > p.t
+		) AS units
+		FROM (
+			SELECT (COALESCE(resources.buffer_before_minutes, rt.buffer_before_minutes, 0)
+				+ COALESCE(resources.buffer_after_minutes, rt.buffer_after_minutes, 0)) * interval '1 minute' AS turnover
+			FROM resource_types rt WHERE rt.id = resources.type_id
+		) buf
+		CROSS JOIN LATERAL (
+			SELECT CAST(? AS timestamptz) AS t
+			UNION
+			SELECT b0.start_time FROM bookings b0
+			WHERE b0.resource_id = resources.id
+			AND b0.status IN ('approved', 'utilized')
+			AND b0.start_time > ? AND b0.start_time < CAST(? AS timestamptz) + buf.turnover
+		) p
+	) usage`
+
+// locationChainSQL lists a resource's location and its ancestors (depth 0 being the location itself)
+// for the settings they pass down.
+const locationChainSQL = `WITH RECURSIVE chain AS (
+		SELECT l.parent_id, l.time_zone, l.opening_hours, 0 AS depth FROM locations l WHERE l.id = resources.location_id
+		UNION ALL
+		SELECT p.parent_id, p.time_zone, p.opening_hours, chain.depth + 1 FROM locations p JOIN chain ON p.id = chain.parent_id
+	) `
+
+// resourceZoneSQL is the time zone of a resource: that of the nearest location up its tree setting
+// one, else the default. Args: default zone.
+const resourceZoneSQL = `COALESCE((` + locationChainSQL + `SELECT time_zone FROM chain WHERE time_zone <> '' ORDER BY depth LIMIT 1), ?)`
+
+// resourceHoursSQL is the opening hours of a resource, found like its time zone. Args: default hours (JSON).
+const resourceHoursSQL = `COALESCE((` + locationChainSQL + `SELECT opening_hours FROM chain WHERE opening_hours IS NOT NULL ORDER BY depth LIMIT 1), CAST(? AS jsonb))`
+
+// slotFitSQL keeps resources whose slot rules (their own overrides, else their type's) accept a window,
+// slots being counted from midnight in the resource's time zone.
+// Args: start, default zone, duration in minutes, duration, duration, duration.
+const slotFitSQL = `
+	EXISTS (
+		SELECT 1 FROM resource_types rt
+		CROSS JOIN LATERAL (
+			SELECT CAST(? AS timestamptz) AT TIME ZONE ` + resourceZoneSQL + ` AS local_start
+		) w
+		WHERE rt.id = resources.type_id
+		AND ? % COALESCE(resources.slot_minutes, rt.slot_minutes, 60) = 0
+		AND CAST(EXTRACT(hour FROM w.local_start) * 60 + EXTRACT(minute FROM w.local_start) AS int) % COALESCE(resources.slot_minutes, rt.slot_minutes, 60) = 0
+		AND ? >= COALESCE(resources.min_duration_minutes, rt.min_duration_minutes, 0)
+		AND (COALESCE(resources.max_duration_minutes, rt.max_duration_minutes, 0) = 0
+			OR ? <= COALESCE(resources.max_duration_minutes, rt.max_duration_minutes, 0))
+	)`
+
+// openingHoursSQL keeps resources whose location (else the default schedule) is open for the whole
+// window, on the local clock: the day it starts on has opening hours and they contain the window.
+// Args: start, default zone, end, default zone, default opening hours (JSON).
+const openingHoursSQL = `
+	EXISTS (
+		SELECT 1 FROM (
+			SELECT CAST(? AS timestamptz) AT TIME ZONE ` + resourceZoneSQL + ` AS local_start,
+				CAST(? AS timestamptz) AT TIME ZONE ` + resourceZoneSQL + ` AS local_end,
+				` + resourceHoursSQL + ` AS hours
+		) w
+		CROSS JOIN LATERAL (
+			SELECT date_trunc('day', w.local_start) AS midnight, w.hours -> to_char(w.local_start, 'FMday') AS day
+		) d
+		WHERE d.day IS NOT NULL
+		AND w.local_start >= d.midnight + CAST(d.day ->> 'open' AS interval)
+		AND w.local_start < d.midnight + CAST(d.day ->> 'close' AS interval)
+		AND w.local_end <= d.midnight + CAST(d.day ->> 'close' AS interval)
+	)`
+
+// bookingWindowSQL keeps resources whose type's booking window (or the type's override for the
+// caller's role) is open for a slot starting the given number of minutes from now.
+// Args: role, role, minutes ahead, minutes ahead.
+const bookingWindowSQL = `
+	EXISTS (
+		SELECT 1 FROM resource_types rt
+		CROSS JOIN LATERAL (
+			SELECT CASE WHEN rt.role_booking_windows -> ? IS NOT NULL THEN rt.role_booking_windows -> ?
+				ELSE jsonb_build_object('min_lead_minutes', rt.min_lead_minutes, 'max_horizon_days', rt.max_horizon_days)
+			END AS w
+		) bw
+		WHERE rt.id = resources.type_id
+		AND COALESCE((bw.w ->> 'min_lead_minutes')::int, 0) <= ?
+		AND (COALESCE((bw.w ->> 'max_horizon_days')::int, 0) = 0
+			OR ? <= COALESCE((bw.w ->> 'max_horizon_days')::int, 0) * 1440)
+	)`
+
+// noHolidaySQL keeps resources whose holiday calendar (the one listing their location or the nearest
+// location above it, else the default one) has no holiday on the local date of an instant.
+// Args: instant, default zone.
+const noHolidaySQL = `
+	NOT EXISTS (
+		SELECT 1 FROM holidays h
+		WHERE h.date = to_char(CAST(? AS timestamptz) AT TIME ZONE ` + resourceZoneSQL + `, 'YYYY-MM-DD')
+		AND h.calendar_id = (
+			SELECT hc.id FROM holiday_calendars hc
+			CROSS JOIN LATERAL (
+				SELECT max(length(v)) AS depth FROM jsonb_array_elements_text(hc.locations) AS v
+				WHERE resources.location = v OR left(resources.location, length(v) + 3) = v || ' / '
+			) m
+			WHERE m.depth IS NOT NULL OR hc.is_default
+			ORDER BY m.depth DESC NULLS LAST, hc.id ASC
+			LIMIT 1
+		)
+	)`
+
+// propertyValueSQL is a property of a resource as its schema type compares: %s are the JSON type the
+// value must have and the cast from text, so a value of any other JSON type is NULL and matches
+// nothing. Args: the key, twice.
+const propertyValueSQL = `(CASE WHEN jsonb_typeof(resources.properties::jsonb -> CAST(? AS text)) = '%s' THEN (resources.properties::jsonb ->> CAST(? AS text))%s END)`
+
+// propertyIsSetSQL keeps resources that set a property (to anything but null). Args: the key.
+const propertyIsSetSQL = `COALESCE(jsonb_typeof(resources.properties::jsonb -> CAST(? AS text)), 'null') <> 'null'`
+
+// propertyValue returns propertyValueSQL for a property of the given type, with its args. Numbers
+// compare as numeric and booleans as boolean; strings, enums and dates ("2006-01-02") as text.
+func propertyValue(key string, t resource.PropertyType) (string, []interface{}) {
+	jsonType, cast := "string", ""
+	switch t {
+	case resource.PropertyInteger, resource.PropertyNumber:
+		jsonType, cast = "number", "::numeric"
+	case resource.PropertyBoolean:
+		jsonType, cast = "boolean", "::boolean"
+	}
+	return fmt.Sprintf(propertyValueSQL, jsonType, cast), []interface{}{key, key}
+}
+
+var propertyComparisons = map[resource.PropertyOperator]string{
+	resource.OpEq: "=", resource.OpGt: ">", resource.OpGte: ">=", resource.OpLt: "<", resource.OpLte: "<=",
+}
+
+// likeEscaper makes a contains filter match its value literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// propertyFilterSQL translates a property filter, checked and typed by the service, into a condition
+// and its args. Keys and values are always bound, never written into the SQL.
+func propertyFilterSQL(f resource.PropertyFilter) (string, []interface{}) {
+	if f.Op == resource.OpExists {
+		if exists, _ := f.Values[0].(bool); !exists {
+			return "NOT " + propertyIsSetSQL, []interface{}{f.Key}
+		}
+		return propertyIsSetSQL, []interface{}{f.Key}
+	}
+	value, args := propertyValue(f.Key, f.Type)
+	switch f.Op {
+	case resource.OpIn:
+		return value + " IN ?", append(args, f.Values)
+	case resource.OpContains:
+		return value + " ILIKE '%' || CAST(? AS text) || '%'", append(args, likeEscaper.Replace(fmt.Sprint(f.Values[0])))
+	}
+	return value + " " + propertyComparisons[f.Op] + " ?", append(args, f.Values[0])
+}
+
+func (r *ResourceRepository) GetAllResources(typeID *int, locationID *int, location string, props resource.PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]resource.ResourceSummary, int64, error) {
 	var resources []resource.ResourceSummary
 	var total int64
 	query := r.db.Model(&resource.Resource{})
@@ -47,34 +214,76 @@ func (r *ResourceRepository) GetAllResources(typeID *int, location string, props
 	if typeID != nil {
 		query = query.Where("type_id = ?", *typeID)
 	}
+	// A location matches every resource inside it, e.g. a building those on each of its floors
+	if locationID != nil {
+		query = query.Where(`resources.location_id IN (
+			WITH RECURSIVE below AS (
+				SELECT id FROM locations WHERE id = ?
+				UNION ALL
+				SELECT l.id FROM locations l JOIN below ON l.parent_id = below.id
+			) SELECT id FROM below)`, *locationID)
+	}
 	if location != "" {
-		query = query.Where("location = ?", location)
+		query = query.Where("(location = ? OR left(location, length(CAST(? AS text)) + 3) = CAST(? AS text) || ' / ')", location, location, location)
 	}
 	// 2. Dynamic JSON Filters
-	for key, value := range props {
-		query = query.Where("properties::jsonb ->> ? = ?", key, value)
+	for _, f := range props.Filters {
+		condition, args := propertyFilterSQL(f)
+		query = query.Where(condition, args...)
 	}
 
-	// 3. Temporal Availability Filter (NOT EXISTS)
-	// ONLY if both start and end times are provided
+	// 3. Temporal Availability Filter
+	// ONLY if both start and end times are provided. A resource is available while the peak number of
+	// units held by approved / in use bookings during the window is below its quantity.
+	var available string
 	if startTime != nil && endTime != nil && *startTime != "" && *endTime != "" {
-		query = query.Where(`
-			NOT EXISTS (
-				SELECT 1 FROM bookings b
-				WHERE b.resource_id = resources.id
-				AND b.status = 'approved'
-				AND (b.start_time < ? AND b.end_time > ?)
-			)
-		`, *endTime, *startTime)
+		available = fmt.Sprintf("(resources.quantity - (%s))", peakUnitsSQL)
+		query = query.Where(available+" > 0", *startTime, *startTime, *endTime)
+
+		// Only resources that can actually be booked for this window
+		start, errStart := time.Parse(time.RFC3339, *startTime)
+		end, errEnd := time.Parse(time.RFC3339, *endTime)
+		if errStart != nil || errEnd != nil {
+			return nil, 0, fmt.Errorf("%w: invalid start_time or end_time format (expected RFC3339)", utils.ErrInvalidInput)
+		}
+		duration := int(end.Sub(start).Minutes())
+		query = query.Where(slotFitSQL, *startTime, utils.DefaultTimeZone, duration, duration, duration, duration)
+
+		// ... within the opening hours of their location, in its time zone
+		defaultHours, err := json.Marshal(utils.DefaultWeeklyHours())
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where(openingHoursSQL, *startTime, utils.DefaultTimeZone, *endTime, utils.DefaultTimeZone, string(defaultHours))
+
+		// ... and that the caller may book right now (lead time / horizon of the type for their role)
+		minutesAhead := int(time.Until(start).Minutes())
+		query = query.Where(bookingWindowSQL, role, role, minutesAhead, minutesAhead)
+
+		// ... and that aren't closed for a holiday of their location that day
+		query = query.Where(noHolidaySQL, *startTime, utils.DefaultTimeZone)
 	}
 
 	// Count Total
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
+	if available != "" {
+		query = query.Select("resources.*, "+available+" AS available", *startTime, *startTime, *endTime)
+	}
+	// Sorted by a property if asked, resources without it last; newest first otherwise
+	var order interface{} = "created_at desc"
+	if props.SortBy != "" {
+		value, args := propertyValue(props.SortBy, props.SortType)
+		direction := "ASC"
+		if props.SortDesc {
+			direction = "DESC"
+		}
+		order = clause.OrderBy{Expression: clause.Expr{SQL: value + " " + direction + " NULLS LAST, created_at desc", Vars: args, WithoutParentheses: true}}
+	}
 	// Pagination
 	offset := (pagination.Page - 1) * pagination.Limit
-	err := query.Order("created_at desc").
+	err := query.Order(order).
 		Limit(pagination.Limit).
 		Offset(offset).
 		Find(&resources).Error
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/utils"
 	"ResourceAllocator/internal/database/repository" // Import the repository package
 	"testing"
 	"time"
@@ -91,9 +92,173 @@ func TestHasApprovedOverlap(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			hasOverlap, err := repo.HasApprovedOverlap(r.ID, tc.start, tc.end)
+			hasOverlap, err := repo.HasApprovedOverlap(r.ID, tc.start, tc.end, 1)
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expectTrue, hasOverlap)
 		})
 	}
 }
+
+func TestHasApprovedOverlap_Turnover(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewBookingRepository(db)
+
+	u := createTestUser(db, "turnover@test.com", "EMPLOYEE")
+	r := createTestResource(db, "Wet Lab")
+	after := 30
+	db.Model(r).Update("buffer_after_minutes", after)
+
+	baseTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	db.Create(&booking.Booking{
+		UserID:     u.UUID,
+		ResourceID: r.ID,
+		StartTime:  baseTime,
+		EndTime:    baseTime.Add(time.Hour),
+		Status:     booking.StatusApproved,
+	})
+
+	// 11:00 is still being cleaned
+	hasOverlap, err := repo.HasApprovedOverlap(r.ID, baseTime.Add(time.Hour), baseTime.Add(2*time.Hour), 1)
+	assert.NoError(t, err)
+	assert.True(t, hasOverlap)
+
+	// 11:30 is free again
+	hasOverlap, err = repo.HasApprovedOverlap(r.ID, baseTime.Add(90*time.Minute), baseTime.Add(150*time.Minute), 1)
+	assert.NoError(t, err)
+	assert.False(t, hasOverlap)
+}
+
+func TestGetFutureApprovedBookingsByUsers_IncludesAttending(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewBookingRepository(db)
+
+	organiser := createTestUser(db, "organiser@test.com", "EMPLOYEE")
+	guest := createTestUser(db, "guest@test.com", "EMPLOYEE")
+	r := createTestResource(db, "Board Room")
+
+	baseTime := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	guestID := guest.UUID
+	meeting := &booking.Booking{UserID: organiser.UUID, ResourceID: r.ID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusApproved,
+		Attendees: []booking.BookingAttendee{{UserID: &guestID, Name: "Guest", Email: "guest@test.com"}}}
+	db.Create(meeting)
+
+	bookings, err := repo.GetFutureApprovedBookingsByUsers([]string{guest.UUID}, time.Now())
+
+	assert.NoError(t, err)
+	if assert.Len(t, bookings, 1) {
+		assert.Equal(t, meeting.ID, bookings[0].ID)
+	}
+}
+
+func TestApproveBookingAndRejectConflicts_SystemApproval(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewBookingRepository(db)
+
+	u := createTestUser(db, "instant@test.com", "EMPLOYEE")
+	r := createTestResource(db, "Hot Desk")
+
+	baseTime := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	b := &booking.Booking{UserID: u.UUID, ResourceID: r.ID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusPending}
+	db.Create(b)
+
+	// Nobody approved it by hand: the event falls back to the system actor
+	_, err := repo.ApproveBookingAndRejectConflicts(b)
+	assert.NoError(t, err)
+
+	events, err := repo.GetBookingEvents(b.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, booking.ActorSystem, events[0].Actor)
+	}
+}
+
+func TestApproveBookingAndRejectConflicts_NoLongerPending(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewBookingRepository(db)
+
+	u := createTestUser(db, "cancelled@test.com", "EMPLOYEE")
+	r := createTestResource(db, "Focus Room")
+
+	baseTime := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	b := &booking.Booking{UserID: u.UUID, ResourceID: r.ID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusPending}
+	db.Create(b)
+	// The owner cancels after the admin loaded the request
+	db.Model(&booking.Booking{}).Where("id = ?", b.ID).Update("status", booking.StatusCancelled)
+
+	admin := "admin-uuid"
+	b.ApprovedBy = &admin
+	_, err := repo.ApproveBookingAndRejectConflicts(b)
+	assert.ErrorIs(t, err, utils.ErrConflict)
+
+	var stored booking.Booking
+	db.First(&stored, b.ID)
+	assert.Equal(t, booking.StatusCancelled, stored.Status)
+	events, err := repo.GetBookingEvents(b.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestUpdateBookingsStatus_RecordsEvents(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewBookingRepository(db)
+
+	u := createTestUser(db, "series@test.com", "EMPLOYEE")
+	r := createTestResource(db, "Huddle Room")
+
+	baseTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	approved := &booking.Booking{UserID: u.UUID, ResourceID: r.ID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusApproved}
+	pending := &booking.Booking{UserID: u.UUID, ResourceID: r.ID, StartTime: baseTime.AddDate(0, 0, 7), EndTime: baseTime.AddDate(0, 0, 7).Add(time.Hour), Status: booking.StatusPending}
+	db.Create(approved)
+	db.Create(pending)
+
+	cancelled, err := repo.UpdateBookingsStatus([]int{approved.ID, pending.ID}, []booking.BookingStatus{booking.StatusApproved, booking.StatusPending},
+		booking.StatusCancelled, "Cancelled by user", u.UUID)
+	assert.NoError(t, err)
+	assert.Len(t, cancelled, 2)
+
+	events, err := repo.GetBookingEvents(approved.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, booking.StatusApproved, events[0].FromStatus)
+		assert.Equal(t, booking.StatusCancelled, events[0].ToStatus)
+		assert.Equal(t, u.UUID, events[0].Actor)
+	}
+	events, err = repo.GetBookingEvents(pending.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, booking.StatusPending, events[0].FromStatus)
+	}
+}
+
+func TestUpdateBundleStatus_RecordsEvents(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewBookingRepository(db)
+
+	u := createTestUser(db, "bundle@test.com", "EMPLOYEE")
+	room := createTestResource(db, "Town Hall")
+	projector := createTestResource(db, "Projector")
+
+	baseTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	bundle := &booking.BookingBundle{UserID: u.UUID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusPending}
+	_, err := repo.CreateBundle(bundle, []booking.Booking{
+		{UserID: u.UUID, ResourceID: room.ID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusPending},
+		{UserID: u.UUID, ResourceID: projector.ID, StartTime: baseTime, EndTime: baseTime.Add(time.Hour), Status: booking.StatusPending},
+	})
+	assert.NoError(t, err)
+
+	bundle.Status = booking.StatusRejected
+	bundle.RejectionReason = "Rooms closed"
+	err = repo.UpdateBundleStatus(bundle, "admin", "Rooms closed")
+	assert.NoError(t, err)
+
+	members, err := repo.GetBookingsByBundleID(bundle.ID)
+	assert.NoError(t, err)
+	for _, m := range members {
+		events, err := repo.GetBookingEvents(m.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, booking.BookingEvent{BookingID: m.ID, FromStatus: booking.StatusPending, ToStatus: booking.StatusRejected, Actor: "admin", Reason: "Rooms closed"},
+				booking.BookingEvent{BookingID: events[0].BookingID, FromStatus: events[0].FromStatus, ToStatus: events[0].ToStatus, Actor: events[0].Actor, Reason: events[0].Reason})
+		}
+	}
+}
@@ -32,7 +32,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// 2. AutoMigrate Schema
-	err = testDB.AutoMigrate(&user.CreateUser{}, &resource.Resource{}, &booking.Booking{})
+	err = testDB.AutoMigrate(&user.CreateUser{}, &resource.Resource{}, &booking.BookingBundle{}, &booking.Booking{}, &booking.BookingAttendee{}, &booking.BookingEvent{})
 	if err != nil {
 		log.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -45,7 +45,7 @@ func TestMain(m *testing.M) {
 }
 
 func setupTestDB() *gorm.DB {
-	err := testDB.Exec("TRUNCATE TABLE bookings, resources, users RESTART IDENTITY CASCADE").Error
+	err := testDB.Exec("TRUNCATE TABLE booking_events, booking_attendees, bookings, booking_bundles, resources, users RESTART IDENTITY CASCADE").Error
 	if err != nil {
 		log.Fatalf("Failed to clean test database: %v", err)
 	}
@@ -73,7 +73,7 @@ func createTestUser(db *gorm.DB, email string, role user.Role) *user.CreateUser
 func createTestResource(db *gorm.DB, name string) *resource.Resource {
 	rt := &resource.ResourceType{
 		Type:             "Conference Room " + uuid.NewString()[:8],
-		SchemaDefinition: map[string]string{"capacity": "integer"},
+		SchemaDefinition: map[string]resource.PropertySchema{"capacity": {Type: resource.PropertyInteger, Required: true}},
 	}
 	if err := db.Create(rt).Error; err != nil {
 		panic(fmt.Sprintf("Failed to create test resource type: %v", err))
@@ -79,7 +79,7 @@ func TestGetAllResources_TemporalFilter(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// NOTE: GetAllResources takes strings for start/end because they come from query params
 			pagination := utils.PaginationQuery{Page: 1, Limit: 10}
-			results, _, err := resRepo.GetAllResources(nil, "", nil, &tc.queryStart, &tc.queryEnd, pagination)
+			results, _, err := resRepo.GetAllResources(nil, nil, "", resource.PropertyQuery{}, &tc.queryStart, &tc.queryEnd, "EMPLOYEE", pagination)
 			assert.NoError(t, err)
 
 			found := false
@@ -93,3 +93,62 @@ func TestGetAllResources_TemporalFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllResources_PropertyFilters(t *testing.T) {
+	db := setupTestDB()
+	repo := repository.NewResourceRepository(db)
+
+	rt := &resource.ResourceType{Type: "Room-" + uuid.NewString()[:8], SchemaDefinition: map[string]resource.PropertySchema{
+		"capacity":  {Type: resource.PropertyInteger},
+		"projector": {Type: resource.PropertyBoolean},
+		"wing":      {Type: resource.PropertyString},
+	}}
+	db.Create(rt)
+	rooms := map[string]map[string]interface{}{
+		"Small":  {"capacity": 4, "projector": false, "wing": "North Lab"},
+		"Medium": {"capacity": 10, "projector": true, "wing": "South"},
+		"Large":  {"capacity": 40, "projector": true},
+	}
+	for name, props := range rooms {
+		db.Create(&resource.Resource{Name: name, TypeID: rt.ID, Description: name, IsActive: true, Properties: props})
+	}
+
+	// Filters as the service passes them on, typed by the schema
+	tests := []struct {
+		name   string
+		query  resource.PropertyQuery
+		expect []string
+	}{
+		{"Numbers compare as numbers", resource.PropertyQuery{Filters: []resource.PropertyFilter{
+			{Key: "capacity", Op: resource.OpGte, Type: resource.PropertyInteger, Values: []interface{}{int64(10)}},
+		}, SortBy: "capacity", SortType: resource.PropertyInteger}, []string{"Medium", "Large"}},
+		{"Boolean equality", resource.PropertyQuery{Filters: []resource.PropertyFilter{
+			{Key: "projector", Op: resource.OpEq, Type: resource.PropertyBoolean, Values: []interface{}{true}},
+		}, SortBy: "capacity", SortDesc: true, SortType: resource.PropertyInteger}, []string{"Large", "Medium"}},
+		{"In a list", resource.PropertyQuery{Filters: []resource.PropertyFilter{
+			{Key: "capacity", Op: resource.OpIn, Type: resource.PropertyInteger, Values: []interface{}{int64(4), int64(40)}},
+		}, SortBy: "capacity", SortType: resource.PropertyInteger}, []string{"Small", "Large"}},
+		{"Contains ignores case and wildcards", resource.PropertyQuery{Filters: []resource.PropertyFilter{
+			{Key: "wing", Op: resource.OpContains, Type: resource.PropertyString, Values: []interface{}{"lab"}},
+		}}, []string{"Small"}},
+		{"Not set", resource.PropertyQuery{Filters: []resource.PropertyFilter{
+			{Key: "wing", Op: resource.OpExists, Type: resource.PropertyString, Values: []interface{}{false}},
+		}}, []string{"Large"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			results, total, err := repo.GetAllResources(&rt.ID, nil, "", tc.query, nil, nil, "EMPLOYEE", utils.PaginationQuery{Page: 1, Limit: 10})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(len(tc.expect)), total)
+			var names []string
+			for _, res := range results {
+				names = append(names, res.Name)
+			}
+			if tc.query.SortBy != "" {
+				assert.Equal(t, tc.expect, names)
+			} else {
+				assert.ElementsMatch(t, tc.expect, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// One long request against two short back-to-back ones that both overlap it
+func competingRequests() []booking.Booking {
+	start := nextWeekdayAt(9)
+	res := resource.Resource{ID: 8, Name: "Lab", TypeID: 3}
+	return []booking.Booking{
+		{ID: 1, ResourceID: 8, Resource: res, StartTime: start, EndTime: start.Add(4 * time.Hour), Status: booking.StatusPending},
+		{ID: 2, ResourceID: 8, Resource: res, StartTime: start, EndTime: start.Add(2 * time.Hour), Status: booking.StatusPending, Priority: 3},
+		{ID: 3, ResourceID: 8, Resource: res, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), Status: booking.StatusPending, Priority: 3},
+	}
+}
+
+func summaryIDs(summaries []booking.BookingSummary) []int {
+	ids := []int{}
+	for _, s := range summaries {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestPreviewAllocation_Objectives(t *testing.T) {
+	resourceID := 8
+	from := nextWeekdayAt(0)
+	to := from.Add(24 * time.Hour)
+
+	cases := []struct {
+		objective booking.AllocationObjective
+		approve   []int
+		reject    []int
+		score     float64
+	}{
+		// 4 hours beat 2 + 1
+		{booking.MaximizeHours, []int{1}, []int{2, 3}, 4},
+		// Two top-priority requests (4 + 4) beat one normal one (1)
+		{booking.MaximizePriority, []int{2, 3}, []int{1}, 8},
+	}
+	for _, tc := range cases {
+		mockRepo := new(MockBookingRepo)
+		svc := booking.NewBookingService(mockRepo)
+		mockRepo.On("GetPendingForAllocation", &resourceID, (*int)(nil), from, to).Return(competingRequests(), nil)
+		mockRepo.On("HasApprovedOverlapExcluding", 8, mock.Anything, mock.Anything, 1, mock.Anything).Return(false, nil)
+
+		plan, err := svc.PreviewAllocation(&booking.AllocationRequest{ResourceID: &resourceID, From: from, To: to, Objective: tc.objective})
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.approve, summaryIDs(plan.Approve), tc.objective)
+		assert.Equal(t, tc.reject, summaryIDs(plan.Reject), tc.objective)
+		assert.Equal(t, tc.score, plan.Score, tc.objective)
+	}
+}
+
+func TestPreviewAllocation_TurnoverSeparatesRequests(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	resourceID := 8
+	from := nextWeekdayAt(0)
+	to := from.Add(24 * time.Hour)
+	requests := competingRequests()
+	// An hour of cleaning after each booking: the back-to-back pair no longer fits
+	for i := range requests {
+		requests[i].Resource.Type = &resource.ResourceType{BufferAfterMinutes: 60}
+	}
+	mockRepo.On("GetPendingForAllocation", &resourceID, (*int)(nil), from, to).Return(requests, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 8, mock.Anything, mock.Anything, 1, mock.Anything).Return(false, nil)
+
+	plan, err := svc.PreviewAllocation(&booking.AllocationRequest{ResourceID: &resourceID, From: from, To: to, Objective: booking.MaximizePriority})
+
+	assert.NoError(t, err)
+	assert.Len(t, plan.Approve, 1)
+	assert.Len(t, plan.Reject, 2)
+}
+
+func TestPreviewAllocation_SkipsQuotaAndRejectsTakenSlots(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	typeID := 3
+	from := nextWeekdayAt(0)
+	to := from.Add(24 * time.Hour)
+	requests := competingRequests()
+	requests[0].UserID = "over-quota"
+	mockRepo.On("GetPendingForAllocation", (*int)(nil), &typeID, from, to).Return(requests, nil)
+	// Request 3 now overlaps a booking approved outside the batch
+	mockRepo.On("HasApprovedOverlapExcluding", 8, mock.Anything, mock.Anything, 1, 3).Return(true, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 8, mock.Anything, mock.Anything, 1, mock.Anything).Return(false, nil)
+	quotas.On("CheckBookings", "over-quota", 3, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(utils.ErrConflict)
+	quotas.On("CheckBookings", mock.Anything, 3, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	plan, err := svc.PreviewAllocation(&booking.AllocationRequest{ResourceTypeID: &typeID, From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, summaryIDs(plan.Approve))
+	assert.Equal(t, []int{3}, summaryIDs(plan.Reject))
+	assert.Len(t, plan.Skipped, 1)
+	assert.Equal(t, 1, plan.Skipped[0].BookingID)
+}
+
+func TestPreviewAllocation_CountsRequestsChosenForSameUser(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	typeID := 3
+	from := nextWeekdayAt(0)
+	to := from.Add(24 * time.Hour)
+	start := nextWeekdayAt(9)
+	// One user asks for two labs at different times; their quota allows a single booking
+	requests := []booking.Booking{
+		{ID: 1, ResourceID: 8, Resource: resource.Resource{ID: 8, TypeID: 3}, UserID: "user-uuid", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusPending},
+		{ID: 2, ResourceID: 9, Resource: resource.Resource{ID: 9, TypeID: 3}, UserID: "user-uuid", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), Status: booking.StatusPending},
+	}
+	mockRepo.On("GetPendingForAllocation", (*int)(nil), &typeID, from, to).Return(requests, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", mock.Anything, mock.Anything, mock.Anything, 1, mock.Anything).Return(false, nil)
+	quotas.On("CheckBookings", "user-uuid", 3, start, start.Add(time.Hour), []quota.Planned(nil), []int{1, 2}).Return(nil)
+	quotas.On("CheckBookings", "user-uuid", 3, start.Add(2*time.Hour), start.Add(3*time.Hour),
+		[]quota.Planned{{ResourceTypeID: 3, StartTime: start, EndTime: start.Add(time.Hour)}}, []int{1, 2}).Return(utils.ErrConflict)
+	// Once request 2 is left pending, it counts as a stored request again
+	quotas.On("CheckBookings", "user-uuid", 3, start, start.Add(time.Hour), []quota.Planned(nil), []int{1}).Return(nil)
+
+	plan, err := svc.PreviewAllocation(&booking.AllocationRequest{ResourceTypeID: &typeID, From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, summaryIDs(plan.Approve))
+	assert.Empty(t, plan.Reject)
+	assert.Len(t, plan.Skipped, 1)
+	assert.Equal(t, 2, plan.Skipped[0].BookingID)
+}
+
+func TestPreviewAllocation_NeedsExactlyOneScope(t *testing.T) {
+	svc := booking.NewBookingService(new(MockBookingRepo))
+	from := nextWeekdayAt(0)
+
+	_, err := svc.PreviewAllocation(&booking.AllocationRequest{From: from, To: from.Add(time.Hour)})
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+}
+
+func TestCommitAllocation_RejectsDuplicateIDs(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	_, err := svc.CommitAllocation(&booking.AllocationCommit{ApproveIDs: []int{1}, RejectIDs: []int{1}}, "admin")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CommitAllocation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCommitAllocation_RechecksQuota(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(9)
+	mockRepo.On("GetBookingByID", 1).Return(&booking.Booking{ID: 1, UserID: "user-uuid", Resource: resource.Resource{TypeID: 3},
+		StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusPending}, nil)
+	// The quota was tightened after the preview
+	quotas.On("CheckBookings", "user-uuid", 3, start, start.Add(time.Hour), []quota.Planned(nil), []int{1, 2}).Return(utils.ErrConflict)
+
+	_, err := svc.CommitAllocation(&booking.AllocationCommit{ApproveIDs: []int{1}, RejectIDs: []int{2}}, "admin")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "CommitAllocation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCommitAllocation_RejectOnlyPlan(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = new(MockQuotaChecker)
+
+	mockRepo.On("CommitAllocation", []int(nil), []int{2}, "admin", mock.AnythingOfType("time.Time")).Return([]booking.Booking{}, []booking.Booking{}, nil)
+
+	result, err := svc.CommitAllocation(&booking.AllocationCommit{RejectIDs: []int{2}}, "admin")
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Approved)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateBooking_ConflictSuggestsOtherResources(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	room := resource.Resource{ID: 1, Name: "Orion", TypeID: 2, IsActive: true, RequiresApproval: true, Location: "Building A, Floor 2",
+		Properties: map[string]interface{}{"projector": true, "capacity": 8.0}}
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 1).Return(&room, nil)
+	mockRepo.On("HasApprovedOverlap", 1, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetFutureApprovedBookings", 1, startTime).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetActiveResourcesByType", 2).Return([]resource.Resource{
+		room,
+		{ID: 2, Name: "Vega", TypeID: 2, Location: "Building B, Floor 2", Properties: map[string]interface{}{"projector": true, "capacity": 10.0}},
+		{ID: 3, Name: "Lyra", TypeID: 2, Location: "Building A, Floor 3", Properties: map[string]interface{}{"projector": true, "capacity": 8.0}},
+		{ID: 4, Name: "Draco", TypeID: 2, Location: "Building A, Floor 2", Properties: map[string]interface{}{"projector": true, "capacity": 8.0}},
+		{ID: 5, Name: "Cetus", TypeID: 2, Location: "Building A, Floor 2"},
+	}, nil)
+	// The closest match is taken too
+	mockRepo.On("HasApprovedOverlap", 4, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("HasApprovedOverlap", mock.Anything, startTime, endTime, 1).Return(false, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 1, StartTime: startTime, EndTime: endTime, Purpose: "Sync"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	var conflict *booking.SlotConflictError
+	assert.True(t, errors.As(err, &conflict))
+	var names []string
+	for _, alt := range conflict.AlternativeResources {
+		names = append(names, alt.Name)
+	}
+	// Same properties first, then a 10 seat room, then one without any of the properties
+	assert.Equal(t, []string{"Lyra", "Vega", "Cetus"}, names)
+	assert.Equal(t, 1.0, conflict.AlternativeResources[0].PropertyMatch)
+	assert.Equal(t, 0.9, conflict.AlternativeResources[1].PropertyMatch)
+	assert.Equal(t, booking.SuggestedSlot{ResourceID: 3, StartTime: startTime, EndTime: endTime}, conflict.AlternativeResources[0].SuggestedSlot)
+}
+
+func TestCreateBooking_AlternativesFollowTheirOwnSlotRules(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10).Add(30 * time.Minute)
+	endTime := startTime.Add(30 * time.Minute)
+	booth := resource.Resource{ID: 1, Name: "Booth 1", TypeID: 5, IsActive: true, RequiresApproval: true, Type: &resource.ResourceType{SlotMinutes: 30}}
+	hourly := 60
+	mockRepo.On("GetResourceByID", 1).Return(&booth, nil)
+	mockRepo.On("HasApprovedOverlap", 1, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetFutureApprovedBookings", 1, startTime).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetActiveResourcesByType", 5).Return([]resource.Resource{
+		booth,
+		{ID: 2, Name: "Booth 2", TypeID: 5, SlotMinutes: &hourly, Type: booth.Type},
+	}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 1, StartTime: startTime, EndTime: endTime, Purpose: "Call"}, "user-uuid")
+
+	var conflict *booking.SlotConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Empty(t, conflict.AlternativeResources)
+	mockRepo.AssertNotCalled(t, "HasApprovedOverlap", 2, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateBooking_AlternativesSeatTheAttendees(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	room := meetingRoom(4.0)
+	room.TypeID = 2
+	small := meetingRoom(2.0)
+	small.ID, small.Name, small.TypeID = 31, "Vega", 2
+	large := meetingRoom(6.0)
+	large.ID, large.Name, large.TypeID = 32, "Lyra", 2
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 30).Return(room, nil)
+	mockRepo.On("HasApprovedOverlap", 30, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetFutureApprovedBookings", 30, startTime).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetActiveResourcesByType", 2).Return([]resource.Resource{*room, *small, *large}, nil)
+	mockRepo.On("HasApprovedOverlap", mock.Anything, startTime, endTime, 1).Return(false, nil)
+
+	// The booker and two guests don't fit the two seat room
+	req := &booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: endTime, Purpose: "Review",
+		Attendees: []booking.AttendeeInput{{Name: "Guest", Email: "guest@partner.com"}, {Name: "Other", Email: "other@partner.com"}}}
+	req.Sanitize()
+	_, err := svc.CreateBooking(req, "user-uuid")
+
+	var conflict *booking.SlotConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Len(t, conflict.AlternativeResources, 1)
+	assert.Equal(t, "Lyra", conflict.AlternativeResources[0].Name)
+}
+
+func TestCreateBooking_AlternativesCloserInLocationTreeFirst(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	floor2 := 12
+	room := resource.Resource{ID: 1, Name: "Orion", TypeID: 2, IsActive: true, RequiresApproval: true, LocationID: &floor2, Location: "HQ / Building A / Floor 2"}
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 1).Return(&room, nil)
+	mockRepo.On("HasApprovedOverlap", 1, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetFutureApprovedBookings", 1, startTime).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetActiveResourcesByType", 2).Return([]resource.Resource{
+		room,
+		{ID: 2, Name: "Vega", TypeID: 2, Location: "HQ / Building B / Floor 2"},
+		{ID: 3, Name: "Lyra", TypeID: 2, Location: "HQ / Building A / Floor 3"},
+		{ID: 4, Name: "Draco", TypeID: 2, LocationID: &floor2, Location: "HQ / Building A / Floor 2"},
+	}, nil)
+	mockRepo.On("HasApprovedOverlap", mock.Anything, startTime, endTime, 1).Return(false, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 1, StartTime: startTime, EndTime: endTime, Purpose: "Sync"}, "user-uuid")
+
+	var conflict *booking.SlotConflictError
+	assert.True(t, errors.As(err, &conflict))
+	var names []string
+	var scores []float64
+	for _, alt := range conflict.AlternativeResources {
+		names = append(names, alt.Name)
+		scores = append(scores, alt.LocationMatch)
+	}
+	// Same floor, then same building, then only the same campus
+	assert.Equal(t, []string{"Draco", "Lyra", "Vega"}, names)
+	assert.Equal(t, []float64{1, 0.67, 0.33}, scores)
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func meetingRoom(capacity interface{}) *resource.Resource {
+	return &resource.Resource{
+		ID: 30, Name: "Orion", IsActive: true, RequiresApproval: true,
+		Properties: map[string]interface{}{"capacity": capacity},
+		Type:       &resource.ResourceType{SchemaDefinition: map[string]resource.PropertySchema{"capacity": {Type: resource.PropertyNumber}}},
+	}
+}
+
+func TestCreateBooking_AttendeesStored(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 30).Return(meetingRoom(3.0), nil)
+	mockRepo.On("GetUsersByUUIDs", []string{"colleague"}).Return([]user.User{{UUID: "colleague", Name: "Asha", Email: "asha@example.com"}}, nil)
+	mockRepo.On("HasApprovedOverlap", 30, startTime, endTime, 1).Return(false, nil)
+	mockRepo.On("CreateBooking", mock.MatchedBy(func(b *booking.Booking) bool {
+		return len(b.Attendees) == 2 && *b.Attendees[0].UserID == "colleague" && b.Attendees[0].Email == "asha@example.com" &&
+			b.Attendees[1].UserID == nil && b.Attendees[1].Email == "guest@partner.com"
+	})).Return(nil, 40)
+	mockRepo.On("GetBookingByID", 40).Return(&booking.Booking{ID: 40, ResourceID: 30, Status: booking.StatusPending}, nil)
+
+	req := &booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: endTime, Purpose: "Review",
+		Attendees: []booking.AttendeeInput{
+			{UserID: "colleague"},
+			{Name: "Guest", Email: " Guest@Partner.com"},
+			{UserID: "booker"}, // The booker isn't their own attendee
+			{UserID: "colleague"},
+		}}
+	req.Sanitize()
+	_, err := svc.CreateBooking(req, "booker")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBooking_AttendeesOverCapacity(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	// Capacity stored as text still counts
+	mockRepo.On("GetResourceByID", 30).Return(meetingRoom("2"), nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "Review",
+		Attendees: []booking.AttendeeInput{{Name: "A", Email: "a@partner.com"}, {Name: "B", Email: "b@partner.com"}}}, "booker")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "capacity of 2")
+	mockRepo.AssertNotCalled(t, "CreateBooking", mock.Anything)
+}
+
+func TestCreateBooking_CapacityOnlyWhenDeclared(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	room := meetingRoom(1.0)
+	room.Type.SchemaDefinition = map[string]resource.PropertySchema{}
+	mockRepo.On("GetResourceByID", 30).Return(room, nil)
+	mockRepo.On("HasApprovedOverlap", 30, startTime, endTime, 1).Return(false, nil)
+	mockRepo.On("CreateBooking", mock.AnythingOfType("*booking.Booking")).Return(nil, 41)
+	mockRepo.On("GetBookingByID", 41).Return(&booking.Booking{ID: 41, ResourceID: 30, Status: booking.StatusPending}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: endTime, Purpose: "Review",
+		Attendees: []booking.AttendeeInput{{Name: "A", Email: "a@partner.com"}, {Name: "B", Email: "b@partner.com"}}}, "booker")
+
+	assert.NoError(t, err)
+}
+
+func TestCreateBooking_UnknownAttendee(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	mockRepo.On("GetResourceByID", 30).Return(meetingRoom(8.0), nil)
+	mockRepo.On("GetUsersByUUIDs", []string{"ghost"}).Return([]user.User{}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "Review",
+		Attendees: []booking.AttendeeInput{{UserID: "ghost"}}}, "booker")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "ghost")
+}
+
+func TestGetAttendingBookings(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	pagination := utils.PaginationQuery{Page: 1, Limit: 10}
+	colleague := "colleague"
+	mockRepo.On("GetBookingsByAttendee", colleague, map[string]interface{}{}, pagination).Return([]booking.Booking{
+		{ID: 40, UserID: "booker", Attendees: []booking.BookingAttendee{{UserID: &colleague, Name: "Asha"}}},
+	}, int64(1), nil)
+
+	bookings, total, err := svc.GetAttendingBookings(colleague, map[string]interface{}{}, pagination)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "Asha", bookings[0].Attendees[0].Name)
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockResourceLister struct {
+	mock.Mock
+}
+
+func (m *MockResourceLister) GetAllResources(typeID *int, locationID *int, location string, props resource.PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]resource.ResourceSummary, int64, error) {
+	args := m.Called(typeID, locationID, location, props, startTime, endTime, role, pagination)
+	return args.Get(0).([]resource.ResourceSummary), args.Get(1).(int64), args.Error(2)
+}
+
+func freeHours(intervals []booking.TimeInterval) []string {
+	var out []string
+	for _, iv := range intervals {
+		out = append(out, iv.Start.Format("15:04")+"-"+iv.End.Format("15:04"))
+	}
+	return out
+}
+
+func TestGetResourceAvailability_FreeIntervalsSkipTurnover(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	day := nextWeekdayAt(0)
+	from, to := day, day.Add(24*time.Hour)
+	// Hourly slots, 30 minutes of cleaning after every booking
+	lab := &resource.Resource{ID: 14, Name: "Lab", Type: &resource.ResourceType{BufferAfterMinutes: 30}}
+	mockRepo.On("GetResourceByID", 14).Return(lab, nil)
+	mockRepo.On("GetBookingsInRange", []int{14}, booking.OccupyingStatuses, from.Add(-30*time.Minute), to.Add(30*time.Minute)).Return([]booking.Booking{
+		{ID: 1, ResourceID: 14, StartTime: day.Add(11 * time.Hour), EndTime: day.Add(12 * time.Hour), Status: booking.StatusApproved},
+	}, nil)
+
+	av, err := svc.GetResourceAvailability(14, &booking.AvailabilityQuery{From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Len(t, av.Busy, 1)
+	assert.Equal(t, booking.StatusApproved, av.Busy[0].Status)
+	// Blocked 10:30 - 12:30, which whole hours round out to 10:00 - 13:00
+	assert.Equal(t, []string{"09:00-10:00", "13:00-17:00"}, freeHours(av.Free))
+}
+
+func TestGetResourceAvailability_PoolFreeWhileAUnitIsLeft(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	day := nextWeekdayAt(0)
+	from, to := day, day.Add(24*time.Hour)
+	laptops := &resource.Resource{ID: 20, Name: "Laptops", Quantity: 2}
+	mockRepo.On("GetResourceByID", 20).Return(laptops, nil)
+	mockRepo.On("GetBookingsInRange", []int{20}, booking.OccupyingStatuses, from, to).Return([]booking.Booking{
+		{ID: 1, ResourceID: 20, StartTime: day.Add(10 * time.Hour), EndTime: day.Add(12 * time.Hour), Status: booking.StatusApproved},
+		{ID: 2, ResourceID: 20, StartTime: day.Add(11 * time.Hour), EndTime: day.Add(13 * time.Hour), Status: booking.StatusUtilized},
+	}, nil)
+
+	av, err := svc.GetResourceAvailability(20, &booking.AvailabilityQuery{From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, av.Units)
+	assert.Equal(t, []string{"09:00-11:00", "12:00-17:00"}, freeHours(av.Free))
+}
+
+func TestGetAvailabilityGrid_PendingCountsWhenAsked(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	lister := new(MockResourceLister)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Resources = lister
+
+	day := nextWeekdayAt(0)
+	from, to := day, day.Add(24*time.Hour)
+	typeID := 2
+	filter := &resource.ResourceFilter{TypeID: &typeID, Role: "EMPLOYEE"}
+	pagination := utils.PaginationQuery{Page: 1, Limit: 10}
+	lister.On("GetAllResources", &typeID, (*int)(nil), "", resource.PropertyQuery{}, (*string)(nil), (*string)(nil), "EMPLOYEE", pagination).
+		Return([]resource.ResourceSummary{{ID: 7}, {ID: 3}}, int64(2), nil)
+	mockRepo.On("GetResourcesByIDs", []int{7, 3}).Return([]resource.Resource{{ID: 3, Name: "Vega"}, {ID: 7, Name: "Orion"}}, nil)
+	statuses := append([]booking.BookingStatus{booking.StatusPending}, booking.OccupyingStatuses...)
+	mockRepo.On("GetBookingsInRange", []int{7, 3}, statuses, from, to).Return([]booking.Booking{
+		{ID: 9, ResourceID: 3, StartTime: day.Add(9 * time.Hour), EndTime: day.Add(17 * time.Hour), Status: booking.StatusPending},
+	}, nil)
+
+	grid, total, err := svc.GetAvailabilityGrid(filter, &booking.AvailabilityQuery{From: from, To: to, IncludePending: true}, pagination)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	// In the listing's order
+	assert.Equal(t, "Orion", grid[0].ResourceName)
+	assert.Equal(t, []string{"09:00-17:00"}, freeHours(grid[0].Free))
+	assert.Equal(t, "Vega", grid[1].ResourceName)
+	assert.Empty(t, grid[1].Free)
+	assert.Equal(t, booking.StatusPending, grid[1].Busy[0].Status)
+}
+
+func TestGetResourceAvailability_RangeTooLong(t *testing.T) {
+	svc := booking.NewBookingService(new(MockBookingRepo))
+	from := nextWeekdayAt(0)
+
+	_, err := svc.GetResourceAvailability(14, &booking.AvailabilityQuery{From: from, To: from.AddDate(0, 2, 0)})
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+}
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffers_ResourceOverridesType(t *testing.T) {
+	after := 45
+	res := &resource.Resource{
+		BufferAfterMinutes: &after,
+		Type:               &resource.ResourceType{BufferBeforeMinutes: 15, BufferAfterMinutes: 30},
+	}
+
+	buf := res.Buffers()
+
+	assert.Equal(t, 15*time.Minute, buf.Before)
+	assert.Equal(t, 45*time.Minute, buf.After)
+	assert.Equal(t, time.Hour, buf.Turnover())
+}
+
+func TestPeakQuantity_WithTurnover(t *testing.T) {
+	base := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	existing := []booking.Booking{{StartTime: base, EndTime: base.Add(time.Hour)}}
+
+	// Back-to-back is fine without buffers, but not when 30 minutes of cleaning follow every booking
+	next := base.Add(time.Hour)
+	assert.Equal(t, 0, booking.PeakQuantity(existing, next, next.Add(time.Hour)))
+	assert.Equal(t, 1, booking.PeakQuantity(booking.WithTurnover(existing, 30*time.Minute), next, next.Add(time.Hour+30*time.Minute)))
+}
+
+func TestCreateBooking_SuggestionsSkipTurnover(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	lab := &resource.Resource{ID: 14, IsActive: true, RequiresApproval: true,
+		Type: &resource.ResourceType{SlotMinutes: 30, BufferAfterMinutes: 30}}
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 14).Return(lab, nil)
+	mockRepo.On("HasApprovedOverlap", 14, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetActiveResourcesByType", 0).Return([]resource.Resource{}, nil)
+	// Booked 10:00 - 11:00, then cleaned until 11:30
+	mockRepo.On("GetFutureApprovedBookings", 14, startTime.Add(-30*time.Minute)).Return([]booking.Booking{
+		{ID: 70, ResourceID: 14, StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: booking.StatusApproved},
+	}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 14, StartTime: startTime, EndTime: endTime, Purpose: "Assay"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	var conflict *booking.SlotConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []int{70}, conflict.ConflictingBookingIDs)
+	assert.Equal(t, booking.SuggestedSlot{ResourceID: 14, StartTime: startTime.Add(90 * time.Minute), EndTime: startTime.Add(150 * time.Minute)}, conflict.SuggestedSlots[0])
+}
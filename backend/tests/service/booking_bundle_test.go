@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// nextWeekdayAt returns the next weekday (at least one day away) at the given hour, IST.
+func nextWeekdayAt(hour int) time.Time {
+	loc, _ := time.LoadLocation("Asia/Kolkata")
+	now := time.Now().In(loc)
+	t := time.Date(now.Year(), now.Month(), now.Day()+1, hour, 0, 0, 0, loc)
+	for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday || utils.DefaultSchedule().IsHoliday(t) != nil {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func TestCreateBundle_Success(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(11)
+	end := start.Add(time.Hour)
+	req := &booking.BundleCreate{ResourceIDs: []int{3, 1, 3}, StartTime: start, EndTime: end, Purpose: "Client demo"}
+
+	// The projector needs approval, so the whole bundle waits for an admin
+	mockRepo.On("GetResourceByID", 1).Return(&resource.Resource{ID: 1, IsActive: true, RequiresApproval: false}, nil)
+	mockRepo.On("GetResourceByID", 3).Return(&resource.Resource{ID: 3, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlap", 1, start, end, 1).Return(false, nil)
+	mockRepo.On("HasApprovedOverlap", 3, start, end, 1).Return(false, nil)
+	mockRepo.On("CreateBundle", mock.MatchedBy(func(b *booking.BookingBundle) bool {
+		return b.Status == booking.StatusPending
+	}), mock.MatchedBy(func(bs []booking.Booking) bool {
+		return len(bs) == 2 && bs[0].ResourceID == 1 && bs[1].ResourceID == 3 && bs[0].Status == booking.StatusPending
+	})).Return([]booking.Booking{}, nil, 8)
+	mockRepo.On("GetBundleByID", 8).Return(&booking.BookingBundle{ID: 8, StartTime: start, EndTime: end, Status: booking.StatusPending}, nil)
+	bundleID := 8
+	mockRepo.On("GetBookingsByBundleID", 8).Return([]booking.Booking{
+		{ID: 20, BundleID: &bundleID, ResourceID: 1, Status: booking.StatusPending, Resource: resource.Resource{Name: "Room"}, User: user.User{Email: "a@test.com"}},
+		{ID: 21, BundleID: &bundleID, ResourceID: 3, Status: booking.StatusPending, Resource: resource.Resource{Name: "Projector"}, User: user.User{Email: "a@test.com"}},
+	}, nil)
+
+	summary, err := svc.CreateBundle(req, "user-uuid")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 8, summary.ID)
+	assert.Len(t, summary.Bookings, 2)
+	assert.Equal(t, booking.PathApprovalRequired, summary.ConfirmationPath)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBundle_OneMemberBusy(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(11)
+	end := start.Add(time.Hour)
+	req := &booking.BundleCreate{ResourceIDs: []int{1, 2}, StartTime: start, EndTime: end, Purpose: "Client demo"}
+
+	mockRepo.On("GetResourceByID", mock.Anything).Return(&resource.Resource{IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlap", 1, start, end, 1).Return(false, nil)
+	mockRepo.On("HasApprovedOverlap", 2, start, end, 1).Return(true, nil)
+
+	_, err := svc.CreateBundle(req, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Contains(t, err.Error(), "resource(s) 2")
+	mockRepo.AssertNotCalled(t, "CreateBundle")
+}
+
+func TestCreateBundle_PooledMemberQuantity(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(11)
+	end := start.Add(time.Hour)
+	req := &booking.BundleCreate{ResourceIDs: []int{1, 30}, StartTime: start, EndTime: end, Purpose: "Training", Quantities: map[int]int{30: 6}}
+
+	mockRepo.On("GetResourceByID", 1).Return(&resource.Resource{ID: 1, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("GetResourceByID", 30).Return(&resource.Resource{ID: 30, IsActive: true, RequiresApproval: true, Quantity: 20}, nil)
+	mockRepo.On("HasApprovedOverlap", 1, start, end, 1).Return(false, nil)
+	mockRepo.On("HasApprovedOverlap", 30, start, end, 6).Return(false, nil)
+	mockRepo.On("CreateBundle", mock.AnythingOfType("*booking.BookingBundle"), mock.MatchedBy(func(bs []booking.Booking) bool {
+		return len(bs) == 2 && bs[0].Quantity == 1 && bs[1].Quantity == 6
+	})).Return([]booking.Booking{}, nil, 8)
+	mockRepo.On("GetBundleByID", 8).Return(&booking.BookingBundle{ID: 8, StartTime: start, EndTime: end, Status: booking.StatusPending}, nil)
+	mockRepo.On("GetBookingsByBundleID", 8).Return([]booking.Booking{}, nil)
+
+	_, err := svc.CreateBundle(req, "user-uuid")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBundle_QuantityForUnknownMember(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(11)
+	req := &booking.BundleCreate{ResourceIDs: []int{1, 2}, StartTime: start, EndTime: start.Add(time.Hour), Purpose: "Training", Quantities: map[int]int{30: 6}}
+
+	_, err := svc.CreateBundle(req, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CreateBundle")
+}
+
+func TestUpdateStatus_BundleMemberRequiresBundleApproval(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	bundleID := 4
+	mockRepo.On("GetBookingByID", 30).Return(&booking.Booking{ID: 30, BundleID: &bundleID, Status: booking.StatusPending}, nil)
+
+	err := svc.UpdateStatus(30, &booking.BookingStatusUpdate{Status: booking.StatusApproved}, "admin-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "ApproveBookingAndRejectConflicts")
+}
+
+func TestCancelBooking_BundleMemberRequiresBundleCancel(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	bundleID := 4
+	mockRepo.On("GetBookingByID", 30).Return(&booking.Booking{ID: 30, UserID: "owner", BundleID: &bundleID, Status: booking.StatusApproved}, nil)
+
+	err := svc.CancelBooking(30, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "/api/bookings/bundles/4/cancel")
+	mockRepo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything)
+}
+
+func TestUpdateBundleStatus_ApprovalRechecksQuota(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(11)
+	end := start.Add(time.Hour)
+	bundleID := 8
+	mockRepo.On("GetBundleByID", 8).Return(&booking.BookingBundle{ID: 8, UserID: "owner", StartTime: start, EndTime: end, Status: booking.StatusPending}, nil)
+	mockRepo.On("GetBookingsByBundleID", 8).Return([]booking.Booking{
+		{ID: 20, BundleID: &bundleID, ResourceID: 1, Resource: resource.Resource{TypeID: 3}, StartTime: start, EndTime: end, Status: booking.StatusPending},
+		{ID: 21, BundleID: &bundleID, ResourceID: 2, Resource: resource.Resource{TypeID: 3}, StartTime: start, EndTime: end, Status: booking.StatusPending},
+	}, nil)
+	quotas.On("CheckBookings", "owner", 3, start, end, []quota.Planned(nil), []int{20, 21}).Return(nil)
+	// The owner's quota was tightened after requesting: the second member no longer fits
+	quotas.On("CheckBookings", "owner", 3, start, end, []quota.Planned{{ResourceTypeID: 3, StartTime: start, EndTime: end}}, []int{20, 21}).Return(utils.ErrConflict)
+
+	err := svc.UpdateBundleStatus(8, &booking.BookingStatusUpdate{Status: booking.StatusApproved}, "admin")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "ApproveBundleAndRejectConflicts", mock.Anything)
+}
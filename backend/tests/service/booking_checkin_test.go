@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func startedBooking(userID string) *booking.Booking {
+	start := time.Now().Add(-5 * time.Minute)
+	return &booking.Booking{ID: 80, ResourceID: 9, UserID: userID, StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusApproved}
+}
+
+func TestSelfCheckIn_Owner(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBookingByID", 80).Return(startedBooking("owner"), nil)
+	mockRepo.On("CheckInBooking", 80, "owner").Return(nil)
+
+	err := svc.SelfCheckIn(80, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSelfCheckIn_NotOwner(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBookingByID", 80).Return(startedBooking("owner"), nil)
+
+	err := svc.SelfCheckIn(80, "someone-else")
+
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+	mockRepo.AssertNotCalled(t, "CheckInBooking", mock.Anything, mock.Anything)
+}
+
+func TestCheckInWithCode_RoundTrip(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+	svc.CheckInURL = "https://rooms.example.com/checkin"
+
+	mockRepo.On("GetResourceByID", 9).Return(&resource.Resource{ID: 9, IsActive: true}, nil)
+	issued, err := svc.GetCheckInCode(9)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(issued.QRPayload, "https://rooms.example.com/checkin?code="))
+	assert.True(t, issued.ExpiresAt.After(time.Now()))
+
+	mockRepo.On("GetCheckInCandidate", 9, mock.AnythingOfType("time.Time"), booking.CheckInWindow).Return(startedBooking("owner"), nil)
+	mockRepo.On("CheckInBooking", 80, booking.ActorCheckInCode).Return(nil)
+
+	summary, err := svc.CheckInWithCode(issued.Code)
+
+	assert.NoError(t, err)
+	assert.Equal(t, booking.StatusUtilized, summary.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckInWithCode_Tampered(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	code, _, err := utils.GenerateCheckInCode(9, time.Now())
+	assert.NoError(t, err)
+	// Same signature, different resource
+	forged := "10" + code[strings.Index(code, "."):]
+
+	_, err = svc.CheckInWithCode(forged)
+
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+	mockRepo.AssertNotCalled(t, "GetCheckInCandidate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckInWithCode_Expired(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	svc := booking.NewBookingService(new(MockBookingRepo))
+
+	code, _, err := utils.GenerateCheckInCode(9, time.Now().Add(-utils.CheckInCodeTTL-time.Minute))
+	assert.NoError(t, err)
+
+	_, err = svc.CheckInWithCode(code)
+
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func inUseBooking() *booking.Booking {
+	start := time.Now().Add(-30 * time.Minute)
+	checkedIn := start.Add(5 * time.Minute)
+	return &booking.Booking{
+		ID: 70, ResourceID: 6, UserID: "owner", StartTime: start, EndTime: start.Add(2 * time.Hour),
+		Status: booking.StatusUtilized, CheckedInAt: &checkedIn,
+		Resource: resource.Resource{Name: "Huddle Room"}, User: user.User{Email: "owner@test.com"},
+	}
+}
+
+func TestCheckOutBooking_ShortensAndPromotes(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	b := inUseBooking()
+	originalEnd := b.EndTime
+	mockRepo.On("GetBookingByID", 70).Return(b, nil)
+	mockRepo.On("CheckOutBooking", 70, mock.AnythingOfType("time.Time")).Return(nil)
+	// The remainder of the slot is offered to the waitlist
+	mockRepo.On("GetWaitingEntries", 6, mock.AnythingOfType("time.Time"), originalEnd, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+
+	summary, err := svc.CheckOutBooking(70, "owner")
+
+	assert.NoError(t, err)
+	assert.True(t, summary.EndTime.Before(originalEnd))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckOutBooking_AlreadyCheckedOut(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	b := inUseBooking()
+	out := time.Now().Add(-time.Minute)
+	b.CheckedOutAt = &out
+	mockRepo.On("GetBookingByID", 70).Return(b, nil)
+
+	_, err := svc.CheckOutBooking(70, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CheckOutBooking")
+}
+
+func TestExtendBooking_RequiresCheckIn(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	b := inUseBooking()
+	b.Status = booking.StatusApproved
+	mockRepo.On("GetBookingByID", 70).Return(b, nil)
+
+	_, err := svc.ExtendBooking(70, &booking.BookingExtend{EndTime: b.EndTime.Add(time.Hour)}, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "ExtendBooking")
+}
+
+func TestExtendBooking_NotOwner(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	b := inUseBooking()
+	mockRepo.On("GetBookingByID", 70).Return(b, nil)
+
+	_, err := svc.ExtendBooking(70, &booking.BookingExtend{EndTime: b.EndTime.Add(time.Hour)}, "someone-else")
+
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+	mockRepo.AssertNotCalled(t, "ExtendBooking")
+}
+
+func TestExtendBooking_ChecksQuotaForLongerBooking(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(10)
+	b := &booking.Booking{ID: 70, ResourceID: 6, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour),
+		Status: booking.StatusUtilized, Resource: resource.Resource{Name: "Huddle Room", TypeID: 3}}
+	newEnd := start.Add(2 * time.Hour)
+	mockRepo.On("GetBookingByID", 70).Return(b, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 6, b.EndTime, newEnd, 1, 70).Return(false, nil)
+	// Two hours in place of one go over the weekly hours
+	quotas.On("CheckBooking", "owner", 3, start, newEnd, 70).Return(utils.ErrConflict)
+
+	_, err := svc.ExtendBooking(70, &booking.BookingExtend{EndTime: newEnd}, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "ExtendBooking", mock.Anything, mock.Anything)
+}
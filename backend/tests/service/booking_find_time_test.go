@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func candidateRooms(c booking.TimeCandidate) []int {
+	var ids []int
+	for _, r := range c.Resources {
+		ids = append(ids, r.ResourceID)
+	}
+	return ids
+}
+
+func TestFindTime_RoomFreeAndAttendeesAvailable(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	day := nextWeekdayAt(0)
+	from, to := day, day.Add(24*time.Hour)
+	mockRepo.On("GetResourcesByIDs", []int{1, 2}).Return([]resource.Resource{
+		{ID: 1, Name: "Orion", IsActive: true},
+		{ID: 2, Name: "Vega", IsActive: true},
+	}, nil)
+	mockRepo.On("GetFutureApprovedBookings", 1, from).Return([]booking.Booking{
+		{ResourceID: 1, StartTime: day.Add(11 * time.Hour), EndTime: day.Add(13 * time.Hour), Status: booking.StatusApproved},
+	}, nil)
+	mockRepo.On("GetFutureApprovedBookings", 2, from).Return([]booking.Booking{}, nil)
+	// The organiser is free, one attendee is in another room until 11
+	mockRepo.On("GetFutureApprovedBookingsByUsers", []string{"organiser", "attendee"}, from).Return([]booking.Booking{
+		{ResourceID: 9, UserID: "attendee", StartTime: day.Add(9 * time.Hour), EndTime: day.Add(11 * time.Hour), Status: booking.StatusApproved},
+	}, nil)
+
+	candidates, err := svc.FindTime(&booking.FindTimeRequest{
+		ResourceIDs: []int{2, 1}, Attendees: []string{"attendee", "organiser"}, DurationMinutes: 60, From: from, To: to, Limit: 3,
+	}, "organiser")
+
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 3)
+	assert.Equal(t, day.Add(11*time.Hour), candidates[0].StartTime)
+	assert.Equal(t, day.Add(12*time.Hour), candidates[0].EndTime)
+	assert.Equal(t, []int{2}, candidateRooms(candidates[0]))
+	assert.Equal(t, []int{2}, candidateRooms(candidates[1]))
+	assert.Equal(t, day.Add(13*time.Hour), candidates[2].StartTime)
+	assert.ElementsMatch(t, []int{1, 2}, candidateRooms(candidates[2]))
+}
+
+func TestFindTime_ByTypeAndProperties(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	lister := new(MockResourceLister)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Resources = lister
+
+	day := nextWeekdayAt(0)
+	from, to := day.Add(15*time.Hour), day.Add(17*time.Hour)
+	typeID := 2
+	props := resource.PropertyQuery{Filters: []resource.PropertyFilter{{Key: "projector", Op: resource.OpEq, Value: "true"}}}
+	lister.On("GetAllResources", &typeID, (*int)(nil), "", props, (*string)(nil), (*string)(nil), "EMPLOYEE", mock.Anything).
+		Return([]resource.ResourceSummary{{ID: 5}}, int64(1), nil)
+	mockRepo.On("GetResourcesByIDs", []int{5}).Return([]resource.Resource{{ID: 5, Name: "Lyra", IsActive: true}}, nil)
+	mockRepo.On("GetFutureApprovedBookings", 5, from).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetFutureApprovedBookingsByUsers", []string{"organiser"}, from).Return([]booking.Booking{}, nil)
+
+	candidates, err := svc.FindTime(&booking.FindTimeRequest{
+		ResourceTypeID: &typeID, Properties: map[string]string{"projector": "true"}, DurationMinutes: 120, From: from, To: to, Role: "EMPLOYEE",
+	}, "organiser")
+
+	assert.NoError(t, err)
+	// Only 15:00 - 17:00 fits before the end of the working day
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, from, candidates[0].StartTime)
+}
+
+func TestFindTime_SkipsRoomsTooSmallForAttendees(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	day := nextWeekdayAt(0)
+	from, to := day.Add(15*time.Hour), day.Add(17*time.Hour)
+	small := meetingRoom(2.0)
+	small.ID = 1
+	large := meetingRoom(8.0)
+	large.ID = 2
+	mockRepo.On("GetResourcesByIDs", []int{1, 2}).Return([]resource.Resource{*small, *large}, nil)
+	mockRepo.On("GetFutureApprovedBookings", 2, from).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetFutureApprovedBookingsByUsers", []string{"organiser", "a", "b"}, from).Return([]booking.Booking{}, nil)
+
+	candidates, err := svc.FindTime(&booking.FindTimeRequest{
+		ResourceIDs: []int{1, 2}, Attendees: []string{"a", "b"}, DurationMinutes: 60, From: from, To: to,
+	}, "organiser")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, candidates)
+	for _, c := range candidates {
+		assert.Equal(t, []int{2}, candidateRooms(c))
+	}
+	mockRepo.AssertNotCalled(t, "GetFutureApprovedBookings", 1, from)
+}
+
+func TestFindTime_TooManyMatchingResources(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	lister := new(MockResourceLister)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Resources = lister
+
+	day := nextWeekdayAt(0)
+	typeID := 2
+	lister.On("GetAllResources", &typeID, (*int)(nil), "", mock.Anything, (*string)(nil), (*string)(nil), "", mock.Anything).
+		Return([]resource.ResourceSummary{{ID: 5}}, int64(140), nil)
+
+	_, err := svc.FindTime(&booking.FindTimeRequest{ResourceTypeID: &typeID, DurationMinutes: 30, From: day, To: day.Add(24 * time.Hour)}, "organiser")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "140 resources match")
+	mockRepo.AssertNotCalled(t, "GetResourcesByIDs", mock.Anything)
+}
+
+func TestFindTime_NeedsResourcesOrType(t *testing.T) {
+	svc := booking.NewBookingService(new(MockBookingRepo))
+	from := nextWeekdayAt(0)
+	typeID := 2
+
+	_, err := svc.FindTime(&booking.FindTimeRequest{ResourceIDs: []int{1}, ResourceTypeID: &typeID, DurationMinutes: 60, From: from, To: from.Add(time.Hour)}, "u1")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+}
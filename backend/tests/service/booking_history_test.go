@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateStatus_RejectionRecordsEvent(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(&booking.Booking{ID: 50, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusPending}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.MatchedBy(func(b *booking.Booking) bool { return b.Status == booking.StatusRejected }),
+		&booking.BookingEvent{BookingID: 50, FromStatus: booking.StatusPending, ToStatus: booking.StatusRejected, Actor: "admin", Reason: "Room under repair"}).Return(nil)
+	mockRepo.On("GetWaitingEntries", 5, start, start.Add(time.Hour), mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+
+	err := svc.UpdateStatus(50, &booking.BookingStatusUpdate{Status: booking.StatusRejected, RejectionReason: "Room under repair"}, "admin")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_RecordsEvent(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(&booking.Booking{ID: 50, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusApproved}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.AnythingOfType("*booking.Booking"),
+		&booking.BookingEvent{BookingID: 50, FromStatus: booking.StatusApproved, ToStatus: booking.StatusCancelled, Actor: "owner", Reason: "Cancelled by user"}).Return(nil)
+	mockRepo.On("GetWaitingEntries", 5, start, start.Add(time.Hour), mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+
+	err := svc.CancelBooking(50, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetBookingHistory_OwnerAndAdmins(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBookingByID", 50).Return(&booking.Booking{ID: 50, UserID: "owner", Status: booking.StatusReleased}, nil)
+	mockRepo.On("GetBookingEvents", 50).Return([]booking.BookingEvent{
+		{BookingID: 50, FromStatus: booking.StatusPending, ToStatus: booking.StatusApproved, Actor: "admin"},
+		{BookingID: 50, FromStatus: booking.StatusApproved, ToStatus: booking.StatusReleased, Actor: booking.ActorAutoReleaseJob, Reason: "Auto-released due to no check-in"},
+	}, nil)
+
+	events, err := svc.GetBookingHistory(50, "owner", "EMPLOYEE")
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, booking.ActorAutoReleaseJob, events[1].Actor)
+
+	_, err = svc.GetBookingHistory(50, "admin", "ADMIN")
+	assert.NoError(t, err)
+
+	_, err = svc.GetBookingHistory(50, "someone-else", "EMPLOYEE")
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+}
+
+func TestUpdateSeriesOccurrences_MoveRecordsEvent(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	seriesID := 9
+	start := nextWeekdayAt(10)
+	approver := "admin"
+	occurrence := booking.Booking{ID: 1, SeriesID: &seriesID, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusApproved, ApprovedBy: &approver}
+	mockRepo.On("GetBookingByID", 1).Return(&occurrence, nil)
+	newStart := start.Add(2 * time.Hour)
+	mockRepo.On("GetResourceByID", 5).Return(&resource.Resource{ID: 5, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 5, newStart, newStart.Add(time.Hour), 1, 1).Return(false, nil)
+	mockRepo.On("UpdateBookingSchedules", mock.MatchedBy(func(bs []booking.Booking) bool { return len(bs) == 1 && bs[0].Status == booking.StatusPending }),
+		[]booking.BookingEvent{{BookingID: 1, FromStatus: booking.StatusApproved, ToStatus: booking.StatusPending, Actor: "owner", Reason: "Series occurrence moved, needs approval again"}}).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetWaitingEntries", 5, start, start.Add(time.Hour), mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+	mockRepo.On("GetSeriesByID", 9).Return(&booking.BookingSeries{ID: 9}, nil)
+	mockRepo.On("GetBookingsBySeriesID", 9).Return([]booking.Booking{occurrence}, nil)
+
+	newEnd := newStart.Add(time.Hour)
+	_, err := svc.UpdateSeriesOccurrences(1, &booking.SeriesOccurrenceUpdate{Scope: booking.ScopeThis, StartTime: &newStart, EndTime: &newEnd}, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSeriesOccurrences_MoveOnInstantResourceStaysApproved(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	seriesID := 9
+	start := nextWeekdayAt(10)
+	occurrences := []booking.Booking{
+		{ID: 1, SeriesID: &seriesID, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusApproved},
+		// Left pending from before the resource stopped requiring approval
+		{ID: 2, SeriesID: &seriesID, ResourceID: 5, UserID: "owner", StartTime: start.AddDate(0, 0, 7), EndTime: start.AddDate(0, 0, 7).Add(time.Hour), Status: booking.StatusPending},
+	}
+	anchor := occurrences[0]
+	mockRepo.On("GetBookingByID", 1).Return(&anchor, nil)
+	mockRepo.On("GetBookingsBySeriesID", 9).Return(occurrences, nil)
+	mockRepo.On("GetResourceByID", 5).Return(&resource.Resource{ID: 5, IsActive: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 5, mock.Anything, mock.Anything, 1, mock.Anything).Return(false, nil)
+	mockRepo.On("UpdateBookingSchedules", mock.MatchedBy(func(bs []booking.Booking) bool {
+		return len(bs) == 2 && bs[0].Status == booking.StatusApproved && bs[1].Status == booking.StatusApproved && bs[1].ApprovedAt != nil
+	}), []booking.BookingEvent{{BookingID: 2, FromStatus: booking.StatusPending, ToStatus: booking.StatusApproved, Actor: booking.ActorSystem,
+		Reason: "Series occurrence moved onto a resource that confirms instantly"}}).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetWaitingEntries", 5, mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+	mockRepo.On("GetSeriesByID", 9).Return(&booking.BookingSeries{ID: 9}, nil)
+	mockRepo.On("UpdateSeries", mock.AnythingOfType("*booking.BookingSeries")).Return(nil)
+
+	newStart := start.Add(2 * time.Hour)
+	newEnd := newStart.Add(time.Hour)
+	_, err := svc.UpdateSeriesOccurrences(1, &booking.SeriesOccurrenceUpdate{Scope: booking.ScopeAll, StartTime: &newStart, EndTime: &newEnd}, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBundleStatusChanges_RecordActor(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBundleByID", 4).Return(&booking.BookingBundle{ID: 4, UserID: "owner", Status: booking.StatusPending}, nil).Once()
+	mockRepo.On("GetBundleByID", 4).Return(&booking.BookingBundle{ID: 4, UserID: "owner", Status: booking.StatusApproved}, nil).Once()
+	mockRepo.On("GetBookingsByBundleID", 4).Return([]booking.Booking{}, nil)
+	mockRepo.On("UpdateBundleStatus", mock.AnythingOfType("*booking.BookingBundle"), "admin", "Rooms closed").Return(nil).Once()
+	mockRepo.On("UpdateBundleStatus", mock.AnythingOfType("*booking.BookingBundle"), "owner", "Bundle cancelled by user").Return(nil).Once()
+
+	err := svc.UpdateBundleStatus(4, &booking.BookingStatusUpdate{Status: booking.StatusRejected, RejectionReason: "Rooms closed"}, "admin")
+	assert.NoError(t, err)
+	err = svc.CancelBundle(4, "owner")
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
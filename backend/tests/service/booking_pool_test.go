@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPeakQuantity(t *testing.T) {
+	base := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	bookings := []booking.Booking{
+		{StartTime: at(0), EndTime: at(2), Quantity: 3},
+		{StartTime: at(1), EndTime: at(3), Quantity: 2},
+		// Starts exactly when the first one ends: doesn't stack on it
+		{StartTime: at(2), EndTime: at(4), Quantity: 4},
+		// Legacy booking without a quantity counts as one unit
+		{StartTime: at(5), EndTime: at(6)},
+	}
+
+	assert.Equal(t, 6, booking.PeakQuantity(bookings, at(0), at(6)))
+	assert.Equal(t, 5, booking.PeakQuantity(bookings, at(0), at(2)))
+	assert.Equal(t, 1, booking.PeakQuantity(bookings, at(4), at(6)))
+	assert.Equal(t, 0, booking.PeakQuantity(bookings, at(6), at(7)))
+}
+
+func TestCreateBooking_PooledQuantity(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	req := &booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: endTime, Purpose: "Workshop", Quantity: 5}
+
+	mockRepo.On("GetResourceByID", 30).Return(&resource.Resource{ID: 30, IsActive: true, RequiresApproval: true, Quantity: 20}, nil)
+	mockRepo.On("HasApprovedOverlap", 30, startTime, endTime, 5).Return(false, nil)
+	mockRepo.On("CreateBooking", mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.Quantity == 5 && b.Status == booking.StatusPending
+	})).Return(nil, 400)
+	mockRepo.On("GetBookingByID", 400).Return(&booking.Booking{
+		ID: 400, ResourceID: 30, Quantity: 5, Status: booking.StatusPending, StartTime: startTime, EndTime: endTime,
+	}, nil)
+
+	summary, err := svc.CreateBooking(req, "user-uuid")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, summary.Quantity)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBooking_QuantityExceedsPool(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	req := &booking.BookingCreate{ResourceID: 30, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "Workshop", Quantity: 25}
+	mockRepo.On("GetResourceByID", 30).Return(&resource.Resource{ID: 30, IsActive: true, Quantity: 20}, nil)
+
+	_, err := svc.CreateBooking(req, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "HasApprovedOverlap", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func approvedBooking(start time.Time, hours int) *booking.Booking {
+	approver := "admin"
+	approvedAt := time.Now()
+	return &booking.Booking{
+		ID: 50, ResourceID: 4, UserID: "owner", StartTime: start, EndTime: start.Add(time.Duration(hours) * time.Hour),
+		Purpose: "Planning", Status: booking.StatusApproved, ApprovedBy: &approver, ApprovedAt: &approvedAt,
+		Resource: resource.Resource{Name: "Board Room"}, User: user.User{Email: "owner@test.com"},
+	}
+}
+
+func TestRescheduleBooking_ShrinkKeepsApproval(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(approvedBooking(start, 3), nil).Once()
+	newEnd := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 4).Return(&resource.Resource{ID: 4, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 4, start, newEnd, 1, 50).Return(false, nil)
+	mockRepo.On("RescheduleBooking", mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.Status == booking.StatusApproved && b.ApprovedBy != nil && b.EndTime.Equal(newEnd)
+	}), mock.MatchedBy(func(c *booking.BookingChange) bool {
+		return c.OldStatus == booking.StatusApproved && c.NewStatus == booking.StatusApproved && c.ChangedBy == "owner"
+	}), (*booking.BookingEvent)(nil)).Return([]booking.Booking{}, nil)
+	// The freed 11:00 - 13:00 part is offered to the waitlist
+	mockRepo.On("GetWaitingEntries", 4, start, start.Add(3*time.Hour), mock.Anything).Return([]booking.WaitlistEntry{}, nil)
+	shrunk := approvedBooking(start, 1)
+	mockRepo.On("GetBookingByID", 50).Return(shrunk, nil).Once()
+
+	summary, err := svc.RescheduleBooking(50, &booking.BookingReschedule{EndTime: &newEnd}, "owner")
+
+	assert.NoError(t, err)
+	assert.Equal(t, booking.StatusApproved, summary.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRescheduleBooking_MoveNeedsReapproval(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(approvedBooking(start, 1), nil)
+	newStart := start.Add(3 * time.Hour)
+	newEnd := newStart.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 4).Return(&resource.Resource{ID: 4, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 4, newStart, newEnd, 1, 50).Return(false, nil)
+	mockRepo.On("RescheduleBooking", mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.Status == booking.StatusPending && b.ApprovedBy == nil && b.ApprovedAt == nil
+	}), mock.AnythingOfType("*booking.BookingChange"), mock.MatchedBy(func(e *booking.BookingEvent) bool {
+		// The history shows the owner sending the booking back for approval
+		return e.BookingID == 50 && e.FromStatus == booking.StatusApproved && e.ToStatus == booking.StatusPending && e.Actor == "owner"
+	})).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetWaitingEntries", 4, start, start.Add(time.Hour), mock.Anything).Return([]booking.WaitlistEntry{}, nil)
+
+	_, err := svc.RescheduleBooking(50, &booking.BookingReschedule{StartTime: &newStart, EndTime: &newEnd}, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRescheduleBooking_ReapprovePolicyOnShrink(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+	svc.ReschedulePolicy = booking.ReapproveOnChange
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(approvedBooking(start, 2), nil)
+	newEnd := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 4).Return(&resource.Resource{ID: 4, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 4, start, newEnd, 1, 50).Return(false, nil)
+	mockRepo.On("RescheduleBooking", mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.Status == booking.StatusPending
+	}), mock.AnythingOfType("*booking.BookingChange"), mock.AnythingOfType("*booking.BookingEvent")).Return([]booking.Booking{}, nil)
+	mockRepo.On("GetWaitingEntries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]booking.WaitlistEntry{}, nil)
+
+	_, err := svc.RescheduleBooking(50, &booking.BookingReschedule{EndTime: &newEnd}, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRescheduleBooking_Conflict(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(approvedBooking(start, 1), nil)
+	newStart := start.Add(2 * time.Hour)
+	newEnd := newStart.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 4).Return(&resource.Resource{ID: 4, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 4, newStart, newEnd, 1, 50).Return(true, nil)
+	mockRepo.On("GetFutureApprovedBookings", 4, newStart).Return([]booking.Booking{}, nil)
+
+	_, err := svc.RescheduleBooking(50, &booking.BookingReschedule{StartTime: &newStart, EndTime: &newEnd}, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Contains(t, err.Error(), "slot unavailable")
+	mockRepo.AssertNotCalled(t, "RescheduleBooking")
+}
+
+func TestRescheduleBooking_NotOwner(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBookingByID", 50).Return(approvedBooking(nextWeekdayAt(10), 1), nil)
+	purpose := "Hijacked"
+
+	_, err := svc.RescheduleBooking(50, &booking.BookingReschedule{Purpose: &purpose}, "someone-else")
+
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+	mockRepo.AssertNotCalled(t, "RescheduleBooking")
+}
@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseRecurrenceRule_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{"Empty", ""},
+		{"Missing FREQ", "COUNT=3"},
+		{"Unsupported FREQ", "FREQ=YEARLY;COUNT=3"},
+		{"Unbounded", "FREQ=DAILY"},
+		{"Count and Until", "FREQ=DAILY;COUNT=3;UNTIL=20270101"},
+		{"Bad weekday", "FREQ=WEEKLY;BYDAY=XX;COUNT=3"},
+		{"Ordinal on weekly", "FREQ=WEEKLY;BYDAY=1MO;COUNT=3"},
+		{"Too many", "FREQ=DAILY;COUNT=1000"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := booking.ParseRecurrenceRule(tc.rule)
+			assert.ErrorIs(t, err, utils.ErrInvalidInput)
+		})
+	}
+}
+
+func TestRecurrenceRule_ExpandWeekly(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Kolkata")
+	rule, err := booking.ParseRecurrenceRule("RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5")
+	assert.NoError(t, err)
+
+	// Monday 4 Jan 2027, 10:00 IST
+	start := time.Date(2027, 1, 4, 10, 0, 0, 0, loc)
+	got := rule.Expand(start)
+
+	want := []time.Time{
+		time.Date(2027, 1, 4, 10, 0, 0, 0, loc),
+		time.Date(2027, 1, 6, 10, 0, 0, 0, loc),
+		time.Date(2027, 1, 11, 10, 0, 0, 0, loc),
+		time.Date(2027, 1, 13, 10, 0, 0, 0, loc),
+		time.Date(2027, 1, 18, 10, 0, 0, 0, loc),
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestRecurrenceRule_ExpandMonthlyLastFriday(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Kolkata")
+	rule, err := booking.ParseRecurrenceRule("FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20270331")
+	assert.NoError(t, err)
+
+	got := rule.Expand(time.Date(2027, 1, 1, 14, 0, 0, 0, loc))
+
+	want := []time.Time{
+		time.Date(2027, 1, 29, 14, 0, 0, 0, loc),
+		time.Date(2027, 2, 26, 14, 0, 0, 0, loc),
+		time.Date(2027, 3, 26, 14, 0, 0, 0, loc),
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestCreateBookingSeries_SkipsConflictingOccurrence(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	loc, _ := time.LoadLocation("Asia/Kolkata")
+	now := time.Now().In(loc)
+	// Next Monday (at least one day away), 10:00 - 11:00
+	first := time.Date(now.Year(), now.Month(), now.Day()+1, 10, 0, 0, 0, loc)
+	for first.Weekday() != time.Monday {
+		first = first.AddDate(0, 0, 1)
+	}
+	second := first.AddDate(0, 0, 7)
+	third := first.AddDate(0, 0, 14)
+
+	req := &booking.BookingCreate{
+		ResourceID: 7,
+		StartTime:  first,
+		EndTime:    first.Add(time.Hour),
+		Purpose:    "Weekly sync",
+		Recurrence: "FREQ=WEEKLY;BYDAY=MO;COUNT=3",
+	}
+
+	mockRepo.On("GetResourceByID", 7).Return(&resource.Resource{ID: 7, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 7, first, first.Add(time.Hour), 1, 0).Return(false, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 7, second, second.Add(time.Hour), 1, 0).Return(true, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 7, third, third.Add(time.Hour), 1, 0).Return(false, nil)
+	mockRepo.On("CreateBookingSeries", mock.AnythingOfType("*booking.BookingSeries"), mock.MatchedBy(func(bs []booking.Booking) bool {
+		return len(bs) == 2 && bs[0].StartTime.Equal(first) && bs[1].StartTime.Equal(third)
+	})).Return([]booking.Booking{}, nil, 55)
+
+	seriesID := 55
+	mockRepo.On("GetBookingsBySeriesID", 55).Return([]booking.Booking{
+		{ID: 1, SeriesID: &seriesID, StartTime: first, EndTime: first.Add(time.Hour), Status: booking.StatusPending,
+			Resource: resource.Resource{Name: "Room"}, User: user.User{Name: "Test User", Email: "test@example.com"}},
+		{ID: 2, SeriesID: &seriesID, StartTime: third, EndTime: third.Add(time.Hour), Status: booking.StatusPending,
+			Resource: resource.Resource{Name: "Room"}, User: user.User{Name: "Test User", Email: "test@example.com"}},
+	}, nil)
+
+	result, err := svc.CreateBookingSeries(req, "user-uuid")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 55, result.Series.ID)
+	assert.Len(t, result.Created, 2)
+	assert.Len(t, result.Skipped, 1)
+	assert.True(t, result.Skipped[0].StartTime.Equal(second))
+	assert.Equal(t, "slot unavailable", result.Skipped[0].Reason)
+	assert.Equal(t, booking.PathApprovalRequired, result.ConfirmationPath)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelSeriesOccurrences_Following(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	seriesID := 9
+	base := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	occurrences := []booking.Booking{
+		{ID: 1, SeriesID: &seriesID, UserID: "owner", StartTime: base, EndTime: base.Add(time.Hour), Status: booking.StatusApproved},
+		{ID: 2, SeriesID: &seriesID, UserID: "owner", StartTime: base.AddDate(0, 0, 7), EndTime: base.AddDate(0, 0, 7).Add(time.Hour), Status: booking.StatusPending},
+		{ID: 3, SeriesID: &seriesID, UserID: "owner", StartTime: base.AddDate(0, 0, 14), EndTime: base.AddDate(0, 0, 14).Add(time.Hour), Status: booking.StatusRejected},
+		{ID: 4, SeriesID: &seriesID, UserID: "owner", StartTime: base.AddDate(0, 0, 21), EndTime: base.AddDate(0, 0, 21).Add(time.Hour), Status: booking.StatusApproved},
+	}
+	anchor := occurrences[1]
+	mockRepo.On("GetBookingByID", 2).Return(&anchor, nil)
+	mockRepo.On("GetBookingsBySeriesID", 9).Return(occurrences, nil)
+	// Occurrence 4 was cancelled from another session in the meantime: only 2 changes
+	mockRepo.On("UpdateBookingsStatus", []int{2, 4}, []booking.BookingStatus{booking.StatusApproved, booking.StatusPending}, booking.StatusCancelled, "Cancelled by user", "owner").
+		Return([]booking.Booking{occurrences[1]}, nil)
+	mockRepo.On("GetWaitingEntries", 0, occurrences[1].StartTime, occurrences[1].EndTime, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+
+	cancelled, err := svc.CancelSeriesOccurrences(2, booking.ScopeFollowing, "owner")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelSeriesOccurrences_NotOwner(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	seriesID := 9
+	mockRepo.On("GetBookingByID", 2).Return(&booking.Booking{ID: 2, SeriesID: &seriesID, UserID: "owner"}, nil)
+
+	_, err := svc.CancelSeriesOccurrences(2, booking.ScopeAll, "someone-else")
+
+	assert.ErrorIs(t, err, utils.ErrUnauthorized)
+	mockRepo.AssertNotCalled(t, "UpdateBookingsStatus")
+}
+
+func TestUpdateSeriesOccurrences_WritesOccurrencesTogether(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	seriesID := 9
+	base := nextWeekdayAt(10)
+	occurrences := []booking.Booking{
+		{ID: 1, SeriesID: &seriesID, ResourceID: 5, UserID: "owner", StartTime: base, EndTime: base.Add(time.Hour), Status: booking.StatusPending},
+		{ID: 2, SeriesID: &seriesID, ResourceID: 5, UserID: "owner", StartTime: base.AddDate(0, 0, 7), EndTime: base.AddDate(0, 0, 7).Add(time.Hour), Status: booking.StatusPending},
+	}
+	anchor := occurrences[0]
+	mockRepo.On("GetBookingByID", 1).Return(&anchor, nil)
+	mockRepo.On("GetBookingsBySeriesID", 9).Return(occurrences, nil)
+	// Both occurrences go to the repository in one call, which fails as a whole
+	mockRepo.On("UpdateBookingSchedules", mock.MatchedBy(func(bs []booking.Booking) bool {
+		return len(bs) == 2 && bs[0].Purpose == "Retro" && bs[1].Purpose == "Retro"
+	}), []booking.BookingEvent(nil)).Return([]booking.Booking(nil), errors.New("connection lost"))
+
+	purpose := "Retro"
+	_, err := svc.UpdateSeriesOccurrences(1, &booking.SeriesOccurrenceUpdate{Scope: booking.ScopeAll, Purpose: &purpose}, "owner")
+
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetSeriesByID", mock.Anything)
+}
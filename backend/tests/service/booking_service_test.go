@@ -34,8 +34,8 @@ func (m *MockBookingRepo) GetBookingByID(id int) (*booking.Booking, error) {
 	return nil, args.Error(1)
 }
 
-func (m *MockBookingRepo) HasApprovedOverlap(resourceID int, start, end time.Time) (bool, error) {
-	args := m.Called(resourceID, start, end)
+func (m *MockBookingRepo) HasApprovedOverlap(resourceID int, start, end time.Time, quantity int) (bool, error) {
+	args := m.Called(resourceID, start, end, quantity)
 	return args.Bool(0), args.Error(1)
 }
 
@@ -68,21 +68,29 @@ func (m *MockBookingRepo) GetFutureApprovedBookings(resourceID int, startTime ti
 	return nil, args.Error(1)
 }
 
-func (m *MockBookingRepo) CheckInBooking(bookingId int) error {
-	return m.Called(bookingId).Error(0)
+func (m *MockBookingRepo) CheckInBooking(bookingId int, actor string) error {
+	return m.Called(bookingId, actor).Error(0)
 }
-func (m *MockBookingRepo) ReleaseUncheckedBookings(cutoffTime time.Time) error {
-	return m.Called(cutoffTime).Error(0)
+func (m *MockBookingRepo) ReleaseUncheckedBookings(cutoffTime time.Time) ([]booking.Booking, error) {
+	args := m.Called(cutoffTime)
+	if val := args.Get(0); val != nil {
+		return val.([]booking.Booking), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
-func (m *MockBookingRepo) GetApprovedBookingsStartingAt(startTime time.Time) ([]booking.Booking, error) {
-	args := m.Called(startTime)
+func (m *MockBookingRepo) GetApprovedBookingsStartingBetween(from, to time.Time) ([]booking.Booking, error) {
+	args := m.Called(from, to)
 	if val := args.Get(0); val != nil {
 		return val.([]booking.Booking), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
-func (m *MockBookingRepo) CancelExpiredPendingBookings(cutoffTime time.Time) error {
-	return m.Called(cutoffTime).Error(0)
+func (m *MockBookingRepo) CancelExpiredPendingBookings(cutoffTime time.Time) ([]booking.Booking, error) {
+	args := m.Called(cutoffTime)
+	if val := args.Get(0); val != nil {
+		return val.([]booking.Booking), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 func (m *MockBookingRepo) GetTopBookedResources(limit int) ([]booking.DashboardResourceStat, error) {
@@ -101,6 +109,181 @@ func (m *MockBookingRepo) GetTopReleasingUsers(limit int) ([]booking.DashboardUs
 	return nil, args.Error(1)
 }
 
+func (m *MockBookingRepo) HasApprovedOverlapExcluding(resourceID int, start, end time.Time, quantity, excludeID int) (bool, error) {
+	args := m.Called(resourceID, start, end, quantity, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockBookingRepo) CreateBookingSeries(series *booking.BookingSeries, bookings []booking.Booking) ([]booking.Booking, error) {
+	args := m.Called(series, bookings)
+	if id := args.Int(2); id != 0 {
+		series.ID = id
+	}
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) GetSeriesByID(id int) (*booking.BookingSeries, error) {
+	args := m.Called(id)
+	if val := args.Get(0); val != nil {
+		return val.(*booking.BookingSeries), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) UpdateSeries(series *booking.BookingSeries) error {
+	return m.Called(series).Error(0)
+}
+func (m *MockBookingRepo) GetBookingsBySeriesID(seriesID int) ([]booking.Booking, error) {
+	args := m.Called(seriesID)
+	if val := args.Get(0); val != nil {
+		return val.([]booking.Booking), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) UpdateBookingsStatus(ids []int, from []booking.BookingStatus, status booking.BookingStatus, reason, actor string) ([]booking.Booking, error) {
+	args := m.Called(ids, from, status, reason, actor)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) UpdateBookingSchedules(bookings []booking.Booking, events []booking.BookingEvent) ([]booking.Booking, error) {
+	args := m.Called(bookings, events)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepo) CreateBundle(bundle *booking.BookingBundle, bookings []booking.Booking) ([]booking.Booking, error) {
+	args := m.Called(bundle, bookings)
+	if id := args.Int(2); id != 0 {
+		bundle.ID = id
+	}
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) GetBundleByID(id int) (*booking.BookingBundle, error) {
+	args := m.Called(id)
+	if val := args.Get(0); val != nil {
+		return val.(*booking.BookingBundle), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) GetBookingsByBundleID(bundleID int) ([]booking.Booking, error) {
+	args := m.Called(bundleID)
+	if val := args.Get(0); val != nil {
+		return val.([]booking.Booking), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) ApproveBundleAndRejectConflicts(bundle *booking.BookingBundle) ([]booking.Booking, error) {
+	args := m.Called(bundle)
+	if val := args.Get(0); val != nil {
+		return val.([]booking.Booking), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) UpdateBundleStatus(bundle *booking.BookingBundle, actor, reason string) error {
+	return m.Called(bundle, actor, reason).Error(0)
+}
+
+func (m *MockBookingRepo) CreateWaitlistEntry(entry *booking.WaitlistEntry) error {
+	return m.Called(entry).Error(0)
+}
+func (m *MockBookingRepo) GetWaitlistEntryByID(id int) (*booking.WaitlistEntry, error) {
+	args := m.Called(id)
+	if val := args.Get(0); val != nil {
+		return val.(*booking.WaitlistEntry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) GetWaitlistEntriesByUserID(userID string, pagination utils.PaginationQuery) ([]booking.WaitlistEntry, int64, error) {
+	args := m.Called(userID, pagination)
+	return args.Get(0).([]booking.WaitlistEntry), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockBookingRepo) UpdateWaitlistEntry(entry *booking.WaitlistEntry) error {
+	return m.Called(entry).Error(0)
+}
+func (m *MockBookingRepo) GetWaitingEntries(resourceID int, start, end, now time.Time) ([]booking.WaitlistEntry, error) {
+	args := m.Called(resourceID, start, end, now)
+	if val := args.Get(0); val != nil {
+		return val.([]booking.WaitlistEntry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockBookingRepo) PromoteWaitlistEntry(entry *booking.WaitlistEntry, b *booking.Booking) error {
+	args := m.Called(entry, b)
+	if id := args.Int(1); id != 0 {
+		b.ID = id
+	}
+	return args.Error(0)
+}
+func (m *MockBookingRepo) ExpireWaitlistEntries(now time.Time) error {
+	return m.Called(now).Error(0)
+}
+func (m *MockBookingRepo) GetActiveResourcesByType(typeID int) ([]resource.Resource, error) {
+	args := m.Called(typeID)
+	return args.Get(0).([]resource.Resource), args.Error(1)
+}
+func (m *MockBookingRepo) GetResourcesByIDs(ids []int) ([]resource.Resource, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]resource.Resource), args.Error(1)
+}
+func (m *MockBookingRepo) GetBookingsInRange(resourceIDs []int, statuses []booking.BookingStatus, from, to time.Time) ([]booking.Booking, error) {
+	args := m.Called(resourceIDs, statuses, from, to)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) GetFutureApprovedBookingsByUsers(userIDs []string, startTime time.Time) ([]booking.Booking, error) {
+	args := m.Called(userIDs, startTime)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) GetUsersByUUIDs(uuids []string) ([]user.User, error) {
+	args := m.Called(uuids)
+	return args.Get(0).([]user.User), args.Error(1)
+}
+func (m *MockBookingRepo) GetBookingsByAttendee(userID string, filters map[string]interface{}, pagination utils.PaginationQuery) ([]booking.Booking, int64, error) {
+	args := m.Called(userID, filters, pagination)
+	return args.Get(0).([]booking.Booking), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockBookingRepo) UpdateBookingStatus(b *booking.Booking, event *booking.BookingEvent) error {
+	return m.Called(b, event).Error(0)
+}
+func (m *MockBookingRepo) GetBookingEvents(bookingID int) ([]booking.BookingEvent, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).([]booking.BookingEvent), args.Error(1)
+}
+func (m *MockBookingRepo) GetPendingForAllocation(resourceID, resourceTypeID *int, from, to time.Time) ([]booking.Booking, error) {
+	args := m.Called(resourceID, resourceTypeID, from, to)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) CommitAllocation(approveIDs, rejectIDs []int, approverID string, now time.Time) ([]booking.Booking, []booking.Booking, error) {
+	args := m.Called(approveIDs, rejectIDs, approverID, now)
+	return args.Get(0).([]booking.Booking), args.Get(1).([]booking.Booking), args.Error(2)
+}
+func (m *MockBookingRepo) GetCheckInCandidate(resourceID int, now time.Time, window time.Duration) (*booking.Booking, error) {
+	args := m.Called(resourceID, now, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) ExtendBooking(b *booking.Booking, newEnd time.Time) ([]booking.Booking, error) {
+	args := m.Called(b, newEnd)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) CheckOutBooking(bookingID int, checkedOutAt time.Time) error {
+	return m.Called(bookingID, checkedOutAt).Error(0)
+}
+func (m *MockBookingRepo) RescheduleBooking(b *booking.Booking, change *booking.BookingChange, event *booking.BookingEvent) ([]booking.Booking, error) {
+	args := m.Called(b, change, event)
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+func (m *MockBookingRepo) GetResourceByID(id int) (*resource.Resource, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resource.Resource), args.Error(1)
+}
+func (m *MockBookingRepo) CreateApprovedBooking(b *booking.Booking) ([]booking.Booking, error) {
+	args := m.Called(b)
+	if id := args.Int(2); id != 0 {
+		b.ID = id
+	}
+	return args.Get(0).([]booking.Booking), args.Error(1)
+}
+
 // --- TEST SUITE ---
 
 func TestCreateBooking_Success(t *testing.T) {
@@ -129,8 +312,11 @@ func TestCreateBooking_Success(t *testing.T) {
 	}
 
 	// 2. Expectations
+	// Expect Resource lookup -> Active and requires approval
+	mockRepo.On("GetResourceByID", 101).Return(&resource.Resource{ID: 101, IsActive: true, RequiresApproval: true}, nil)
+
 	// Expect Overlap check -> Returns false (No overlap)
-	mockRepo.On("HasApprovedOverlap", 101, startTime, endTime).Return(false, nil)
+	mockRepo.On("HasApprovedOverlap", 101, startTime, endTime, 1).Return(false, nil)
 
 	// Expect Create -> Returns success
 	// We use mock.AnythingOfType because the object pointer changes
@@ -155,6 +341,7 @@ func TestCreateBooking_Success(t *testing.T) {
 	assert.NotNil(t, summary)
 	assert.Equal(t, 123, summary.ID)
 	assert.Equal(t, "Test Room", summary.ResourceName)
+	assert.Equal(t, booking.PathApprovalRequired, summary.ConfirmationPath)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -166,6 +353,9 @@ func TestCreateBooking_Conflict(t *testing.T) {
 	loc, _ := time.LoadLocation("Asia/Kolkata")
 	now := time.Now().In(loc)
 	startTime := time.Date(now.Year(), now.Month(), now.Day()+1, 10, 0, 0, 0, loc)
+	for startTime.Weekday() == time.Saturday || startTime.Weekday() == time.Sunday {
+		startTime = startTime.AddDate(0, 0, 1)
+	}
 	endTime := startTime.Add(1 * time.Hour)
 
 	req := &booking.BookingCreate{
@@ -175,8 +365,11 @@ func TestCreateBooking_Conflict(t *testing.T) {
 		Purpose:    "Conflict Test",
 	}
 
+	mockRepo.On("GetResourceByID", 101).Return(&resource.Resource{ID: 101, IsActive: true, RequiresApproval: true}, nil)
+
 	// Expect Overlap check -> Returns TRUE (Conflict exists)
-	mockRepo.On("HasApprovedOverlap", 101, startTime, endTime).Return(true, nil)
+	mockRepo.On("HasApprovedOverlap", 101, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetActiveResourcesByType", 0).Return([]resource.Resource{}, nil)
 
 	// Expect GetFutureApprovedBookings (Service tries to find suggestions)
 	// Return empty list implies no suggestions found
@@ -190,3 +383,56 @@ func TestCreateBooking_Conflict(t *testing.T) {
 
 	mockRepo.AssertNotCalled(t, "CreateBooking") // Should NOT trigger creation
 }
+
+func TestCreateBooking_InstantConfirm(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(11)
+	endTime := startTime.Add(1 * time.Hour)
+
+	req := &booking.BookingCreate{
+		ResourceID: 202,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Purpose:    "Quick call",
+	}
+
+	mockRepo.On("GetResourceByID", 202).Return(&resource.Resource{ID: 202, IsActive: true, RequiresApproval: false}, nil)
+	mockRepo.On("HasApprovedOverlap", 202, startTime, endTime, 1).Return(false, nil)
+	// Booking must reach the repository already approved
+	mockRepo.On("CreateApprovedBooking", mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.Status == booking.StatusApproved && b.ApprovedAt != nil
+	})).Return([]booking.Booking{}, nil, 321)
+	mockRepo.On("GetBookingByID", 321).Return(&booking.Booking{
+		ID:         321,
+		ResourceID: 202,
+		Status:     booking.StatusApproved,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Resource:   resource.Resource{Name: "Phone Booth"},
+		User:       user.User{Name: "Test User", Email: "test@example.com"},
+	}, nil)
+
+	summary, err := svc.CreateBooking(req, "user-uuid")
+
+	assert.NoError(t, err)
+	assert.Equal(t, booking.StatusApproved, summary.Status)
+	assert.Equal(t, booking.PathInstant, summary.ConfirmationPath)
+	mockRepo.AssertNotCalled(t, "CreateBooking")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBooking_InactiveResource(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(11)
+	req := &booking.BookingCreate{ResourceID: 5, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "x"}
+	mockRepo.On("GetResourceByID", 5).Return(&resource.Resource{ID: 5, IsActive: false}, nil)
+
+	_, err := svc.CreateBooking(req, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "HasApprovedOverlap")
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func phoneBooth() *resource.Resource {
+	return &resource.Resource{ID: 12, IsActive: true, RequiresApproval: true,
+		Type: &resource.ResourceType{SlotMinutes: 15, MaxDurationMinutes: 60}}
+}
+
+func TestCreateBooking_QuarterHourSlot(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10).Add(15 * time.Minute)
+	endTime := startTime.Add(30 * time.Minute)
+	mockRepo.On("GetResourceByID", 12).Return(phoneBooth(), nil)
+	mockRepo.On("HasApprovedOverlap", 12, startTime, endTime, 1).Return(false, nil)
+	mockRepo.On("CreateBooking", mock.AnythingOfType("*booking.Booking")).Return(nil, 500)
+	mockRepo.On("GetBookingByID", 500).Return(&booking.Booking{ID: 500, ResourceID: 12, StartTime: startTime, EndTime: endTime, Status: booking.StatusPending}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 12, StartTime: startTime, EndTime: endTime, Purpose: "Call"}, "user-uuid")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBooking_SlotRulesViolations(t *testing.T) {
+	base := nextWeekdayAt(10)
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		msg   string
+	}{
+		{"Off the slot boundary", base.Add(10 * time.Minute), base.Add(40 * time.Minute), "15 minutes boundary"},
+		{"Not a whole number of slots", base, base.Add(20 * time.Minute), "multiples of 15 minutes"},
+		{"Longer than the maximum", base, base.Add(90 * time.Minute), "longer than 1 hour"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockBookingRepo)
+			svc := booking.NewBookingService(mockRepo)
+			mockRepo.On("GetResourceByID", 12).Return(phoneBooth(), nil)
+
+			_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 12, StartTime: tc.start, EndTime: tc.end, Purpose: "Call"}, "user-uuid")
+
+			assert.ErrorIs(t, err, utils.ErrInvalidInput)
+			assert.Contains(t, err.Error(), tc.msg)
+			mockRepo.AssertNotCalled(t, "HasApprovedOverlap", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateBooking_MinimumDuration(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	minDur := 180
+	lab := &resource.Resource{ID: 13, IsActive: true, MinDurationMinutes: &minDur}
+	startTime := nextWeekdayAt(9)
+	mockRepo.On("GetResourceByID", 13).Return(lab, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 13, StartTime: startTime, EndTime: startTime.Add(2 * time.Hour), Purpose: "Assay"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "at least 3 hours")
+}
+
+func TestCreateBooking_SuggestionsFollowSlotGranularity(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(15 * time.Minute)
+	mockRepo.On("GetResourceByID", 12).Return(phoneBooth(), nil)
+	mockRepo.On("HasApprovedOverlap", 12, startTime, endTime, 1).Return(true, nil)
+	mockRepo.On("GetActiveResourcesByType", 0).Return([]resource.Resource{}, nil)
+	// Taken until 10:30, so the next quarter hours are offered
+	mockRepo.On("GetFutureApprovedBookings", 12, startTime).Return([]booking.Booking{
+		{ResourceID: 12, StartTime: startTime, EndTime: startTime.Add(30 * time.Minute), Status: booking.StatusApproved},
+	}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 12, StartTime: startTime, EndTime: endTime, Purpose: "Call"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	var conflict *booking.SlotConflictError
+	assert.True(t, errors.As(err, &conflict))
+	var starts []string
+	for _, slot := range conflict.SuggestedSlots {
+		starts = append(starts, slot.StartTime.Format("15:04"))
+		assert.Equal(t, 15*time.Minute, slot.EndTime.Sub(slot.StartTime))
+	}
+	assert.Equal(t, []string{"10:30", "10:45", "11:00", "11:15"}, starts)
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckTransition(t *testing.T) {
+	assert.NoError(t, booking.CheckTransition(booking.StatusPending, booking.StatusApproved, booking.TriggerAdmin))
+	assert.NoError(t, booking.CheckTransition(booking.StatusApproved, booking.StatusCancelled, booking.TriggerOwner))
+	assert.NoError(t, booking.CheckTransition(booking.StatusApproved, booking.StatusReleased, booking.TriggerSystem))
+
+	// Not a transition at all
+	assert.ErrorIs(t, booking.CheckTransition(booking.StatusPending, booking.StatusUtilized, booking.TriggerOwner), utils.ErrInvalidInput)
+	assert.ErrorIs(t, booking.CheckTransition(booking.StatusReleased, booking.StatusApproved, booking.TriggerAdmin), utils.ErrInvalidInput)
+	// A transition, but not theirs to make
+	err := booking.CheckTransition(booking.StatusPending, booking.StatusApproved, booking.TriggerOwner)
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "admin or system")
+}
+
+func TestTransitionSources(t *testing.T) {
+	assert.Equal(t, []booking.BookingStatus{booking.StatusApproved, booking.StatusPending}, booking.TransitionSources(booking.StatusCancelled, booking.TriggerOwner))
+	assert.Equal(t, []booking.BookingStatus{booking.StatusApproved}, booking.TransitionSources(booking.StatusReleased, booking.TriggerSystem))
+	assert.Empty(t, booking.TransitionSources(booking.StatusApproved, booking.TriggerOwner))
+}
+
+func TestCancelBooking_UtilizedIsFinalForOwner(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBookingByID", 50).Return(&booking.Booking{ID: 50, UserID: "owner", Status: booking.StatusUtilized}, nil)
+
+	err := svc.CancelBooking(50, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything)
+}
+
+func TestUpdateStatus_OnlyApproveOrReject(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetBookingByID", 50).Return(&booking.Booking{ID: 50, UserID: "owner", Status: booking.StatusPending}, nil)
+
+	err := svc.UpdateStatus(50, &booking.BookingStatusUpdate{Status: booking.StatusUtilized}, "admin")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ApproveBookingAndRejectConflicts", mock.Anything)
+}
+
+func TestRunAutoCancellationJob_RunsSideEffects(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Minute)
+	mockRepo.On("CancelExpiredPendingBookings", mock.AnythingOfType("time.Time")).Return([]booking.Booking{
+		{ID: 50, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusPending},
+	}, nil)
+	// Cancelling frees the slot, which is offered to the waitlist
+	mockRepo.On("GetWaitingEntries", 5, start, start.Add(time.Hour), mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{}, nil)
+
+	err := svc.RunAutoCancellationJob()
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,227 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestJoinWaitlist_SlotFree(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 5).Return(&resource.Resource{ID: 5, IsActive: true}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, start, end, 1).Return(false, nil)
+
+	_, err := svc.JoinWaitlist(&booking.WaitlistCreate{ResourceID: 5, StartTime: start, EndTime: end, Purpose: "Standup"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CreateWaitlistEntry")
+}
+
+func TestJoinWaitlist_DefaultsExpiryToEnd(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 5).Return(&resource.Resource{ID: 5, IsActive: true}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, start, end, 1).Return(true, nil)
+	mockRepo.On("CreateWaitlistEntry", mock.MatchedBy(func(e *booking.WaitlistEntry) bool {
+		return e.ExpiresAt.Equal(end) && e.Status == booking.WaitlistWaiting
+	})).Return(nil)
+
+	entry, err := svc.JoinWaitlist(&booking.WaitlistCreate{ResourceID: 5, StartTime: start, EndTime: end, Purpose: "Standup"}, "user-uuid")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-uuid", entry.UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestJoinWaitlist_PooledQuantity(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 30).Return(&resource.Resource{ID: 30, IsActive: true, Quantity: 20}, nil)
+	mockRepo.On("HasApprovedOverlap", 30, start, end, 5).Return(true, nil)
+	mockRepo.On("CreateWaitlistEntry", mock.MatchedBy(func(e *booking.WaitlistEntry) bool {
+		return e.Quantity == 5
+	})).Return(nil)
+
+	_, err := svc.JoinWaitlist(&booking.WaitlistCreate{ResourceID: 30, StartTime: start, EndTime: end, Purpose: "Workshop", Quantity: 5}, "user-uuid")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_PromotesWaitlistEntryWithQuantity(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(14)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetBookingByID", 40).Return(&booking.Booking{
+		ID: 40, ResourceID: 30, UserID: "owner", StartTime: start, EndTime: end, Quantity: 5, Status: booking.StatusApproved,
+	}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.AnythingOfType("*booking.Booking"), mock.AnythingOfType("*booking.BookingEvent")).Return(nil)
+
+	entry := booking.WaitlistEntry{ID: 1, ResourceID: 30, UserID: "early", StartTime: start, EndTime: end, Quantity: 4, Status: booking.WaitlistWaiting,
+		Resource: resource.Resource{Name: "Desks", Quantity: 20, RequiresApproval: true}, User: user.User{Email: "early@test.com"}}
+	mockRepo.On("GetWaitingEntries", 30, start, end, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{entry}, nil)
+	mockRepo.On("HasApprovedOverlap", 30, start, end, 4).Return(false, nil)
+	mockRepo.On("PromoteWaitlistEntry", mock.AnythingOfType("*booking.WaitlistEntry"),
+		mock.MatchedBy(func(b *booking.Booking) bool { return b.Quantity == 4 })).Return(nil, 99)
+
+	err := svc.CancelBooking(40, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_PromotesFirstWaitlistEntry(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(14)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetBookingByID", 40).Return(&booking.Booking{
+		ID: 40, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: end, Status: booking.StatusApproved,
+	}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.AnythingOfType("*booking.Booking"), mock.AnythingOfType("*booking.BookingEvent")).Return(nil)
+
+	// Two users waiting for the same slot: only the oldest entry should be promoted
+	first := booking.WaitlistEntry{ID: 1, ResourceID: 5, UserID: "early", StartTime: start, EndTime: end, Status: booking.WaitlistWaiting,
+		Resource: resource.Resource{Name: "Room", RequiresApproval: true}, User: user.User{Email: "early@test.com"}}
+	second := booking.WaitlistEntry{ID: 2, ResourceID: 5, UserID: "late", StartTime: start, EndTime: end, Status: booking.WaitlistWaiting}
+	mockRepo.On("GetWaitingEntries", 5, start, end, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{first, second}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, start, end, 1).Return(false, nil)
+	mockRepo.On("PromoteWaitlistEntry", mock.MatchedBy(func(e *booking.WaitlistEntry) bool { return e.ID == 1 }),
+		mock.MatchedBy(func(b *booking.Booking) bool { return b.UserID == "early" && b.Status == booking.StatusPending })).Return(nil, 99)
+
+	err := svc.CancelBooking(40, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "PromoteWaitlistEntry", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_WaitlistPromotionChecksQuota(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(14)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetBookingByID", 40).Return(&booking.Booking{
+		ID: 40, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: end, Status: booking.StatusApproved,
+	}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.AnythingOfType("*booking.Booking"), mock.AnythingOfType("*booking.BookingEvent")).Return(nil)
+
+	// The oldest entry's user is at their quota, so the next one gets the slot
+	first := booking.WaitlistEntry{ID: 1, ResourceID: 5, UserID: "early", StartTime: start, EndTime: end, Status: booking.WaitlistWaiting,
+		Resource: resource.Resource{TypeID: 3, RequiresApproval: true}}
+	second := booking.WaitlistEntry{ID: 2, ResourceID: 5, UserID: "late", StartTime: start, EndTime: end, Status: booking.WaitlistWaiting,
+		Resource: resource.Resource{TypeID: 3, RequiresApproval: true}, User: user.User{Email: "late@test.com"}}
+	mockRepo.On("GetWaitingEntries", 5, start, end, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{first, second}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, start, end, 1).Return(false, nil)
+	quotas.On("CheckBooking", "early", 3, start, end, 0).Return(utils.ErrConflict)
+	quotas.On("CheckBooking", "late", 3, start, end, 0).Return(nil)
+	mockRepo.On("PromoteWaitlistEntry", mock.MatchedBy(func(e *booking.WaitlistEntry) bool { return e.ID == 2 }),
+		mock.AnythingOfType("*booking.Booking")).Return(nil, 99)
+
+	err := svc.CancelBooking(40, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "PromoteWaitlistEntry", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunAutoReleaseJob_PromotesRestOfRunningEntry(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := time.Now().Add(-20 * time.Minute).Truncate(time.Minute)
+	end := start.Add(2 * time.Hour)
+	mockRepo.On("ReleaseUncheckedBookings", mock.AnythingOfType("time.Time")).Return([]booking.Booking{
+		{ID: 40, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: end, Status: booking.StatusApproved},
+	}, nil)
+
+	// The entry's window started with the released booking; it gets what is left of it
+	entry := booking.WaitlistEntry{ID: 1, ResourceID: 5, UserID: "waiting", StartTime: start, EndTime: end, Status: booking.WaitlistWaiting,
+		Resource: resource.Resource{Name: "Room", RequiresApproval: true}, User: user.User{Email: "waiting@test.com"}}
+	mockRepo.On("GetWaitingEntries", 5, start, end, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{entry}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, mock.MatchedBy(func(s time.Time) bool { return s.After(start) && !s.After(time.Now()) }), end, 1).Return(false, nil)
+	mockRepo.On("PromoteWaitlistEntry", mock.AnythingOfType("*booking.WaitlistEntry"), mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.StartTime.After(start) && b.EndTime.Equal(end)
+	})).Return(nil, 99)
+
+	err := svc.RunAutoReleaseJob()
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_WaitlistKeepsTurnoverBetweenPromotions(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	end := start.Add(2 * time.Hour)
+	mockRepo.On("GetBookingByID", 40).Return(&booking.Booking{
+		ID: 40, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: end, Status: booking.StatusApproved,
+	}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.AnythingOfType("*booking.Booking"), mock.AnythingOfType("*booking.BookingEvent")).Return(nil)
+
+	// 10:00 - 11:00 and 11:00 - 12:00 back to back, but the room needs 30 minutes in between
+	after := 30
+	room := resource.Resource{Name: "Wet Lab", RequiresApproval: true, BufferAfterMinutes: &after}
+	first := booking.WaitlistEntry{ID: 1, ResourceID: 5, UserID: "early", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.WaitlistWaiting, Resource: room}
+	second := booking.WaitlistEntry{ID: 2, ResourceID: 5, UserID: "late", StartTime: start.Add(time.Hour), EndTime: end, Status: booking.WaitlistWaiting, Resource: room}
+	mockRepo.On("GetWaitingEntries", 5, start, end, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{first, second}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, start, start.Add(time.Hour), 1).Return(false, nil)
+	mockRepo.On("PromoteWaitlistEntry", mock.MatchedBy(func(e *booking.WaitlistEntry) bool { return e.ID == 1 }), mock.AnythingOfType("*booking.Booking")).Return(nil, 99)
+
+	err := svc.CancelBooking(40, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "PromoteWaitlistEntry", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelBooking_PromotesOntoInstantResourceWithActor(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(14)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetBookingByID", 40).Return(&booking.Booking{
+		ID: 40, ResourceID: 5, UserID: "owner", StartTime: start, EndTime: end, Status: booking.StatusApproved,
+	}, nil)
+	mockRepo.On("UpdateBookingStatus", mock.AnythingOfType("*booking.Booking"), mock.AnythingOfType("*booking.BookingEvent")).Return(nil)
+
+	// The desk confirms instantly, so the promoted booking is approved straight away
+	entry := booking.WaitlistEntry{ID: 1, ResourceID: 5, UserID: "early", StartTime: start, EndTime: end, Status: booking.WaitlistWaiting,
+		Resource: resource.Resource{Name: "Desk", RequiresApproval: false}, User: user.User{Email: "early@test.com"}}
+	mockRepo.On("GetWaitingEntries", 5, start, end, mock.AnythingOfType("time.Time")).Return([]booking.WaitlistEntry{entry}, nil)
+	mockRepo.On("HasApprovedOverlap", 5, start, end, 1).Return(false, nil)
+	mockRepo.On("PromoteWaitlistEntry", mock.AnythingOfType("*booking.WaitlistEntry"), mock.AnythingOfType("*booking.Booking")).Return(nil, 99)
+	mockRepo.On("ApproveBookingAndRejectConflicts", mock.MatchedBy(func(b *booking.Booking) bool {
+		return b.Status == booking.StatusApproved && b.ApprovedBy != nil && *b.ApprovedBy == booking.ActorWaitlist
+	})).Return([]booking.Booking{}, nil)
+
+	err := svc.CancelBooking(40, "owner")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
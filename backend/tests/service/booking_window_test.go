@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func boardRoom() *resource.Resource {
+	return &resource.Resource{ID: 20, IsActive: true, RequiresApproval: true, Type: &resource.ResourceType{
+		BookingWindow:      resource.BookingWindow{MinLeadMinutes: 60, MaxHorizonDays: 14},
+		RoleBookingWindows: map[string]resource.BookingWindow{"ADMIN": {MaxHorizonDays: 90}},
+	}}
+}
+
+func TestCreateBooking_BeyondHorizon(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10).AddDate(0, 0, 28)
+	mockRepo.On("GetResourceByID", 20).Return(boardRoom(), nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 20, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "Offsite", Role: "EMPLOYEE"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	// The error names the moment the slot becomes bookable
+	assert.Contains(t, err.Error(), startTime.AddDate(0, 0, -14).Format("Mon, 02 Jan 2006 15:04"))
+	mockRepo.AssertNotCalled(t, "HasApprovedOverlap", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateBooking_AdminHorizonOverride(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10).AddDate(0, 0, 28)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 20).Return(boardRoom(), nil)
+	mockRepo.On("HasApprovedOverlap", 20, startTime, endTime, 1).Return(false, nil)
+	mockRepo.On("CreateBooking", mock.AnythingOfType("*booking.Booking")).Return(nil, 600)
+	mockRepo.On("GetBookingByID", 600).Return(&booking.Booking{ID: 600, ResourceID: 20, StartTime: startTime, EndTime: endTime, Status: booking.StatusPending}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 20, StartTime: startTime, EndTime: endTime, Purpose: "Offsite", Role: "ADMIN"}, "admin-uuid")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookingWindow_MinimumLeadTime(t *testing.T) {
+	w := resource.BookingWindow{MinLeadMinutes: 60}
+	start := time.Date(2030, 1, 9, 10, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, w.Check(start, start.Add(-2*time.Hour)))
+
+	err := w.Check(start, start.Add(-30*time.Minute))
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "closed at Wed, 09 Jan 2030 09:00")
+}
+
+func TestCreateBookingSeries_SkipsOccurrencesBeyondHorizon(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	// Weekly for four weeks: the last occurrences are more than 14 days ahead
+	first := nextWeekdayAt(10)
+	req := &booking.BookingCreate{
+		ResourceID: 20, StartTime: first, EndTime: first.Add(time.Hour), Purpose: "Weekly sync",
+		Recurrence: "FREQ=WEEKLY;COUNT=4", Role: "EMPLOYEE",
+	}
+	mockRepo.On("GetResourceByID", 20).Return(boardRoom(), nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 20, mock.Anything, mock.Anything, 1, 0).Return(false, nil)
+	mockRepo.On("CreateBookingSeries", mock.AnythingOfType("*booking.BookingSeries"), mock.MatchedBy(func(bs []booking.Booking) bool {
+		for _, b := range bs {
+			if b.StartTime.After(time.Now().AddDate(0, 0, 14)) {
+				return false
+			}
+		}
+		return len(bs) > 0
+	})).Return([]booking.Booking{}, nil, 55)
+	mockRepo.On("GetBookingsBySeriesID", 55).Return([]booking.Booking{}, nil)
+
+	result, err := svc.CreateBookingSeries(req, "user-uuid")
+
+	assert.NoError(t, err)
+	var beyond int
+	for _, sk := range result.Skipped {
+		if strings.Contains(sk.Reason, "up to 14 days ahead") {
+			beyond++
+		}
+	}
+	assert.GreaterOrEqual(t, beyond, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRescheduleBooking_ChecksBookingWindow(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	start := nextWeekdayAt(10)
+	b := approvedBooking(start, 1)
+	b.ResourceID = 20
+	mockRepo.On("GetBookingByID", 50).Return(b, nil)
+	mockRepo.On("GetResourceByID", 20).Return(boardRoom(), nil)
+	newStart := start.AddDate(0, 0, 28)
+	newEnd := newStart.Add(time.Hour)
+
+	_, err := svc.RescheduleBooking(50, &booking.BookingReschedule{StartTime: &newStart, EndTime: &newEnd, Role: "EMPLOYEE"}, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "up to 14 days ahead")
+	mockRepo.AssertNotCalled(t, "RescheduleBooking", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateBundle_MemberBeyondHorizon(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10).AddDate(0, 0, 28)
+	mockRepo.On("GetResourceByID", 1).Return(&resource.Resource{ID: 1, IsActive: true}, nil)
+	mockRepo.On("GetResourceByID", 20).Return(boardRoom(), nil)
+
+	_, err := svc.CreateBundle(&booking.BundleCreate{ResourceIDs: []int{1, 20}, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "Offsite", Role: "EMPLOYEE"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "resource 20")
+	mockRepo.AssertNotCalled(t, "CreateBundle", mock.Anything, mock.Anything)
+}
+
+func TestJoinWaitlist_BeyondHorizon(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	startTime := nextWeekdayAt(10).AddDate(0, 0, 28)
+	mockRepo.On("GetResourceByID", 20).Return(boardRoom(), nil)
+
+	_, err := svc.JoinWaitlist(&booking.WaitlistCreate{ResourceID: 20, StartTime: startTime, EndTime: startTime.Add(time.Hour), Purpose: "Offsite", Role: "EMPLOYEE"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CreateWaitlistEntry", mock.Anything)
+}
@@ -0,0 +1,211 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/calendar"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// --- MOCK REPOSITORY ---
+type MockCalendarRepo struct {
+	mock.Mock
+}
+
+func (m *MockCalendarRepo) CreateCalendar(cal *calendar.HolidayCalendar) error {
+	return m.Called(cal).Error(0)
+}
+func (m *MockCalendarRepo) GetCalendarByID(id int) (*calendar.HolidayCalendar, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*calendar.HolidayCalendar), args.Error(1)
+}
+func (m *MockCalendarRepo) GetAllCalendars(pagination utils.PaginationQuery) ([]calendar.HolidayCalendar, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]calendar.HolidayCalendar), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockCalendarRepo) GetCalendars() ([]calendar.HolidayCalendar, error) {
+	args := m.Called()
+	return args.Get(0).([]calendar.HolidayCalendar), args.Error(1)
+}
+func (m *MockCalendarRepo) UpdateCalendar(cal *calendar.HolidayCalendar) error {
+	return m.Called(cal).Error(0)
+}
+func (m *MockCalendarRepo) DeleteCalendar(id int) error {
+	return m.Called(id).Error(0)
+}
+func (m *MockCalendarRepo) AddHoliday(holiday *calendar.Holiday) error {
+	return m.Called(holiday).Error(0)
+}
+func (m *MockCalendarRepo) DeleteHoliday(calendarID, holidayID int) error {
+	return m.Called(calendarID, holidayID).Error(0)
+}
+func (m *MockCalendarRepo) UpsertHolidays(calendarID int, holidays []calendar.Holiday) (int, int, error) {
+	args := m.Called(calendarID, holidays)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+func (m *MockCalendarRepo) GetCalendarForLocation(location string) (*calendar.HolidayCalendar, error) {
+	args := m.Called(location)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*calendar.HolidayCalendar), args.Error(1)
+}
+func (m *MockCalendarRepo) GetHolidays(calendarID int, fromDate, toDate string) ([]calendar.Holiday, error) {
+	args := m.Called(calendarID, fromDate, toDate)
+	return args.Get(0).([]calendar.Holiday), args.Error(1)
+}
+
+// MockHolidayLookup stands in for the calendar service behind the booking service.
+type MockHolidayLookup struct {
+	mock.Mock
+}
+
+func (m *MockHolidayLookup) HolidaysFor(location string, from, to time.Time) (utils.Holidays, error) {
+	args := m.Called(location, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(utils.Holidays), args.Error(1)
+}
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20261225\r\n" +
+	"DTEND;VALUE=DATE:20261226\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"SUMMARY:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20261020\r\n" +
+	"DTEND;VALUE=DATE:20261022\r\n" +
+	"SUMMARY:Diwali\\, Govar\r\n" +
+	" dhan Puja\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20260815T000000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	holidays, skipped, err := calendar.ParseICS(strings.NewReader(sampleICS))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []calendar.Holiday{
+		{Date: "2026-12-25", Name: "Christmas"},
+		// DTEND of an all-day event is exclusive; folded lines and escapes are undone
+		{Date: "2026-10-20", Name: "Diwali, Govardhan Puja"},
+		{Date: "2026-10-21", Name: "Diwali, Govardhan Puja"},
+	}, holidays)
+	assert.Len(t, skipped, 1)
+	assert.Contains(t, skipped[0], "missing SUMMARY")
+
+	_, _, err = calendar.ParseICS(strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+}
+
+func TestImportICS_OneHolidayPerDate(t *testing.T) {
+	mockRepo := new(MockCalendarRepo)
+	svc := calendar.NewCalendarService(mockRepo)
+
+	ics := strings.Replace(sampleICS, "SUMMARY:Christmas", "SUMMARY:Christmas Day", 1) +
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20261225\r\nSUMMARY:Christmas (observed)\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	mockRepo.On("GetCalendarByID", 1).Return(&calendar.HolidayCalendar{ID: 1, Name: "India"}, nil)
+	mockRepo.On("UpsertHolidays", 1, []calendar.Holiday{
+		{CalendarID: 1, Date: "2026-12-25", Name: "Christmas Day"},
+		{CalendarID: 1, Date: "2026-10-20", Name: "Diwali, Govardhan Puja"},
+		{CalendarID: 1, Date: "2026-10-21", Name: "Diwali, Govardhan Puja"},
+	}).Return(2, 1, nil)
+
+	result, err := svc.ImportICS(1, strings.NewReader(ics))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Added)
+	assert.Equal(t, 1, result.Updated)
+	assert.Len(t, result.Skipped, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateCalendar_LocationAlreadyCovered(t *testing.T) {
+	mockRepo := new(MockCalendarRepo)
+	svc := calendar.NewCalendarService(mockRepo)
+
+	mockRepo.On("GetCalendars").Return([]calendar.HolidayCalendar{
+		{ID: 1, Name: "Karnataka", Locations: []string{"Bangalore HQ"}},
+	}, nil)
+
+	err := svc.CreateCalendar(&calendar.HolidayCalendar{Name: "Bangalore", Locations: []string{"Bangalore HQ"}})
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "CreateCalendar", mock.Anything)
+}
+
+func TestHolidaysFor_CalendarOfTheLocation(t *testing.T) {
+	mockRepo := new(MockCalendarRepo)
+	svc := calendar.NewCalendarService(mockRepo)
+
+	loc, _ := time.LoadLocation("Asia/Kolkata")
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, loc)
+	to := time.Date(2026, 10, 31, 0, 0, 0, 0, loc)
+	mockRepo.On("GetCalendarForLocation", "Pune").Return(&calendar.HolidayCalendar{ID: 3, Name: "Maharashtra"}, nil)
+	mockRepo.On("GetHolidays", 3, "2026-10-01", "2026-10-31").Return([]calendar.Holiday{
+		{CalendarID: 3, Date: "2026-10-02", Name: "Gandhi Jayanti"},
+	}, nil)
+	// No calendar for the location and no default: nothing but weekends
+	mockRepo.On("GetCalendarForLocation", "Remote").Return(nil, nil)
+
+	holidays, err := svc.HolidaysFor("Pune", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, "Gandhi Jayanti", holidays["2026-10-02"])
+
+	holidays, err = svc.HolidaysFor("Remote", from, to)
+	assert.NoError(t, err)
+	assert.Empty(t, holidays)
+}
+
+func TestCreateBooking_HolidayOfTheResourcesCalendar(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	lookup := new(MockHolidayLookup)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Holidays = lookup
+
+	start := nextWeekdayAt(10)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 8).Return(&resource.Resource{ID: 8, Location: "Pune", IsActive: true}, nil)
+	lookup.On("HolidaysFor", "Pune", start, end).Return(utils.Holidays{utils.DefaultSchedule().Date(start): "Ganesh Chaturthi"}, nil)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 8, StartTime: start, EndTime: end, Purpose: "Sync"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "Ganesh Chaturthi")
+	mockRepo.AssertNotCalled(t, "CreateBooking", mock.Anything)
+}
+
+func TestGetResourceAvailability_HolidayHasNoFreeTime(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	lookup := new(MockHolidayLookup)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Holidays = lookup
+
+	day := nextWeekdayAt(0)
+	from, to := day, day.Add(24*time.Hour)
+	mockRepo.On("GetResourceByID", 14).Return(&resource.Resource{ID: 14, Name: "Lab", Location: "Pune"}, nil)
+	mockRepo.On("GetBookingsInRange", []int{14}, booking.OccupyingStatuses, from, to).Return([]booking.Booking{}, nil)
+	lookup.On("HolidaysFor", "Pune", from, to).Return(utils.Holidays{utils.DefaultSchedule().Date(day): "Ganesh Chaturthi"}, nil)
+
+	av, err := svc.GetResourceAvailability(14, &booking.AvailabilityQuery{From: from, To: to})
+
+	assert.NoError(t, err)
+	assert.Empty(t, av.Free)
+}
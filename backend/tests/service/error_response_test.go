@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func errorResponse(t *testing.T, handle func(c *gin.Context), body string) (int, map[string]interface{}) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handle(c)
+
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestRespondError_SlotConflictCarriesSuggestions(t *testing.T) {
+	start := time.Date(2030, 1, 7, 11, 0, 0, 0, time.UTC)
+	conflict := &booking.SlotConflictError{
+		ConflictingBookingIDs: []int{70},
+		SuggestedSlots:        []booking.SuggestedSlot{{ResourceID: 14, StartTime: start, EndTime: start.Add(time.Hour)}},
+		AlternativeResources:  []booking.AlternativeResource{},
+	}
+
+	status, resp := errorResponse(t, func(c *gin.Context) { utils.RespondError(c, conflict) }, "")
+
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "conflict", resp["code"])
+	details := resp["details"].(map[string]interface{})
+	assert.Equal(t, []interface{}{70.0}, details["conflicting_booking_ids"])
+	slot := details["suggested_slots"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 14.0, slot["resource_id"])
+	assert.Equal(t, "2030-01-07T11:00:00Z", slot["start_time"])
+}
+
+func TestRespondError_CodeFollowsSentinel(t *testing.T) {
+	err := fmt.Errorf("%w: booking not found", utils.ErrNotFound)
+
+	status, resp := errorResponse(t, func(c *gin.Context) { utils.RespondError(c, err) }, "")
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "not_found", resp["code"])
+	assert.Equal(t, err.Error(), resp["error"])
+	assert.Nil(t, resp["details"])
+}
+
+func TestBindingError_ListsFields(t *testing.T) {
+	handle := func(c *gin.Context) {
+		var req booking.BookingCreate
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BindingError(c, err, "invalid booking request")
+		}
+	}
+
+	status, resp := errorResponse(t, handle, `{"resource_id": 1, "quantity": -2}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "validation_failed", resp["code"])
+	fields := map[string]string{}
+	for _, f := range resp["fields"].([]interface{}) {
+		fe := f.(map[string]interface{})
+		fields[fe["field"].(string)] = fe["rule"].(string)
+	}
+	assert.Equal(t, "required", fields["start_time"])
+	assert.Equal(t, "required", fields["purpose"])
+	assert.Equal(t, "min", fields["quantity"])
+}
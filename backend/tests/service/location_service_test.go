@@ -0,0 +1,304 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/location"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// --- MOCK REPOSITORY ---
+type MockLocationRepo struct {
+	mock.Mock
+}
+
+func (m *MockLocationRepo) CreateLocation(l *location.Location) error {
+	return m.Called(l).Error(0)
+}
+func (m *MockLocationRepo) GetLocationByID(id int) (*location.Location, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*location.Location), args.Error(1)
+}
+func (m *MockLocationRepo) GetLocationByPath(path string) (*location.Location, error) {
+	args := m.Called(path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*location.Location), args.Error(1)
+}
+func (m *MockLocationRepo) GetAllLocations(pagination utils.PaginationQuery) ([]location.Location, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]location.Location), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockLocationRepo) GetLocations() ([]location.Location, error) {
+	args := m.Called()
+	return args.Get(0).([]location.Location), args.Error(1)
+}
+func (m *MockLocationRepo) GetLocationChain(id int) ([]location.Location, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]location.Location), args.Error(1)
+}
+func (m *MockLocationRepo) GetChildren(id int) ([]location.Location, error) {
+	args := m.Called(id)
+	return args.Get(0).([]location.Location), args.Error(1)
+}
+func (m *MockLocationRepo) UpdateLocation(l *location.Location, oldPath string) error {
+	return m.Called(l, oldPath).Error(0)
+}
+func (m *MockLocationRepo) DeleteLocation(id int) error {
+	return m.Called(id).Error(0)
+}
+func (m *MockLocationRepo) CountResourcesAt(id int) (int64, error) {
+	args := m.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func intPtr(i int) *int { return &i }
+
+// dubai works Sunday to Thursday, closing early on Thursdays.
+func dubai() *location.Location {
+	day := utils.DayHours{Open: "08:00", Close: "16:00"}
+	return &location.Location{ID: 4, Kind: location.KindBuilding, Name: "Dubai", Path: "Dubai", TimeZone: "Asia/Dubai", OpeningHours: utils.WeeklyHours{
+		"sunday": day, "monday": day, "tuesday": day, "wednesday": day,
+		"thursday": {Open: "08:00", Close: "12:00"},
+	}}
+}
+
+// nextDubaiDayAt returns the next given weekday, at least a day ahead, at hour:min Dubai time.
+func nextDubaiDayAt(weekday time.Weekday, hour, min int) time.Time {
+	loc, _ := time.LoadLocation("Asia/Dubai")
+	now := time.Now().In(loc)
+	t := time.Date(now.Year(), now.Month(), now.Day()+1, hour, min, 0, 0, loc)
+	for t.Weekday() != weekday {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func TestCreateLocation_Validation(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	err := svc.CreateLocation(&location.Location{Kind: location.KindCampus, Name: "Mars", TimeZone: "Mars/Olympus_Mons"})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+
+	err = svc.CreateLocation(&location.Location{Kind: location.KindCampus, Name: "London", OpeningHours: utils.WeeklyHours{
+		"monday": {Open: "17:00", Close: "09:00"},
+	}})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+
+	err = svc.CreateLocation(&location.Location{Kind: location.KindCampus, Name: "London", OpeningHours: utils.WeeklyHours{
+		"funday": {Open: "09:00", Close: "17:00"},
+	}})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+
+	err = svc.CreateLocation(&location.Location{Kind: location.KindBuilding, Name: "Building A / B"})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+
+	// Kinds nest campus > building > floor > zone
+	mockRepo.On("GetLocationChain", 2).Return([]location.Location{{ID: 2, Kind: location.KindFloor, Path: "HQ / Building A / Floor 1"}}, nil)
+	err = svc.CreateLocation(&location.Location{Kind: location.KindBuilding, Name: "Annex", ParentID: intPtr(2)})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+
+	mockRepo.AssertNotCalled(t, "CreateLocation", mock.Anything)
+}
+
+func TestCreateLocation_PathUnderParent(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	mockRepo.On("GetLocationChain", 2).Return([]location.Location{
+		{ID: 2, Kind: location.KindBuilding, Path: "HQ / Building A"},
+		{ID: 1, Kind: location.KindCampus, Path: "HQ"},
+	}, nil)
+	mockRepo.On("CreateLocation", mock.AnythingOfType("*location.Location")).Return(nil)
+
+	l := &location.Location{Kind: location.KindFloor, Name: "Floor 1", ParentID: intPtr(2)}
+	err := svc.CreateLocation(l)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "HQ / Building A / Floor 1", l.Path)
+	// Time zone and opening hours are left to be inherited
+	assert.Empty(t, l.TimeZone)
+	assert.Nil(t, l.OpeningHours)
+}
+
+func TestUpdateLocation_CannotMoveInsideItself(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	mockRepo.On("GetLocationByID", 2).Return(&location.Location{ID: 2, Kind: location.KindBuilding, Name: "Building A", Path: "HQ / Building A"}, nil)
+	// Zone 5 lies inside building 2
+	mockRepo.On("GetLocationChain", 5).Return([]location.Location{
+		{ID: 5, Kind: location.KindZone, Path: "HQ / Building A / Floor 1 / East"},
+		{ID: 3, Kind: location.KindFloor, Path: "HQ / Building A / Floor 1"},
+		{ID: 2, Kind: location.KindBuilding, Path: "HQ / Building A"},
+	}, nil)
+
+	err := svc.UpdateLocation(&location.Location{ID: 2, Kind: location.KindCampus, Name: "Building A", ParentID: intPtr(5)})
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "UpdateLocation", mock.Anything, mock.Anything)
+}
+
+func TestUpdateLocation_RenameCarriesPathDown(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	mockRepo.On("GetLocationByID", 2).Return(&location.Location{ID: 2, ParentID: intPtr(1), Kind: location.KindBuilding, Name: "Building A", Path: "HQ / Building A"}, nil)
+	mockRepo.On("GetLocationChain", 1).Return([]location.Location{{ID: 1, Kind: location.KindCampus, Path: "HQ"}}, nil)
+	mockRepo.On("GetChildren", 2).Return([]location.Location{{ID: 3, Kind: location.KindFloor, Name: "Floor 1"}}, nil)
+	mockRepo.On("UpdateLocation", mock.AnythingOfType("*location.Location"), "HQ / Building A").Return(nil)
+
+	l := &location.Location{ID: 2, ParentID: intPtr(1), Kind: location.KindBuilding, Name: "Aurora"}
+	err := svc.UpdateLocation(l)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "HQ / Aurora", l.Path)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteLocation_StillInUse(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	mockRepo.On("GetLocationByID", 4).Return(dubai(), nil)
+	mockRepo.On("GetChildren", 4).Return([]location.Location{}, nil)
+	mockRepo.On("CountResourcesAt", 4).Return(int64(3), nil)
+	mockRepo.On("GetLocationByID", 1).Return(&location.Location{ID: 1, Kind: location.KindCampus, Path: "HQ"}, nil)
+	mockRepo.On("GetChildren", 1).Return([]location.Location{{ID: 2, Kind: location.KindBuilding}}, nil)
+
+	assert.ErrorIs(t, svc.DeleteLocation(4), utils.ErrConflict)
+	assert.ErrorIs(t, svc.DeleteLocation(1), utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "DeleteLocation", mock.Anything)
+}
+
+func TestScheduleAt_InheritedFromAncestors(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	halfDays := utils.WeeklyHours{"monday": {Open: "09:00", Close: "13:00"}}
+	mockRepo.On("GetLocationChain", 3).Return([]location.Location{
+		{ID: 3, Kind: location.KindFloor, Path: "London / Building A / Floor 1"},
+		{ID: 2, Kind: location.KindBuilding, Path: "London / Building A", OpeningHours: halfDays},
+		{ID: 1, Kind: location.KindCampus, Path: "London", TimeZone: "Europe/London", OpeningHours: utils.DefaultWeeklyHours()},
+	}, nil)
+	mockRepo.On("GetLocationChain", 9).Return(nil, utils.ErrNotFound)
+
+	schedule, err := svc.ScheduleAt(intPtr(3))
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/London", schedule.Zone.String())
+	assert.Equal(t, halfDays, schedule.Hours)
+
+	// No location, or one that is gone: the default schedule
+	for _, id := range []*int{nil, intPtr(9)} {
+		schedule, err = svc.ScheduleAt(id)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.DefaultTimeZone, schedule.Zone.String())
+		assert.Equal(t, utils.DefaultWeeklyHours(), schedule.Hours)
+	}
+}
+
+func TestGetLocationTree(t *testing.T) {
+	mockRepo := new(MockLocationRepo)
+	svc := location.NewLocationService(mockRepo)
+
+	mockRepo.On("GetLocations").Return([]location.Location{
+		{ID: 1, Kind: location.KindCampus, Name: "HQ", Path: "HQ"},
+		{ID: 2, ParentID: intPtr(1), Kind: location.KindBuilding, Name: "Building A", Path: "HQ / Building A"},
+		{ID: 3, ParentID: intPtr(2), Kind: location.KindFloor, Name: "Floor 1", Path: "HQ / Building A / Floor 1"},
+		{ID: 4, Kind: location.KindBuilding, Name: "Dubai", Path: "Dubai"},
+	}, nil)
+
+	tree, err := svc.GetLocationTree()
+
+	assert.NoError(t, err)
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Floor 1", tree[0].Children[0].Children[0].Name)
+	assert.Empty(t, tree[1].Children)
+}
+
+func TestCreateResource_PlacedByLocationPath(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	locationRepo := new(MockLocationRepo)
+	svc := resource.NewResourceService(mockRepo)
+	svc.Locations = location.NewLocationService(locationRepo)
+
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, Type: "Room"}, nil)
+	locationRepo.On("GetLocationByPath", "HQ / Building A / Floor 1").Return(&location.Location{ID: 3, Path: "HQ / Building A / Floor 1"}, nil)
+	locationRepo.On("GetLocationByPath", "Bldg A Floor 1").Return(nil, utils.ErrNotFound)
+	mockRepo.On("CreateResource", mock.AnythingOfType("*resource.Resource")).Return(nil)
+
+	res := &resource.Resource{Name: "Orion", TypeID: 1, Location: "HQ / Building A / Floor 1"}
+	assert.NoError(t, svc.CreateResource(res))
+	assert.Equal(t, 3, *res.LocationID)
+
+	err := svc.CreateResource(&resource.Resource{Name: "Vega", TypeID: 1, Location: "Bldg A Floor 1"})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNumberOfCalls(t, "CreateResource", 1)
+}
+
+func TestCreateBooking_LocalOpeningHours(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	locationRepo := new(MockLocationRepo)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Locations = location.NewLocationService(locationRepo)
+
+	mockRepo.On("GetResourceByID", 21).Return(&resource.Resource{ID: 21, LocationID: intPtr(4), Location: "Dubai", IsActive: true, RequiresApproval: true}, nil)
+	locationRepo.On("GetLocationChain", 4).Return([]location.Location{*dubai()}, nil)
+
+	// Sunday is a working day in Dubai; the window can come in any zone
+	start := nextDubaiDayAt(time.Sunday, 9, 0).UTC()
+	end := start.Add(time.Hour)
+	mockRepo.On("HasApprovedOverlap", 21, start, end, 1).Return(false, nil)
+	mockRepo.On("CreateBooking", mock.AnythingOfType("*booking.Booking")).Return(nil, 77)
+	mockRepo.On("GetBookingByID", 77).Return(&booking.Booking{
+		ID: 77, ResourceID: 21, Status: booking.StatusPending, StartTime: start, EndTime: end,
+		Resource: resource.Resource{Name: "Majlis"}, User: user.User{Name: "Test User", Email: "test@example.com"},
+	}, nil)
+
+	summary, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 21, StartTime: start, EndTime: end, Purpose: "Sync"}, "user-uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, 77, summary.ID)
+
+	// ... while Friday is the weekend
+	friday := nextDubaiDayAt(time.Friday, 9, 0)
+	_, err = svc.CreateBooking(&booking.BookingCreate{ResourceID: 21, StartTime: friday, EndTime: friday.Add(time.Hour), Purpose: "Sync"}, "user-uuid")
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "weekend")
+
+	// ... and Thursday is a half-day
+	thursday := nextDubaiDayAt(time.Thursday, 11, 0)
+	_, err = svc.CreateBooking(&booking.BookingCreate{ResourceID: 21, StartTime: thursday, EndTime: thursday.Add(2 * time.Hour), Purpose: "Sync"}, "user-uuid")
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "12:00")
+
+	mockRepo.AssertNumberOfCalls(t, "CreateBooking", 1)
+}
+
+func TestSendCheckInReminders_LastIntervalOfStarts(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	svc := booking.NewBookingService(mockRepo)
+
+	mockRepo.On("GetApprovedBookingsStartingBetween", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]booking.Booking{}, nil)
+
+	assert.NoError(t, svc.SendCheckInReminders())
+
+	from := mockRepo.Calls[0].Arguments.Get(0).(time.Time)
+	to := mockRepo.Calls[0].Arguments.Get(1).(time.Time)
+	assert.Equal(t, booking.ReminderInterval, to.Sub(from))
+	assert.True(t, to.Before(time.Now().Add(-booking.ReminderDelay+time.Second)))
+	assert.Equal(t, time.Duration(0), to.Sub(to.Truncate(booking.ReminderInterval)))
+}
@@ -0,0 +1,286 @@
+package service_test
+
+import (
+	"ResourceAllocator/internal/api/booking"
+	"ResourceAllocator/internal/api/quota"
+	"ResourceAllocator/internal/api/resource"
+	"ResourceAllocator/internal/api/user"
+	"ResourceAllocator/internal/api/utils"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// --- MOCK REPOSITORY ---
+type MockQuotaRepo struct {
+	mock.Mock
+}
+
+func (m *MockQuotaRepo) CreateRule(rule *quota.QuotaRule) error {
+	return m.Called(rule).Error(0)
+}
+func (m *MockQuotaRepo) GetRuleByID(id int) (*quota.QuotaRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*quota.QuotaRule), args.Error(1)
+}
+func (m *MockQuotaRepo) GetAllRules(pagination utils.PaginationQuery) ([]quota.QuotaRule, int64, error) {
+	args := m.Called(pagination)
+	return args.Get(0).([]quota.QuotaRule), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockQuotaRepo) GetRules() ([]quota.QuotaRule, error) {
+	args := m.Called()
+	return args.Get(0).([]quota.QuotaRule), args.Error(1)
+}
+func (m *MockQuotaRepo) UpdateRule(rule *quota.QuotaRule) error {
+	return m.Called(rule).Error(0)
+}
+func (m *MockQuotaRepo) DeleteRule(id int) error {
+	return m.Called(id).Error(0)
+}
+func (m *MockQuotaRepo) GetUserByUUID(uuid string) (*user.User, error) {
+	args := m.Called(uuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+func (m *MockQuotaRepo) CountActiveBookings(filter quota.BookingFilter, now time.Time) (int64, error) {
+	args := m.Called(filter, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockQuotaRepo) SumBookedHours(filter quota.BookingFilter, from, to time.Time) (float64, error) {
+	args := m.Called(filter, from, to)
+	return args.Get(0).(float64), args.Error(1)
+}
+func (m *MockQuotaRepo) CountBookingsStarting(filter quota.BookingFilter, from, to time.Time) (int64, error) {
+	args := m.Called(filter, from, to)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockQuotaChecker struct {
+	mock.Mock
+}
+
+func (m *MockQuotaChecker) CheckBooking(userID string, resourceTypeID int, start, end time.Time, excludeBookingID int) error {
+	return m.Called(userID, resourceTypeID, start, end, excludeBookingID).Error(0)
+}
+func (m *MockQuotaChecker) CheckBookings(userID string, resourceTypeID int, start, end time.Time, planned []quota.Planned, excludeIDs []int) error {
+	return m.Called(userID, resourceTypeID, start, end, planned, excludeIDs).Error(0)
+}
+
+// --- TEST SUITE ---
+
+func TestCheckBooking_WeeklyHoursExceeded(t *testing.T) {
+	mockRepo := new(MockQuotaRepo)
+	svc := quota.NewQuotaService(mockRepo)
+
+	labType := 3
+	mockRepo.On("GetUserByUUID", "u1").Return(&user.User{UUID: "u1", Role: user.RoleEmployee, Group: "Research"}, nil)
+	mockRepo.On("GetRules").Return([]quota.QuotaRule{
+		{ID: 1, Name: "Lab fair share", Group: "research", ResourceTypeID: &labType, MaxHoursPerWeek: 10},
+	}, nil)
+	// Wednesday: the week runs from Monday 00:00 to the next Monday
+	start := time.Date(2030, 1, 9, 10, 0, 0, 0, time.Local)
+	weekStart := time.Date(2030, 1, 7, 0, 0, 0, 0, time.Local)
+	mockRepo.On("SumBookedHours", quota.BookingFilter{UserID: "u1", ResourceTypeID: &labType}, weekStart, weekStart.AddDate(0, 0, 7)).Return(8.0, nil)
+
+	err := svc.CheckBooking("u1", labType, start, start.Add(3*time.Hour), 0)
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Contains(t, err.Error(), "8 of 10 hours")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckBooking_RuleForOtherTypeOrRoleIgnored(t *testing.T) {
+	mockRepo := new(MockQuotaRepo)
+	svc := quota.NewQuotaService(mockRepo)
+
+	labType := 3
+	mockRepo.On("GetUserByUUID", "u1").Return(&user.User{UUID: "u1", Role: user.RoleEmployee}, nil)
+	mockRepo.On("GetRules").Return([]quota.QuotaRule{
+		{ID: 1, Name: "Lab", ResourceTypeID: &labType, MaxBookingsPerDay: 1},
+		{ID: 2, Name: "Admins", Role: user.RoleAdmin, MaxActiveBookings: 1},
+	}, nil)
+
+	start := time.Date(2030, 1, 9, 10, 0, 0, 0, time.Local)
+	err := svc.CheckBooking("u1", 7, start, start.Add(time.Hour), 0)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CountBookingsStarting", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "CountActiveBookings", mock.Anything, mock.Anything)
+}
+
+func TestCheckBooking_ApprovalExcludesTheBookingItself(t *testing.T) {
+	mockRepo := new(MockQuotaRepo)
+	svc := quota.NewQuotaService(mockRepo)
+
+	mockRepo.On("GetUserByUUID", "u1").Return(&user.User{UUID: "u1", Role: user.RoleEmployee}, nil)
+	mockRepo.On("GetRules").Return([]quota.QuotaRule{{ID: 1, Name: "Two at a time", MaxActiveBookings: 2}}, nil)
+	// One other booking held; the pending one being approved is left out and counted as the new one
+	mockRepo.On("CountActiveBookings", quota.BookingFilter{UserID: "u1", ExcludeIDs: []int{42}}, mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+
+	start := time.Date(2030, 1, 9, 10, 0, 0, 0, time.Local)
+	err := svc.CheckBooking("u1", 7, start, start.Add(time.Hour), 42)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateRule_NeedsALimit(t *testing.T) {
+	mockRepo := new(MockQuotaRepo)
+	svc := quota.NewQuotaService(mockRepo)
+
+	err := svc.CreateRule(&quota.QuotaRule{Name: "Empty"})
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CreateRule", mock.Anything)
+}
+
+func TestCreateBooking_QuotaExceeded(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	startTime := nextWeekdayAt(10)
+	endTime := startTime.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 8).Return(&resource.Resource{ID: 8, TypeID: 3, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlap", 8, startTime, endTime, 1).Return(false, nil)
+	quotas.On("CheckBooking", "user-uuid", 3, startTime, endTime, 0).Return(utils.ErrConflict)
+
+	_, err := svc.CreateBooking(&booking.BookingCreate{ResourceID: 8, StartTime: startTime, EndTime: endTime, Purpose: "Sync"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "CreateBooking", mock.Anything)
+}
+
+func TestUpdateStatus_ApprovalChecksQuota(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 42).Return(&booking.Booking{
+		ID: 42, ResourceID: 8, UserID: "owner", StartTime: start, EndTime: start.Add(time.Hour), Status: booking.StatusPending,
+		Resource: resource.Resource{TypeID: 3},
+	}, nil)
+	quotas.On("CheckBooking", "owner", 3, start, start.Add(time.Hour), 42).Return(utils.ErrConflict)
+
+	err := svc.UpdateStatus(42, &booking.BookingStatusUpdate{Status: booking.StatusApproved}, "admin")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "ApproveBookingAndRejectConflicts", mock.Anything)
+}
+
+func TestCheckBookings_CountsPlannedBookings(t *testing.T) {
+	mockRepo := new(MockQuotaRepo)
+	svc := quota.NewQuotaService(mockRepo)
+
+	mockRepo.On("GetUserByUUID", "u1").Return(&user.User{UUID: "u1", Role: user.RoleEmployee}, nil)
+	mockRepo.On("GetRules").Return([]quota.QuotaRule{{ID: 1, Name: "Two a day", MaxBookingsPerDay: 2}}, nil)
+	start := time.Date(2030, 1, 9, 10, 0, 0, 0, time.Local)
+	day := time.Date(2030, 1, 9, 0, 0, 0, 0, time.Local)
+	// Booking 42 is being replaced, so it is left out of what is stored
+	mockRepo.On("CountBookingsStarting", quota.BookingFilter{UserID: "u1", ExcludeIDs: []int{42}}, day, day.AddDate(0, 0, 1)).Return(int64(0), nil)
+
+	// One planned booking that day leaves room for another
+	planned := []quota.Planned{{ResourceTypeID: 7, StartTime: start.Add(-2 * time.Hour), EndTime: start.Add(-time.Hour)}}
+	err := svc.CheckBookings("u1", 7, start, start.Add(time.Hour), planned, []int{42})
+	assert.NoError(t, err)
+
+	// Two don't; a planned booking on another day doesn't count
+	planned = append(planned, quota.Planned{ResourceTypeID: 7, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour)})
+	err = svc.CheckBookings("u1", 7, start, start.Add(time.Hour), planned, []int{42})
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Contains(t, err.Error(), "2 of 2 bookings")
+
+	err = svc.CheckBookings("u1", 7, start, start.Add(time.Hour), planned[1:], []int{42})
+	assert.NoError(t, err)
+}
+
+func TestCreateBookingSeries_SkipsOccurrencesOverQuota(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotaRepo := new(MockQuotaRepo)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quota.NewQuotaService(quotaRepo)
+
+	first := nextWeekdayAt(10)
+	second := first.AddDate(0, 0, 7)
+	for utils.DefaultSchedule().IsHoliday(second) != nil {
+		first, second = second, second.AddDate(0, 0, 7)
+	}
+	req := &booking.BookingCreate{
+		ResourceID: 7, StartTime: first, EndTime: first.Add(time.Hour), Purpose: "Weekly sync",
+		Recurrence: "FREQ=WEEKLY;COUNT=2",
+	}
+	mockRepo.On("GetResourceByID", 7).Return(&resource.Resource{ID: 7, TypeID: 3, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 7, mock.Anything, mock.Anything, 1, 0).Return(false, nil)
+	// One booking already held: the first occurrence takes the second slot, the next one doesn't fit
+	quotaRepo.On("GetUserByUUID", "user-uuid").Return(&user.User{UUID: "user-uuid", Role: user.RoleEmployee}, nil)
+	quotaRepo.On("GetRules").Return([]quota.QuotaRule{{ID: 1, Name: "Two at a time", MaxActiveBookings: 2}}, nil)
+	quotaRepo.On("CountActiveBookings", quota.BookingFilter{UserID: "user-uuid"}, mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+	mockRepo.On("CreateBookingSeries", mock.AnythingOfType("*booking.BookingSeries"), mock.MatchedBy(func(bs []booking.Booking) bool {
+		return len(bs) == 1 && bs[0].StartTime.Equal(first)
+	})).Return([]booking.Booking{}, nil, 55)
+	mockRepo.On("GetBookingsBySeriesID", 55).Return([]booking.Booking{{ID: 1, StartTime: first, EndTime: first.Add(time.Hour)}}, nil)
+
+	result, err := svc.CreateBookingSeries(req, "user-uuid")
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Created, 1)
+	if assert.Len(t, result.Skipped, 1) {
+		assert.True(t, result.Skipped[0].StartTime.Equal(second))
+		assert.Contains(t, result.Skipped[0].Reason, "quota 'Two at a time' exceeded")
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBundle_OverQuotaRejected(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(11)
+	end := start.Add(time.Hour)
+	mockRepo.On("GetResourceByID", 1).Return(&resource.Resource{ID: 1, TypeID: 3, IsActive: true}, nil)
+	mockRepo.On("GetResourceByID", 2).Return(&resource.Resource{ID: 2, TypeID: 4, IsActive: true}, nil)
+	mockRepo.On("HasApprovedOverlap", mock.Anything, start, end, 1).Return(false, nil)
+	// The second member is checked with the first one counted
+	quotas.On("CheckBookings", "user-uuid", 3, start, end, []quota.Planned(nil), []int(nil)).Return(nil)
+	quotas.On("CheckBookings", "user-uuid", 4, start, end, []quota.Planned{{ResourceTypeID: 3, StartTime: start, EndTime: end}}, []int(nil)).
+		Return(fmt.Errorf("%w: quota 'Two at a time' exceeded", utils.ErrConflict))
+
+	_, err := svc.CreateBundle(&booking.BundleCreate{ResourceIDs: []int{1, 2}, StartTime: start, EndTime: end, Purpose: "Demo"}, "user-uuid")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	assert.Contains(t, err.Error(), "resource 2")
+	quotas.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateBundle", mock.Anything, mock.Anything)
+}
+
+func TestRescheduleBooking_ChecksQuotaForNewWindow(t *testing.T) {
+	mockRepo := new(MockBookingRepo)
+	quotas := new(MockQuotaChecker)
+	svc := booking.NewBookingService(mockRepo)
+	svc.Quotas = quotas
+
+	start := nextWeekdayAt(10)
+	mockRepo.On("GetBookingByID", 50).Return(approvedBooking(start, 1), nil)
+	newEnd := start.Add(4 * time.Hour)
+	mockRepo.On("GetResourceByID", 4).Return(&resource.Resource{ID: 4, TypeID: 3, IsActive: true, RequiresApproval: true}, nil)
+	mockRepo.On("HasApprovedOverlapExcluding", 4, start, newEnd, 1, 50).Return(false, nil)
+	quotas.On("CheckBooking", "owner", 3, start, newEnd, 50).Return(utils.ErrConflict)
+
+	_, err := svc.RescheduleBooking(50, &booking.BookingReschedule{EndTime: &newEnd}, "owner")
+
+	assert.ErrorIs(t, err, utils.ErrConflict)
+	mockRepo.AssertNotCalled(t, "RescheduleBooking", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -3,8 +3,13 @@ package service_test
 import (
 	"ResourceAllocator/internal/api/resource"
 	"ResourceAllocator/internal/api/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -45,8 +50,8 @@ func (m *MockResourceRepo) GetResourceByID(id int) (*resource.Resource, error) {
 	}
 	return nil, args.Error(1)
 }
-func (m *MockResourceRepo) GetAllResources(typeID *int, location string, props map[string]string, startTime, endTime *string, pagination utils.PaginationQuery) ([]resource.ResourceSummary, int64, error) {
-	args := m.Called(typeID, location, props, startTime, endTime, pagination)
+func (m *MockResourceRepo) GetAllResources(typeID *int, locationID *int, location string, props resource.PropertyQuery, startTime, endTime *string, role string, pagination utils.PaginationQuery) ([]resource.ResourceSummary, int64, error) {
+	args := m.Called(typeID, locationID, location, props, startTime, endTime, role, pagination)
 	return args.Get(0).([]resource.ResourceSummary), args.Get(1).(int64), args.Error(2)
 }
 func (m *MockResourceRepo) GetAllResourceTypes(pagination utils.PaginationQuery) ([]resource.ResourceType, int64, error) {
@@ -76,7 +81,7 @@ func TestCreateResource_ValidationSuccess(t *testing.T) {
 	resType := &resource.ResourceType{
 		ID:               1,
 		Type:             "Room",
-		SchemaDefinition: map[string]string{"capacity": "int"},
+		SchemaDefinition: map[string]resource.PropertySchema{"capacity": {Type: resource.PropertyInteger, Required: true}},
 	}
 	mockRepo.On("GetResourceTypeByID", 1).Return(resType, nil)
 
@@ -99,7 +104,7 @@ func TestCreateResource_ValidationFail_MissingProp(t *testing.T) {
 
 	resType := &resource.ResourceType{
 		ID:               1,
-		SchemaDefinition: map[string]string{"capacity": "int"}, // Required
+		SchemaDefinition: map[string]resource.PropertySchema{"capacity": {Type: resource.PropertyInteger, Required: true}}, // Required
 	}
 	mockRepo.On("GetResourceTypeByID", 1).Return(resType, nil)
 
@@ -109,8 +114,10 @@ func TestCreateResource_ValidationFail_MissingProp(t *testing.T) {
 	}
 
 	err := svc.CreateResource(invalidRes)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "missing required property")
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	var invalid *utils.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []utils.FieldError{{Field: "properties.capacity", Rule: "required", Message: "is required"}}, invalid.Fields)
 
 	mockRepo.AssertNotCalled(t, "CreateResource")
 }
@@ -128,3 +135,290 @@ func TestDeleteResourceType_Conflict(t *testing.T) {
 
 	mockRepo.AssertNotCalled(t, "DeleteResourceType")
 }
+
+func TestSlotRules_ResourceOverridesType(t *testing.T) {
+	slot, maxDur := 15, 30
+	res := &resource.Resource{
+		SlotMinutes:        &slot,
+		MaxDurationMinutes: &maxDur,
+		Type:               &resource.ResourceType{SlotMinutes: 60, MinDurationMinutes: 15, MaxDurationMinutes: 240},
+	}
+
+	rules := res.SlotRules()
+
+	assert.Equal(t, 15*time.Minute, rules.Granularity)
+	assert.Equal(t, 15*time.Minute, rules.MinDuration) // Inherited from the type
+	assert.Equal(t, 30*time.Minute, rules.MaxDuration)
+}
+
+func TestCreateResource_MaxDurationBelowTypeMinimum(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, SlotMinutes: 60, MinDurationMinutes: 180}, nil)
+	maxDur := 120
+	res := &resource.Resource{TypeID: 1, MaxDurationMinutes: &maxDur}
+
+	err := svc.CreateResource(res)
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CreateResource")
+}
+
+func TestCreateResourceType_UnknownRoleWindow(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	rt := &resource.ResourceType{Type: "Lab", SlotMinutes: 60,
+		RoleBookingWindows: map[string]resource.BookingWindow{"MANAGER": {MaxHorizonDays: 30}}}
+
+	err := svc.CreateResourceType(rt)
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "CreateResourceType", mock.Anything)
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+// Room schema exercising every property type
+var roomSchema = map[string]resource.PropertySchema{
+	"capacity":  {Type: resource.PropertyInteger, Required: true, Min: floatPtr(1), Max: floatPtr(500)},
+	"area":      {Type: resource.PropertyNumber},
+	"projector": {Type: resource.PropertyBoolean, Default: false},
+	"layout":    {Type: resource.PropertyEnum, Values: []string{"boardroom", "classroom"}, Default: "boardroom"},
+	"code":      {Type: resource.PropertyString, Pattern: `^R-\d+$`},
+	"serviced":  {Type: resource.PropertyDate},
+}
+
+func TestCreateResource_PropertiesStoredTyped(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, SchemaDefinition: roomSchema}, nil)
+	mockRepo.On("CreateResource", mock.Anything).Return(nil)
+
+	res := &resource.Resource{Name: "Room 1", TypeID: 1, Properties: map[string]interface{}{
+		"capacity": "12", "area": 30.5, "code": "R-101", "serviced": "2026-03-01",
+	}}
+	err := svc.CreateResource(res)
+
+	assert.NoError(t, err)
+	// Numbers from a form are stored as numbers, and left out optional properties get their defaults
+	assert.Equal(t, map[string]interface{}{
+		"capacity": int64(12), "area": 30.5, "code": "R-101", "serviced": "2026-03-01",
+		"projector": false, "layout": "boardroom",
+	}, res.Properties)
+	capacity, ok := (&resource.Resource{Type: &resource.ResourceType{SchemaDefinition: roomSchema}, Properties: res.Properties}).Capacity()
+	assert.True(t, ok)
+	assert.Equal(t, 12, capacity)
+}
+
+func TestUpdateResource_EveryFieldErrorReported(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, SchemaDefinition: roomSchema}, nil)
+
+	err := svc.UpdateResource(&resource.Resource{ID: 4, TypeID: 1, Properties: map[string]interface{}{
+		"capacity": 12.5, "area": "large", "projector": "maybe", "layout": "theatre",
+		"code": "101", "serviced": "01/03/2026", "colour": "red",
+	}})
+
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+	var invalid *utils.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []utils.FieldError{
+		{Field: "properties.area", Rule: "type", Message: "must be a number"},
+		{Field: "properties.capacity", Rule: "type", Message: "must be a whole number"},
+		{Field: "properties.code", Rule: "pattern", Message: `must match ^R-\d+$`},
+		{Field: "properties.layout", Rule: "enum", Message: "must be one of: boardroom, classroom"},
+		{Field: "properties.projector", Rule: "type", Message: "must be true or false"},
+		{Field: "properties.serviced", Rule: "type", Message: "must be a date (YYYY-MM-DD)"},
+		{Field: "properties.colour", Rule: "unknown", Message: "is not a property of this resource type"},
+	}, invalid.Fields)
+	mockRepo.AssertNotCalled(t, "UpdateResource", mock.Anything)
+
+	err = svc.UpdateResource(&resource.Resource{ID: 4, TypeID: 1, Properties: map[string]interface{}{"capacity": 900}})
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []utils.FieldError{{Field: "properties.capacity", Rule: "max", Message: "must be at most 500"}}, invalid.Fields)
+}
+
+func TestCreateResourceType_InvalidSchema(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	err := svc.CreateResourceType(&resource.ResourceType{Type: "Room", SlotMinutes: 60, SchemaDefinition: map[string]resource.PropertySchema{
+		"layout":   {Type: resource.PropertyEnum},
+		"code":     {Type: resource.PropertyString, Pattern: "(unclosed"},
+		"capacity": {Type: resource.PropertyInteger, Min: floatPtr(10), Max: floatPtr(2)},
+		"floor":    {Type: resource.PropertyInteger, Default: "ground"},
+		"colour":   {Type: "colour"},
+	}})
+
+	var invalid *utils.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	rules := map[string]string{}
+	for _, f := range invalid.Fields {
+		rules[f.Field] = f.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"schema_definition.capacity": "max",
+		"schema_definition.code":     "pattern",
+		"schema_definition.colour":   "type",
+		"schema_definition.floor":    "type",
+		"schema_definition.layout":   "values",
+	}, rules)
+	mockRepo.AssertNotCalled(t, "CreateResourceType", mock.Anything)
+}
+
+func TestPropertySchema_ReadsLegacyTypeNames(t *testing.T) {
+	var schema map[string]resource.PropertySchema
+	err := json.Unmarshal([]byte(`{"capacity": "int", "floor": "Number", "wing": {"type": "text", "required": false}}`), &schema)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]resource.PropertySchema{
+		"capacity": {Type: resource.PropertyInteger, Required: true},
+		"floor":    {Type: resource.PropertyNumber, Required: true},
+		"wing":     {Type: resource.PropertyString},
+	}, schema)
+}
+
+func TestParseResourceFilter_PropertyOperators(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/resources?type_id=1&prop_capacity[gte]=10&prop_layout[in]=boardroom,classroom&prop_projector=true&sort=-prop_capacity", nil)
+
+	f, err := resource.ParseResourceFilter(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{
+			{Key: "capacity", Op: resource.OpGte, Value: "10"},
+			{Key: "layout", Op: resource.OpIn, Value: "boardroom,classroom"},
+			{Key: "projector", Op: resource.OpEq, Value: "true"},
+		},
+		SortBy:   "capacity",
+		SortDesc: true,
+	}, f.Props)
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/resources?type_id=1&sort=name", nil)
+	_, err = resource.ParseResourceFilter(c)
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+}
+
+func TestGetAllResources_PropertyFiltersTypedBySchema(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	typeID := 1
+	pagination := utils.PaginationQuery{Page: 1, Limit: 10}
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, SchemaDefinition: roomSchema}, nil)
+	// Numbers and booleans reach the repository typed, so they compare as such
+	mockRepo.On("GetAllResources", &typeID, (*int)(nil), "", resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{
+			{Key: "capacity", Op: resource.OpGte, Value: "10", Type: resource.PropertyInteger, Values: []interface{}{int64(10)}},
+			{Key: "area", Op: resource.OpIn, Value: "20, 32.5", Type: resource.PropertyNumber, Values: []interface{}{20.0, 32.5}},
+			{Key: "projector", Op: resource.OpEq, Value: "true", Type: resource.PropertyBoolean, Values: []interface{}{true}},
+			{Key: "code", Op: resource.OpContains, Value: "R-1", Type: resource.PropertyString, Values: []interface{}{"R-1"}},
+			{Key: "serviced", Op: resource.OpExists, Value: "false", Type: resource.PropertyDate, Values: []interface{}{false}},
+		},
+		SortBy: "capacity", SortType: resource.PropertyInteger,
+	}, (*string)(nil), (*string)(nil), "EMPLOYEE", pagination).Return([]resource.ResourceSummary{{ID: 3}}, int64(1), nil)
+
+	results, total, err := svc.GetAllResources(&typeID, nil, "", resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{
+			resource.ParsePropertyFilter("capacity[gte]", "10"),
+			resource.ParsePropertyFilter("area[in]", "20, 32.5"),
+			resource.ParsePropertyFilter("projector", "true"),
+			resource.ParsePropertyFilter("code[contains]", "R-1"),
+			resource.ParsePropertyFilter("serviced[exists]", "false"),
+		},
+		SortBy: "capacity",
+	}, nil, nil, "EMPLOYEE", pagination)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, results, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllResources_InvalidPropertyFilters(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	typeID := 1
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, SchemaDefinition: roomSchema}, nil)
+
+	_, _, err := svc.GetAllResources(&typeID, nil, "", resource.PropertyQuery{
+		Filters: resource.PropertyFilters(map[string]string{
+			"capacity[contains]": "1",
+			"capacity[in]":       "2,many",
+			"code[gte]":          "R-1",
+			"projector[near]":    "true",
+			"colour":             "red",
+		}),
+		SortBy: "height",
+	}, nil, nil, "EMPLOYEE", utils.PaginationQuery{Page: 1, Limit: 10})
+
+	var invalid *utils.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []utils.FieldError{
+		{Field: "prop_capacity", Rule: "operator", Message: "contains only applies to strings and enums"},
+		{Field: "prop_capacity", Rule: "type", Message: "each value must be a whole number"},
+		{Field: "prop_code", Rule: "operator", Message: "gte only compares integers, numbers and dates"},
+		{Field: "prop_colour", Rule: "unknown", Message: "is not a property of this resource type"},
+		{Field: "prop_projector", Rule: "operator", Message: "'near' is not an operator (use eq, gt, gte, lt, lte, in, contains or exists)"},
+		{Field: "sort", Rule: "unknown", Message: "'height' is not a property of this resource type"},
+	}, invalid.Fields)
+	mockRepo.AssertNotCalled(t, "GetAllResources", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Properties only compare through the schema of a type
+	_, _, err = svc.GetAllResources(nil, nil, "", resource.PropertyQuery{SortBy: "capacity"}, nil, nil, "EMPLOYEE", utils.PaginationQuery{Page: 1, Limit: 10})
+	assert.ErrorIs(t, err, utils.ErrInvalidInput)
+}
+
+func TestGetAllResources_EnumAndBooleanFilters(t *testing.T) {
+	mockRepo := new(MockResourceRepo)
+	svc := resource.NewResourceService(mockRepo)
+
+	typeID := 1
+	pagination := utils.PaginationQuery{Page: 1, Limit: 10}
+	mockRepo.On("GetResourceTypeByID", 1).Return(&resource.ResourceType{ID: 1, SchemaDefinition: roomSchema}, nil)
+
+	// An enum only matches its own values
+	_, _, err := svc.GetAllResources(&typeID, nil, "", resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{resource.ParsePropertyFilter("layout", "theatre")},
+	}, nil, nil, "EMPLOYEE", pagination)
+	var invalid *utils.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []utils.FieldError{
+		{Field: "prop_layout", Rule: "enum", Message: "must be one of: boardroom, classroom"},
+	}, invalid.Fields)
+
+	_, _, err = svc.GetAllResources(&typeID, nil, "", resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{
+			resource.ParsePropertyFilter("layout[in]", "classroom,theatre"),
+			resource.ParsePropertyFilter("projector[in]", "true,false"),
+		},
+	}, nil, nil, "EMPLOYEE", pagination)
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []utils.FieldError{
+		{Field: "prop_layout", Rule: "enum", Message: "each value must be one of: boardroom, classroom"},
+		{Field: "prop_projector", Rule: "operator", Message: "in doesn't apply to booleans (use eq)"},
+	}, invalid.Fields)
+	mockRepo.AssertNotCalled(t, "GetAllResources", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Values of the enum pass
+	mockRepo.On("GetAllResources", &typeID, (*int)(nil), "", resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{
+			{Key: "layout", Op: resource.OpIn, Value: "boardroom, classroom", Type: resource.PropertyEnum, Values: []interface{}{"boardroom", "classroom"}},
+		},
+	}, (*string)(nil), (*string)(nil), "EMPLOYEE", pagination).Return([]resource.ResourceSummary{{ID: 3}}, int64(1), nil)
+
+	_, _, err = svc.GetAllResources(&typeID, nil, "", resource.PropertyQuery{
+		Filters: []resource.PropertyFilter{resource.ParsePropertyFilter("layout[in]", "boardroom, classroom")},
+	}, nil, nil, "EMPLOYEE", pagination)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -73,8 +73,15 @@ const TypeTable = ({ onCreateClick, onEdit, refreshTrigger }) => {
             'int': 'Integer',
             'integer': 'Integer',
             'boolean': 'True/False',
+            'enum': 'One of',
             'date': 'Date'
         };
+        // Typed schemas are { type, required, values, ... }; older ones just the type name
+        if (value && typeof value === 'object') {
+            const label = typeMap[value.type] || value.type;
+            const values = value.type === 'enum' && value.values ? ` (${value.values.join(', ')})` : '';
+            return `${label}${values}${value.required ? '' : ', optional'}`;
+        }
         return typeMap[value] || value;
     };
 
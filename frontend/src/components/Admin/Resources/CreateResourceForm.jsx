@@ -168,8 +168,11 @@ const CreateResourceForm = ({ onClose, onSuccess }) => {
                 }
             }
 
-            // All properties are required for all resource types
-            Object.entries(schema).forEach(([key, type]) => {
+            // Properties are required unless the schema makes them optional
+            Object.entries(schema).forEach(([key, definition]) => {
+                if (definition && definition.required === false) {
+                    return;
+                }
                 const value = formData.properties[key];
                 if (!value || (typeof value === 'string' && !value.trim())) {
                     newErrors[`property_${key}`] = `${formatPropertyLabel(key)} is required`;
@@ -207,7 +210,8 @@ const CreateResourceForm = ({ onClose, onSuccess }) => {
                     }
                 }
 
-                Object.entries(schema).forEach(([key, type]) => {
+                Object.entries(schema).forEach(([key, definition]) => {
+                    const type = propertyType(definition);
                     const value = formData.properties[key];
 
                     // Skip if value is null, undefined, or empty
@@ -216,7 +220,7 @@ const CreateResourceForm = ({ onClose, onSuccess }) => {
                     }
 
                     if (type === 'number' || type === 'integer' || type === 'int') {
-                        const numValue = parseInt(value);
+                        const numValue = type === 'number' ? parseFloat(value) : parseInt(value);
                         if (!isNaN(numValue)) {
                             processedProperties[key] = numValue;
                         }
@@ -294,8 +298,12 @@ const CreateResourceForm = ({ onClose, onSuccess }) => {
         }
     };
 
+    // Schema entries are { type, required, ... }; older types stored just the type name
+    const propertyType = (definition) =>
+        definition && typeof definition === 'object' ? definition.type : definition;
+
     const getInputType = (schemaType) => {
-        switch (schemaType) {
+        switch (propertyType(schemaType)) {
             case 'number':
             case 'integer':
                 return 'number';
@@ -471,7 +479,7 @@ const CreateResourceForm = ({ onClose, onSuccess }) => {
                                                     <label className="block text-sm font-medium text-gray-700 mb-2">
                                                         {formatPropertyLabel(key)}
                                                         <span className="text-red-500 ml-1">*</span>
-                                                        <span className="text-xs text-gray-500 ml-2">({schemaType})</span>
+                                                        <span className="text-xs text-gray-500 ml-2">({propertyType(schemaType)})</span>
                                                     </label>
                                                     <input
                                                         type={getInputType(schemaType)}
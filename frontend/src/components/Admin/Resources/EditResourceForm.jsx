@@ -206,7 +206,10 @@ const EditResourceForm = ({ resource, onClose, onSuccess }) => {
 
             // For meeting room, all properties are required
             if (isMeetingRoom) {
-                Object.entries(schema).forEach(([key, type]) => {
+                Object.entries(schema).forEach(([key, definition]) => {
+                    if (definition && definition.required === false) {
+                        return;
+                    }
                     const value = formData.properties[key];
                     if (!value || (typeof value === 'string' && !value.trim())) {
                         newErrors[`property_${key}`] = `${formatPropertyLabel(key)} is required`;
@@ -245,7 +248,8 @@ const EditResourceForm = ({ resource, onClose, onSuccess }) => {
                     }
                 }
 
-                Object.entries(schema).forEach(([key, type]) => {
+                Object.entries(schema).forEach(([key, definition]) => {
+                    const type = propertyType(definition);
                     const value = formData.properties[key];
 
                     // Skip if value is null, undefined, or empty
@@ -254,7 +258,7 @@ const EditResourceForm = ({ resource, onClose, onSuccess }) => {
                     }
 
                     if (type === 'number' || type === 'integer' || type === 'int') {
-                        const numValue = parseInt(value);
+                        const numValue = type === 'number' ? parseFloat(value) : parseInt(value);
                         if (!isNaN(numValue)) {
                             processedProperties[key] = numValue;
                         }
@@ -329,8 +333,12 @@ const EditResourceForm = ({ resource, onClose, onSuccess }) => {
         }
     };
 
+    // Schema entries are { type, required, ... }; older types stored just the type name
+    const propertyType = (definition) =>
+        definition && typeof definition === 'object' ? definition.type : definition;
+
     const getInputType = (schemaType) => {
-        switch (schemaType) {
+        switch (propertyType(schemaType)) {
             case 'number':
             case 'integer':
                 return 'number';
@@ -534,7 +542,7 @@ const EditResourceForm = ({ resource, onClose, onSuccess }) => {
                                                         {formatPropertyLabel(key)}
                                                         {isMeetingRoom && <span className="text-red-500 ml-1">*</span>}
                                                         <span className="text-xs text-gray-500 ml-2">
-                                                            ({schemaType}) {!isMeetingRoom && '- Optional'}
+                                                            ({propertyType(schemaType)}) {!isMeetingRoom && '- Optional'}
                                                         </span>
                                                     </label>
                                                     <input